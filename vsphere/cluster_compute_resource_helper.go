@@ -0,0 +1,304 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// clusterComputeResourceFromID locates a ClusterComputeResource by its managed
+// object reference ID.
+func clusterComputeResourceFromID(client *govmomi.Client, id string) (*object.ClusterComputeResource, error) {
+	finder := find.NewFinder(client.Client, false)
+
+	ref := types.ManagedObjectReference{
+		Type:  "ClusterComputeResource",
+		Value: id,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	cluster, err := finder.ObjectReference(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	return cluster.(*object.ClusterComputeResource), nil
+}
+
+// clusterComputeResourceFromPath loads a ClusterComputeResource from its path.
+//
+// Datacenter is optional here - if not provided, it's expected that the path
+// is sufficient enough for finder to determine the datacenter required.
+func clusterComputeResourceFromPath(client *govmomi.Client, name string, dc *object.Datacenter) (*object.ClusterComputeResource, error) {
+	finder := find.NewFinder(client.Client, false)
+	if dc != nil {
+		finder.SetDatacenter(dc)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return finder.ClusterComputeResource(ctx, name)
+}
+
+// clusterComputeResourceProperties is a convenience method that wraps
+// fetching the ClusterComputeResource MO from its higher-level object.
+func clusterComputeResourceProperties(cluster *object.ClusterComputeResource) (*mo.ClusterComputeResource, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	var props mo.ClusterComputeResource
+	if err := cluster.Properties(ctx, cluster.Reference(), nil, &props); err != nil {
+		return nil, err
+	}
+	return &props, nil
+}
+
+// clusterConfigInfoEx returns the ClusterConfigInfoEx for a
+// ClusterComputeResource.
+func clusterConfigInfoEx(cluster *object.ClusterComputeResource) (*types.ClusterConfigInfoEx, error) {
+	props, err := clusterComputeResourceProperties(cluster)
+	if err != nil {
+		return nil, err
+	}
+	info, ok := props.ConfigurationEx.(*types.ClusterConfigInfoEx)
+	if !ok {
+		return nil, fmt.Errorf("unexpected configuration type %T for cluster %q", props.ConfigurationEx, cluster.Reference().Value)
+	}
+	return info, nil
+}
+
+// createClusterComputeResource creates a ClusterComputeResource in the
+// supplied host folder with the supplied configuration.
+func createClusterComputeResource(folder *object.Folder, name string, spec types.ClusterConfigSpecEx) (*object.ClusterComputeResource, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return folder.CreateCluster(ctx, name, spec)
+}
+
+// reconfigureClusterComputeResource applies a ClusterConfigSpecEx to a
+// ClusterComputeResource via ReconfigureComputeResource_Task. The spec is
+// merged into the existing configuration, so any unset values in the spec are
+// left as they are.
+func reconfigureClusterComputeResource(cluster *object.ClusterComputeResource, spec *types.ClusterConfigSpecEx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	task, err := cluster.Reconfigure(ctx, spec, true)
+	if err != nil {
+		return err
+	}
+	tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer tcancel()
+	return task.Wait(tctx)
+}
+
+// clusterEVCManager returns the ClusterEVCManager for a
+// ClusterComputeResource. This local implementation may go away if this is
+// exposed in the higher-level object upstream.
+func clusterEVCManager(client *govmomi.Client, cluster *object.ClusterComputeResource) (types.ManagedObjectReference, error) {
+	req := &types.EvcManager{
+		This: cluster.Reference(),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	resp, err := methods.EvcManager(ctx, client, req)
+	if err != nil {
+		return types.ManagedObjectReference{}, err
+	}
+	if resp.Returnval == nil {
+		return types.ManagedObjectReference{}, fmt.Errorf("cluster %q has no EVC manager", cluster.Reference().Value)
+	}
+	return *resp.Returnval, nil
+}
+
+// clusterEVCMode returns the current EVC mode key for a
+// ClusterComputeResource. An empty string is returned if EVC is disabled.
+func clusterEVCMode(client *govmomi.Client, cluster *object.ClusterComputeResource) (string, error) {
+	ref, err := clusterEVCManager(client, cluster)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	var props mo.ClusterEVCManager
+	pc := client.PropertyCollector()
+	if err := pc.RetrieveOne(ctx, ref, []string{"evcState"}, &props); err != nil {
+		return "", err
+	}
+	return props.EvcState.CurrentEVCModeKey, nil
+}
+
+// updateClusterEVCMode sets the EVC mode of a ClusterComputeResource. An empty
+// mode disables EVC.
+func updateClusterEVCMode(client *govmomi.Client, cluster *object.ClusterComputeResource, mode string) error {
+	ref, err := clusterEVCManager(client, cluster)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	var taskRef types.ManagedObjectReference
+	if mode == "" {
+		resp, err := methods.DisableEvcMode_Task(ctx, client, &types.DisableEvcMode_Task{This: ref})
+		if err != nil {
+			return err
+		}
+		taskRef = resp.Returnval
+	} else {
+		resp, err := methods.ConfigureEvcMode_Task(ctx, client, &types.ConfigureEvcMode_Task{This: ref, EvcModeKey: mode})
+		if err != nil {
+			return err
+		}
+		taskRef = resp.Returnval
+	}
+	task := object.NewTask(client.Client, taskRef)
+	tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer tcancel()
+	return task.Wait(tctx)
+}
+
+// clusterGroup returns the group with the supplied name in a
+// ClusterComputeResource. nil is returned if the cluster has no such group.
+func clusterGroup(cluster *object.ClusterComputeResource, name string) (types.BaseClusterGroupInfo, error) {
+	info, err := clusterConfigInfoEx(cluster)
+	if err != nil {
+		return nil, err
+	}
+	for _, group := range info.Group {
+		if group.GetClusterGroupInfo().Name == name {
+			return group, nil
+		}
+	}
+	return nil, nil
+}
+
+// updateClusterGroup adds or edits a single group in a
+// ClusterComputeResource. Other groups in the cluster are left untouched.
+func updateClusterGroup(cluster *object.ClusterComputeResource, op types.ArrayUpdateOperation, info types.BaseClusterGroupInfo) error {
+	spec := &types.ClusterConfigSpecEx{
+		GroupSpec: []types.ClusterGroupSpec{
+			{
+				ArrayUpdateSpec: types.ArrayUpdateSpec{
+					Operation: op,
+				},
+				Info: info,
+			},
+		},
+	}
+	return reconfigureClusterComputeResource(cluster, spec)
+}
+
+// removeClusterGroup removes the group with the supplied name from a
+// ClusterComputeResource.
+func removeClusterGroup(cluster *object.ClusterComputeResource, name string) error {
+	spec := &types.ClusterConfigSpecEx{
+		GroupSpec: []types.ClusterGroupSpec{
+			{
+				ArrayUpdateSpec: types.ArrayUpdateSpec{
+					Operation: types.ArrayUpdateOperationRemove,
+					RemoveKey: name,
+				},
+			},
+		},
+	}
+	return reconfigureClusterComputeResource(cluster, spec)
+}
+
+// clusterRule returns the rule with the supplied name in a
+// ClusterComputeResource. nil is returned if the cluster has no such rule.
+func clusterRule(cluster *object.ClusterComputeResource, name string) (types.BaseClusterRuleInfo, error) {
+	info, err := clusterConfigInfoEx(cluster)
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range info.Rule {
+		if rule.GetClusterRuleInfo().Name == name {
+			return rule, nil
+		}
+	}
+	return nil, nil
+}
+
+// updateClusterRule adds or edits a single rule in a ClusterComputeResource.
+// When editing, the key of the rule in info must be set to the key of the
+// existing rule. Other rules in the cluster are left untouched.
+func updateClusterRule(cluster *object.ClusterComputeResource, op types.ArrayUpdateOperation, info types.BaseClusterRuleInfo) error {
+	spec := &types.ClusterConfigSpecEx{
+		RulesSpec: []types.ClusterRuleSpec{
+			{
+				ArrayUpdateSpec: types.ArrayUpdateSpec{
+					Operation: op,
+				},
+				Info: info,
+			},
+		},
+	}
+	return reconfigureClusterComputeResource(cluster, spec)
+}
+
+// removeClusterRule removes the rule with the supplied key from a
+// ClusterComputeResource.
+func removeClusterRule(cluster *object.ClusterComputeResource, key int32) error {
+	spec := &types.ClusterConfigSpecEx{
+		RulesSpec: []types.ClusterRuleSpec{
+			{
+				ArrayUpdateSpec: types.ArrayUpdateSpec{
+					Operation: types.ArrayUpdateOperationRemove,
+					RemoveKey: key,
+				},
+			},
+		},
+	}
+	return reconfigureClusterComputeResource(cluster, spec)
+}
+
+// splitComputeClusterObjectID splits the ID of a resource that lives inside a
+// cluster, such as a group or rule, into the managed object ID of the cluster
+// and the name of the object. These IDs are in the form CLUSTER_ID:NAME.
+func splitComputeClusterObjectID(id string) (string, string, error) {
+	parts := strings.SplitN(id, ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid ID %q, expected COMPUTE_CLUSTER_ID:NAME", id)
+	}
+	return parts[0], parts[1], nil
+}
+
+// clusterDrsVMConfig returns the DRS override for a virtual machine in a
+// ClusterComputeResource. nil is returned if the cluster has no override for
+// the virtual machine.
+func clusterDrsVMConfig(cluster *object.ClusterComputeResource, vm *object.VirtualMachine) (*types.ClusterDrsVmConfigInfo, error) {
+	info, err := clusterConfigInfoEx(cluster)
+	if err != nil {
+		return nil, err
+	}
+	for _, config := range info.DrsVmConfig {
+		if config.Key.Value == vm.Reference().Value {
+			return &config, nil
+		}
+	}
+	return nil, nil
+}
+
+// clusterDasVMConfig returns the HA override for a virtual machine in a
+// ClusterComputeResource. nil is returned if the cluster has no override for
+// the virtual machine.
+func clusterDasVMConfig(cluster *object.ClusterComputeResource, vm *object.VirtualMachine) (*types.ClusterDasVmConfigInfo, error) {
+	info, err := clusterConfigInfoEx(cluster)
+	if err != nil {
+		return nil, err
+	}
+	for _, config := range info.DasVmConfig {
+		if config.Key.Value == vm.Reference().Value {
+			return &config, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,457 @@
+package vsphere
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+const (
+	clusterAdmissionControlPolicyResourcePercentage = "resourcePercentage"
+	clusterAdmissionControlPolicySlotPolicy         = "slotPolicy"
+	clusterAdmissionControlPolicyFailoverHosts      = "failoverHosts"
+	clusterAdmissionControlPolicyDisabled           = "disabled"
+)
+
+var clusterAdmissionControlPolicyAllowedValues = []string{
+	clusterAdmissionControlPolicyResourcePercentage,
+	clusterAdmissionControlPolicySlotPolicy,
+	clusterAdmissionControlPolicyFailoverHosts,
+	clusterAdmissionControlPolicyDisabled,
+}
+
+// schemaClusterConfigSpecEx returns schema items for resources that need to
+// work with a ClusterConfigSpecEx.
+func schemaClusterConfigSpecEx() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		// ClusterDrsConfigInfo
+		// Skipped attributes: option
+		"drs_enabled": {
+			Type:        schema.TypeBool,
+			Description: "Enable DRS for this cluster.",
+			Optional:    true,
+		},
+		"drs_automation_level": {
+			Type:        schema.TypeString,
+			Description: "The default automation level for all virtual machines in this cluster. Can be one of manual, partiallyAutomated, or fullyAutomated.",
+			Optional:    true,
+			Default:     string(types.DrsBehaviorManual),
+			ValidateFunc: validation.StringInSlice(
+				[]string{
+					string(types.DrsBehaviorManual),
+					string(types.DrsBehaviorPartiallyAutomated),
+					string(types.DrsBehaviorFullyAutomated),
+				},
+				false,
+			),
+		},
+		"drs_migration_threshold": {
+			Type:         schema.TypeInt,
+			Description:  "A value between 1 and 5 indicating the threshold of imbalance tolerated between hosts. A lower setting will tolerate more imbalance while a higher setting will tolerate less.",
+			Optional:     true,
+			Default:      3,
+			ValidateFunc: validation.IntBetween(1, 5),
+		},
+		"drs_enable_vm_overrides": {
+			Type:        schema.TypeBool,
+			Description: "When true, allows individual VM overrides within this cluster to be set.",
+			Optional:    true,
+			Default:     true,
+		},
+		// ClusterProactiveDrsConfigInfo
+		"drs_enable_predictive_drs": {
+			Type:        schema.TypeBool,
+			Description: "When true, enables DRS to use data from vRealize Operations Manager to make proactive DRS recommendations.",
+			Optional:    true,
+		},
+		// ClusterDasConfigInfo
+		// Skipped attributes: vmMonitoring, vmComponentProtecting, option
+		"ha_enabled": {
+			Type:        schema.TypeBool,
+			Description: "Enable vSphere HA for this cluster.",
+			Optional:    true,
+		},
+		"ha_host_monitoring": {
+			Type:        schema.TypeString,
+			Description: "Global setting that controls whether vSphere HA remediates VMs on host failure. Can be one of enabled or disabled.",
+			Optional:    true,
+			Default:     string(types.ClusterDasConfigInfoServiceStateEnabled),
+			ValidateFunc: validation.StringInSlice(
+				[]string{
+					string(types.ClusterDasConfigInfoServiceStateEnabled),
+					string(types.ClusterDasConfigInfoServiceStateDisabled),
+				},
+				false,
+			),
+		},
+		"ha_vm_restart_priority": {
+			Type:        schema.TypeString,
+			Description: "The default restart priority for affected VMs when vSphere detects a host failure. Can be one of lowest, low, medium, high, or highest.",
+			Optional:    true,
+			Default:     string(types.ClusterDasVmSettingsRestartPriorityMedium),
+			ValidateFunc: validation.StringInSlice(
+				[]string{
+					string(types.ClusterDasVmSettingsRestartPriorityLowest),
+					string(types.ClusterDasVmSettingsRestartPriorityLow),
+					string(types.ClusterDasVmSettingsRestartPriorityMedium),
+					string(types.ClusterDasVmSettingsRestartPriorityHigh),
+					string(types.ClusterDasVmSettingsRestartPriorityHighest),
+				},
+				false,
+			),
+		},
+		"ha_host_isolation_response": {
+			Type:        schema.TypeString,
+			Description: "The action to take on virtual machines when a host has detected that it has been isolated from the rest of the cluster. Can be one of none, powerOff, or shutdown.",
+			Optional:    true,
+			Default:     string(types.ClusterDasVmSettingsIsolationResponseNone),
+			ValidateFunc: validation.StringInSlice(
+				[]string{
+					string(types.ClusterDasVmSettingsIsolationResponseNone),
+					string(types.ClusterDasVmSettingsIsolationResponsePowerOff),
+					string(types.ClusterDasVmSettingsIsolationResponseShutdown),
+				},
+				false,
+			),
+		},
+		"ha_admission_control_policy": {
+			Type:         schema.TypeString,
+			Description:  "The type of admission control policy to use with vSphere HA, which controls whether or not specific VM operations are permitted in the cluster in order to protect the reliability of the cluster. Can be one of resourcePercentage, slotPolicy, failoverHosts, or disabled.",
+			Optional:     true,
+			Default:      clusterAdmissionControlPolicyResourcePercentage,
+			ValidateFunc: validation.StringInSlice(clusterAdmissionControlPolicyAllowedValues, false),
+		},
+		"ha_admission_control_host_failure_tolerance": {
+			Type:         schema.TypeInt,
+			Description:  "The maximum number of failed hosts that admission control tolerates when making decisions on whether to permit virtual machine operations.",
+			Optional:     true,
+			Default:      1,
+			ValidateFunc: validation.IntAtLeast(1),
+		},
+		"ha_admission_control_resource_percentage_auto_compute": {
+			Type:        schema.TypeBool,
+			Description: "When ha_admission_control_policy is resourcePercentage, automatically determine available resource percentages by subtracting the average number of host resources represented by the ha_admission_control_host_failure_tolerance setting from the total amount of resources in the cluster.",
+			Optional:    true,
+			Default:     true,
+		},
+		"ha_admission_control_resource_percentage_cpu": {
+			Type:         schema.TypeInt,
+			Description:  "When ha_admission_control_policy is resourcePercentage, this controls the user-defined percentage of CPU resources in the cluster to reserve for failover.",
+			Optional:     true,
+			Default:      100,
+			ValidateFunc: validation.IntBetween(1, 100),
+		},
+		"ha_admission_control_resource_percentage_memory": {
+			Type:         schema.TypeInt,
+			Description:  "When ha_admission_control_policy is resourcePercentage, this controls the user-defined percentage of memory resources in the cluster to reserve for failover.",
+			Optional:     true,
+			Default:      100,
+			ValidateFunc: validation.IntBetween(1, 100),
+		},
+		"ha_admission_control_failover_host_system_ids": {
+			Type:        schema.TypeSet,
+			Description: "When ha_admission_control_policy is failoverHosts, this defines the managed object IDs of hosts to use as dedicated failover hosts.",
+			Optional:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+		},
+		"ha_heartbeat_datastore_policy": {
+			Type:        schema.TypeString,
+			Description: "The selection policy for HA heartbeat datastores. Can be one of allFeasibleDs, userSelectedDs, or allFeasibleDsWithUserPreference.",
+			Optional:    true,
+			Default:     string(types.ClusterDasConfigInfoHBDatastoreCandidateAllFeasibleDsWithUserPreference),
+			ValidateFunc: validation.StringInSlice(
+				[]string{
+					string(types.ClusterDasConfigInfoHBDatastoreCandidateAllFeasibleDs),
+					string(types.ClusterDasConfigInfoHBDatastoreCandidateUserSelectedDs),
+					string(types.ClusterDasConfigInfoHBDatastoreCandidateAllFeasibleDsWithUserPreference),
+				},
+				false,
+			),
+		},
+		"ha_heartbeat_datastore_ids": {
+			Type:        schema.TypeSet,
+			Description: "The list of managed object IDs for preferred datastores to use for HA heartbeating. This setting is only useful when ha_heartbeat_datastore_policy is set to either userSelectedDs or allFeasibleDsWithUserPreference.",
+			Optional:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+		},
+		// ClusterInfraUpdateHaConfigInfo
+		"proactive_ha_enabled": {
+			Type:        schema.TypeBool,
+			Description: "Enables proactive HA, allowing for vSphere to get HA data from external providers and use DRS to perform remediation.",
+			Optional:    true,
+		},
+		"proactive_ha_automation_level": {
+			Type:        schema.TypeString,
+			Description: "The DRS behavior for proactive HA recommendations. Can be one of Automated or Manual.",
+			Optional:    true,
+			Default:     string(types.ClusterInfraUpdateHaConfigInfoBehaviorTypeManual),
+			ValidateFunc: validation.StringInSlice(
+				[]string{
+					string(types.ClusterInfraUpdateHaConfigInfoBehaviorTypeManual),
+					string(types.ClusterInfraUpdateHaConfigInfoBehaviorTypeAutomated),
+				},
+				false,
+			),
+		},
+		"proactive_ha_moderate_remediation": {
+			Type:        schema.TypeString,
+			Description: "The configured remediation for moderately degraded hosts. Can be one of MaintenanceMode or QuarantineMode.",
+			Optional:    true,
+			Default:     string(types.ClusterInfraUpdateHaConfigInfoRemediationTypeQuarantineMode),
+			ValidateFunc: validation.StringInSlice(
+				[]string{
+					string(types.ClusterInfraUpdateHaConfigInfoRemediationTypeMaintenanceMode),
+					string(types.ClusterInfraUpdateHaConfigInfoRemediationTypeQuarantineMode),
+				},
+				false,
+			),
+		},
+		"proactive_ha_severe_remediation": {
+			Type:        schema.TypeString,
+			Description: "The configured remediation for severely degraded hosts. Can be one of MaintenanceMode or QuarantineMode.",
+			Optional:    true,
+			Default:     string(types.ClusterInfraUpdateHaConfigInfoRemediationTypeQuarantineMode),
+			ValidateFunc: validation.StringInSlice(
+				[]string{
+					string(types.ClusterInfraUpdateHaConfigInfoRemediationTypeMaintenanceMode),
+					string(types.ClusterInfraUpdateHaConfigInfoRemediationTypeQuarantineMode),
+				},
+				false,
+			),
+		},
+		"proactive_ha_provider_ids": {
+			Type:        schema.TypeSet,
+			Description: "The list of IDs for health update providers configured for this cluster.",
+			Optional:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+		},
+	}
+}
+
+// validateClusterConfigSpecEx checks combinations of cluster configuration
+// settings that cannot be checked by validation on individual schema
+// attributes.
+func validateClusterConfigSpecEx(d *schema.ResourceData) error {
+	if d.Get("proactive_ha_enabled").(bool) && !d.Get("drs_enabled").(bool) {
+		return errors.New("proactive_ha_enabled requires drs_enabled to be true")
+	}
+	policy := d.Get("ha_admission_control_policy").(string)
+	hosts := d.Get("ha_admission_control_failover_host_system_ids").(*schema.Set).Len()
+	if policy == clusterAdmissionControlPolicyFailoverHosts && hosts < 1 {
+		return fmt.Errorf("ha_admission_control_failover_host_system_ids must contain at least one host when ha_admission_control_policy is %q", policy)
+	}
+	if policy != clusterAdmissionControlPolicyFailoverHosts && hosts > 0 {
+		return fmt.Errorf("ha_admission_control_failover_host_system_ids can only be set when ha_admission_control_policy is %q", clusterAdmissionControlPolicyFailoverHosts)
+	}
+	return nil
+}
+
+// expandClusterConfigSpecEx reads certain ResourceData keys and returns a
+// ClusterConfigSpecEx.
+func expandClusterConfigSpecEx(d *schema.ResourceData) *types.ClusterConfigSpecEx {
+	obj := &types.ClusterConfigSpecEx{
+		DasConfig:           expandClusterDasConfigInfo(d),
+		DrsConfig:           expandClusterDrsConfigInfo(d),
+		InfraUpdateHaConfig: expandClusterInfraUpdateHaConfigInfo(d),
+		ProactiveDrsConfig: &types.ClusterProactiveDrsConfigInfo{
+			Enabled: boolPtr(d.Get("drs_enable_predictive_drs").(bool)),
+		},
+	}
+	return obj
+}
+
+// flattenClusterConfigInfoEx reads various fields from a ClusterConfigInfoEx
+// into the passed in ResourceData.
+func flattenClusterConfigInfoEx(d *schema.ResourceData, obj *types.ClusterConfigInfoEx) error {
+	if err := flattenClusterDrsConfigInfo(d, obj.DrsConfig); err != nil {
+		return err
+	}
+	if err := flattenClusterDasConfigInfo(d, obj.DasConfig); err != nil {
+		return err
+	}
+	if c := obj.ProactiveDrsConfig; c != nil {
+		if err := setBoolPtr(d, "drs_enable_predictive_drs", c.Enabled); err != nil {
+			return err
+		}
+	}
+	if c := obj.InfraUpdateHaConfig; c != nil {
+		if err := flattenClusterInfraUpdateHaConfigInfo(d, *c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// expandClusterDrsConfigInfo reads certain ResourceData keys and returns a
+// ClusterDrsConfigInfo.
+//
+// The DRS migration threshold in the vSphere client goes from 1 (most
+// conservative) to 5 (most aggressive), which is the reverse of the
+// vmotionRate value in the API. The value in drs_migration_threshold uses the
+// vSphere client convention.
+func expandClusterDrsConfigInfo(d *schema.ResourceData) *types.ClusterDrsConfigInfo {
+	obj := &types.ClusterDrsConfigInfo{
+		Enabled:                   boolPtr(d.Get("drs_enabled").(bool)),
+		EnableVmBehaviorOverrides: boolPtr(d.Get("drs_enable_vm_overrides").(bool)),
+		DefaultVmBehavior:         types.DrsBehavior(d.Get("drs_automation_level").(string)),
+		VmotionRate:               int32(6 - d.Get("drs_migration_threshold").(int)),
+	}
+	return obj
+}
+
+// flattenClusterDrsConfigInfo reads various fields from a
+// ClusterDrsConfigInfo into the passed in ResourceData.
+func flattenClusterDrsConfigInfo(d *schema.ResourceData, obj types.ClusterDrsConfigInfo) error {
+	if err := setBoolPtr(d, "drs_enabled", obj.Enabled); err != nil {
+		return err
+	}
+	if err := setBoolPtr(d, "drs_enable_vm_overrides", obj.EnableVmBehaviorOverrides); err != nil {
+		return err
+	}
+	d.Set("drs_automation_level", obj.DefaultVmBehavior)
+	if obj.VmotionRate > 0 {
+		d.Set("drs_migration_threshold", 6-obj.VmotionRate)
+	}
+	return nil
+}
+
+// expandClusterDasConfigInfo reads certain ResourceData keys and returns a
+// ClusterDasConfigInfo.
+func expandClusterDasConfigInfo(d *schema.ResourceData) *types.ClusterDasConfigInfo {
+	obj := &types.ClusterDasConfigInfo{
+		Enabled:        boolPtr(d.Get("ha_enabled").(bool)),
+		HostMonitoring: d.Get("ha_host_monitoring").(string),
+		DefaultVmSettings: &types.ClusterDasVmSettings{
+			RestartPriority:   d.Get("ha_vm_restart_priority").(string),
+			IsolationResponse: d.Get("ha_host_isolation_response").(string),
+		},
+		HBDatastoreCandidatePolicy: d.Get("ha_heartbeat_datastore_policy").(string),
+	}
+	for _, id := range sliceInterfacesToStrings(d.Get("ha_heartbeat_datastore_ids").(*schema.Set).List()) {
+		obj.HeartbeatDatastore = append(obj.HeartbeatDatastore, types.ManagedObjectReference{
+			Type:  "Datastore",
+			Value: id,
+		})
+	}
+
+	policy := d.Get("ha_admission_control_policy").(string)
+	obj.AdmissionControlEnabled = boolPtr(policy != clusterAdmissionControlPolicyDisabled)
+	obj.AdmissionControlPolicy = expandClusterDasAdmissionControlPolicy(d, policy)
+	return obj
+}
+
+// expandClusterDasAdmissionControlPolicy returns the admission control policy
+// matching the supplied policy type. nil is returned for the disabled policy
+// type, which leaves the existing policy in place.
+func expandClusterDasAdmissionControlPolicy(d *schema.ResourceData, policy string) types.BaseClusterDasAdmissionControlPolicy {
+	failoverLevel := int32(d.Get("ha_admission_control_host_failure_tolerance").(int))
+	switch policy {
+	case clusterAdmissionControlPolicyResourcePercentage:
+		return &types.ClusterFailoverResourcesAdmissionControlPolicy{
+			AutoComputePercentages:         boolPtr(d.Get("ha_admission_control_resource_percentage_auto_compute").(bool)),
+			FailoverLevel:                  failoverLevel,
+			CpuFailoverResourcesPercent:    int32(d.Get("ha_admission_control_resource_percentage_cpu").(int)),
+			MemoryFailoverResourcesPercent: int32(d.Get("ha_admission_control_resource_percentage_memory").(int)),
+		}
+	case clusterAdmissionControlPolicySlotPolicy:
+		return &types.ClusterFailoverLevelAdmissionControlPolicy{
+			FailoverLevel: failoverLevel,
+		}
+	case clusterAdmissionControlPolicyFailoverHosts:
+		obj := &types.ClusterFailoverHostAdmissionControlPolicy{
+			FailoverLevel: failoverLevel,
+		}
+		for _, id := range sliceInterfacesToStrings(d.Get("ha_admission_control_failover_host_system_ids").(*schema.Set).List()) {
+			obj.FailoverHosts = append(obj.FailoverHosts, types.ManagedObjectReference{
+				Type:  "HostSystem",
+				Value: id,
+			})
+		}
+		return obj
+	}
+	return nil
+}
+
+// flattenClusterDasConfigInfo reads various fields from a
+// ClusterDasConfigInfo into the passed in ResourceData.
+func flattenClusterDasConfigInfo(d *schema.ResourceData, obj types.ClusterDasConfigInfo) error {
+	if err := setBoolPtr(d, "ha_enabled", obj.Enabled); err != nil {
+		return err
+	}
+	d.Set("ha_host_monitoring", obj.HostMonitoring)
+	d.Set("ha_heartbeat_datastore_policy", obj.HBDatastoreCandidatePolicy)
+	if c := obj.DefaultVmSettings; c != nil {
+		d.Set("ha_vm_restart_priority", c.RestartPriority)
+		d.Set("ha_host_isolation_response", c.IsolationResponse)
+	}
+
+	var dsIDs []string
+	for _, ref := range obj.HeartbeatDatastore {
+		dsIDs = append(dsIDs, ref.Value)
+	}
+	if err := d.Set("ha_heartbeat_datastore_ids", dsIDs); err != nil {
+		return fmt.Errorf("error setting ha_heartbeat_datastore_ids: %s", err)
+	}
+
+	if obj.AdmissionControlEnabled != nil && !*obj.AdmissionControlEnabled {
+		d.Set("ha_admission_control_policy", clusterAdmissionControlPolicyDisabled)
+		return nil
+	}
+	var hostIDs []string
+	switch p := obj.AdmissionControlPolicy.(type) {
+	case *types.ClusterFailoverResourcesAdmissionControlPolicy:
+		d.Set("ha_admission_control_policy", clusterAdmissionControlPolicyResourcePercentage)
+		d.Set("ha_admission_control_host_failure_tolerance", p.FailoverLevel)
+		d.Set("ha_admission_control_resource_percentage_cpu", p.CpuFailoverResourcesPercent)
+		d.Set("ha_admission_control_resource_percentage_memory", p.MemoryFailoverResourcesPercent)
+		if err := setBoolPtr(d, "ha_admission_control_resource_percentage_auto_compute", p.AutoComputePercentages); err != nil {
+			return err
+		}
+	case *types.ClusterFailoverLevelAdmissionControlPolicy:
+		d.Set("ha_admission_control_policy", clusterAdmissionControlPolicySlotPolicy)
+		d.Set("ha_admission_control_host_failure_tolerance", p.FailoverLevel)
+	case *types.ClusterFailoverHostAdmissionControlPolicy:
+		d.Set("ha_admission_control_policy", clusterAdmissionControlPolicyFailoverHosts)
+		if p.FailoverLevel > 0 {
+			d.Set("ha_admission_control_host_failure_tolerance", p.FailoverLevel)
+		}
+		for _, ref := range p.FailoverHosts {
+			hostIDs = append(hostIDs, ref.Value)
+		}
+	}
+	if err := d.Set("ha_admission_control_failover_host_system_ids", hostIDs); err != nil {
+		return fmt.Errorf("error setting ha_admission_control_failover_host_system_ids: %s", err)
+	}
+	return nil
+}
+
+// expandClusterInfraUpdateHaConfigInfo reads certain ResourceData keys and
+// returns a ClusterInfraUpdateHaConfigInfo.
+func expandClusterInfraUpdateHaConfigInfo(d *schema.ResourceData) *types.ClusterInfraUpdateHaConfigInfo {
+	obj := &types.ClusterInfraUpdateHaConfigInfo{
+		Enabled:             boolPtr(d.Get("proactive_ha_enabled").(bool)),
+		Behavior:            d.Get("proactive_ha_automation_level").(string),
+		ModerateRemediation: d.Get("proactive_ha_moderate_remediation").(string),
+		SevereRemediation:   d.Get("proactive_ha_severe_remediation").(string),
+		Providers:           sliceInterfacesToStrings(d.Get("proactive_ha_provider_ids").(*schema.Set).List()),
+	}
+	return obj
+}
+
+// flattenClusterInfraUpdateHaConfigInfo reads various fields from a
+// ClusterInfraUpdateHaConfigInfo into the passed in ResourceData.
+func flattenClusterInfraUpdateHaConfigInfo(d *schema.ResourceData, obj types.ClusterInfraUpdateHaConfigInfo) error {
+	if err := setBoolPtr(d, "proactive_ha_enabled", obj.Enabled); err != nil {
+		return err
+	}
+	d.Set("proactive_ha_automation_level", obj.Behavior)
+	d.Set("proactive_ha_moderate_remediation", obj.ModerateRemediation)
+	d.Set("proactive_ha_severe_remediation", obj.SevereRemediation)
+	if err := d.Set("proactive_ha_provider_ids", obj.Providers); err != nil {
+		return fmt.Errorf("error setting proactive_ha_provider_ids: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package vsphere
+
+import (
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// schemaClusterRuleInfo returns schema items for resources that need to work
+// with the common fields of a ClusterRuleInfo.
+func schemaClusterRuleInfo() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"enabled": {
+			Type:        schema.TypeBool,
+			Description: "Enable this rule.",
+			Optional:    true,
+			Default:     true,
+		},
+		"mandatory": {
+			Type:        schema.TypeBool,
+			Description: "When true, prevents any virtual machine operations that may violate this rule.",
+			Optional:    true,
+		},
+	}
+}
+
+// expandClusterRuleInfo reads certain ResourceData keys and returns a
+// ClusterRuleInfo with the supplied key.
+func expandClusterRuleInfo(d *schema.ResourceData, key int32) types.ClusterRuleInfo {
+	obj := types.ClusterRuleInfo{
+		Key:       key,
+		Name:      d.Get("name").(string),
+		Enabled:   boolPtr(d.Get("enabled").(bool)),
+		Mandatory: boolPtr(d.Get("mandatory").(bool)),
+	}
+	return obj
+}
+
+// flattenClusterRuleInfo reads various fields from a ClusterRuleInfo into the
+// passed in ResourceData.
+func flattenClusterRuleInfo(d *schema.ResourceData, obj *types.ClusterRuleInfo) error {
+	d.Set("name", obj.Name)
+	if err := setBoolPtr(d, "enabled", obj.Enabled); err != nil {
+		return err
+	}
+	return setBoolPtr(d, "mandatory", obj.Mandatory)
+}
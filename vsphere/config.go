@@ -6,9 +6,11 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/pbm"
 	"github.com/vmware/govmomi/vim25/debug"
 	"github.com/vmware/vic/pkg/vsphere/tags"
 	"golang.org/x/net/context"
@@ -24,6 +26,13 @@ type VSphereClient struct {
 
 	// The specialized tags client SDK imported from vmware/vic.
 	tagsClient *tags.RestClient
+
+	// The storage policy (SPBM) client. This shares the session of vimClient,
+	// and is created on first use by PbmClient.
+	pbmClient *pbm.Client
+
+	// Guards the lazy creation of pbmClient.
+	pbmLock sync.Mutex
 }
 
 // TagsClient returns the embedded REST client used for tags, after determining
@@ -57,6 +66,34 @@ func (c *VSphereClient) TagsClient() (*tags.RestClient, error) {
 	return c.tagsClient, nil
 }
 
+// PbmClient returns the storage policy (SPBM) client, after checking that the
+// connection is to vCenter. Storage policies are not available on direct ESXi
+// connections.
+//
+// The client is created on first use, so that a vCenter without SPBM, or a
+// user without the privileges to use it, only affects the code paths that
+// need storage policies.
+func (c *VSphereClient) PbmClient() (*pbm.Client, error) {
+	if err := validateVirtualCenter(c.vimClient); err != nil {
+		return nil, err
+	}
+	c.pbmLock.Lock()
+	defer c.pbmLock.Unlock()
+	if c.pbmClient == nil {
+		// This re-uses the session of the VIM client, so no additional login is
+		// necessary.
+		ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+		defer cancel()
+		client, err := pbm.NewClient(ctx, c.vimClient.Client)
+		if err != nil {
+			return nil, fmt.Errorf("error setting up storage policy client: %s", err)
+		}
+		log.Println("[INFO] Storage policy (SPBM) client configured")
+		c.pbmClient = client
+	}
+	return c.pbmClient, nil
+}
+
 // Config holds the provider configuration, and delivers a populated
 // VSphereClient based off the contained settings.
 type Config struct {
@@ -0,0 +1,75 @@
+package vsphere
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/vmware/govmomi/object"
+)
+
+func dataSourceVSphereDatastore() *schema.Resource {
+	s := map[string]*schema.Schema{
+		"name": {
+			Type:        schema.TypeString,
+			Description: "The name or path of the datastore.",
+			Required:    true,
+		},
+		"datacenter_id": {
+			Type:        schema.TypeString,
+			Description: "The managed object ID of the datacenter the datastore is in. This is required if the supplied path is not an absolute path containing a datacenter and there are multiple datacenters in your infrastructure.",
+			Optional:    true,
+		},
+		"type": {
+			Type:        schema.TypeString,
+			Description: "The type of the datastore, such as VMFS or NFS.",
+			Computed:    true,
+		},
+		"host_system_ids": {
+			Type:        schema.TypeList,
+			Description: "The managed object IDs of the hosts that mount the datastore.",
+			Computed:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+		},
+	}
+	mergeSchema(s, schemaDatastoreSummary())
+
+	return &schema.Resource{
+		Read:   dataSourceVSphereDatastoreRead,
+		Schema: s,
+	}
+}
+
+func dataSourceVSphereDatastoreRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	name := d.Get("name").(string)
+	var dc *object.Datacenter
+	if dcID, ok := d.GetOk("datacenter_id"); ok {
+		var err error
+		dc, err = datacenterFromID(client, dcID.(string))
+		if err != nil {
+			return fmt.Errorf("cannot locate datacenter: %s", err)
+		}
+	}
+	ds, err := datastoreFromPath(client, name, dc)
+	if err != nil {
+		return fmt.Errorf("error fetching datastore: %s", err)
+	}
+	props, err := datastoreProperties(ds)
+	if err != nil {
+		return fmt.Errorf("could not get properties for datastore: %s", err)
+	}
+
+	d.SetId(ds.Reference().Value)
+	d.Set("type", props.Summary.Type)
+	if err := flattenDatastoreSummary(d, &props.Summary); err != nil {
+		return err
+	}
+	var mountedHosts []string
+	for _, mount := range props.Host {
+		mountedHosts = append(mountedHosts, mount.Key.Value)
+	}
+	if err := d.Set("host_system_ids", mountedHosts); err != nil {
+		return err
+	}
+	return nil
+}
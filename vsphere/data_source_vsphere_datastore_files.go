@@ -0,0 +1,182 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func dataSourceVSphereDatastoreFiles() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceVSphereDatastoreFilesRead,
+
+		Schema: map[string]*schema.Schema{
+			"datastore_id": {
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the datastore to search.",
+				Required:    true,
+			},
+			"folder": {
+				Type:        schema.TypeString,
+				Description: "The folder to search, relative to the root of the datastore. The folder and all of its subfolders are searched.",
+				Optional:    true,
+			},
+			"match_patterns": {
+				Type:        schema.TypeList,
+				Description: "A list of patterns to match file names against, such as *.iso. When not set, all files are returned.",
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"files": {
+				Type:        schema.TypeList,
+				Description: "The files and folders found by the search, sorted by path.",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"path": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The path of the file, relative to the root of the datastore.",
+						},
+						"datastore_path": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The full datastore path of the file, in the format [datastore] path.",
+						},
+						"size": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "The size of the file, in bytes.",
+						},
+						"modification_time": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The time the file was last modified, in RFC3339 format.",
+						},
+						"type": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The type of the file. Can be one of folder, vmDisk, isoImage, floppyImage, vmConfig, vmLog, vmNvram, vmSnapshot, or file.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceVSphereDatastoreFilesRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	ds, err := datastoreFromID(client, d.Get("datastore_id").(string))
+	if err != nil {
+		return fmt.Errorf("error fetching datastore: %s", err)
+	}
+	props, err := datastoreProperties(ds)
+	if err != nil {
+		return fmt.Errorf("error fetching datastore properties: %s", err)
+	}
+
+	spec := types.HostDatastoreBrowserSearchSpec{
+		// The generic FileQuery matches all files, while the others ensure that
+		// files are returned with their specific type.
+		Query: []types.BaseFileQuery{
+			&types.FolderFileQuery{},
+			&types.VmDiskFileQuery{},
+			&types.IsoImageFileQuery{},
+			&types.FloppyImageFileQuery{},
+			&types.VmConfigFileQuery{},
+			&types.VmLogFileQuery{},
+			&types.VmNvramFileQuery{},
+			&types.VmSnapshotFileQuery{},
+			&types.FileQuery{},
+		},
+		Details: &types.FileQueryFlags{
+			FileType:     true,
+			FileSize:     true,
+			Modification: true,
+		},
+	}
+	for _, v := range d.Get("match_patterns").([]interface{}) {
+		spec.MatchPattern = append(spec.MatchPattern, v.(string))
+	}
+
+	folder := ds.Path(d.Get("folder").(string))
+	b := object.NewHostDatastoreBrowser(client.Client, props.Browser)
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	task, err := b.SearchDatastoreSubFolders(ctx, folder, &spec)
+	if err != nil {
+		return fmt.Errorf("error searching %s: %s", folder, err)
+	}
+	info, err := task.WaitForResult(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error searching %s: %s", folder, err)
+	}
+
+	d.SetId(folder)
+	results := info.Result.(types.ArrayOfHostDatastoreBrowserSearchResults).HostDatastoreBrowserSearchResults
+	if err := d.Set("files", flattenDatastoreFiles(props.Name, results)); err != nil {
+		return fmt.Errorf("error setting files: %s", err)
+	}
+	return nil
+}
+
+// flattenDatastoreFiles returns the files in a set of datastore search
+// results, sorted by their path relative to the root of the datastore.
+func flattenDatastoreFiles(datastore string, results []types.HostDatastoreBrowserSearchResults) []map[string]interface{} {
+	var files []map[string]interface{}
+	for _, result := range results {
+		var folder object.DatastorePath
+		folder.FromString(result.FolderPath)
+		for _, file := range result.File {
+			fi := file.GetFileInfo()
+			dsPath := object.DatastorePath{
+				Datastore: datastore,
+				Path:      path.Join(folder.Path, fi.Path),
+			}
+			m := map[string]interface{}{
+				"path":           dsPath.Path,
+				"datastore_path": dsPath.String(),
+				"size":           int(fi.FileSize),
+				"type":           datastoreFileType(file),
+			}
+			if fi.Modification != nil {
+				m["modification_time"] = fi.Modification.Format(time.RFC3339)
+			}
+			files = append(files, m)
+		}
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i]["path"].(string) < files[j]["path"].(string)
+	})
+	return files
+}
+
+// datastoreFileType returns the type of a file returned by a datastore search.
+func datastoreFileType(file types.BaseFileInfo) string {
+	switch file.(type) {
+	case *types.FolderFileInfo:
+		return "folder"
+	case *types.VmDiskFileInfo:
+		return "vmDisk"
+	case *types.IsoImageFileInfo:
+		return "isoImage"
+	case *types.FloppyImageFileInfo:
+		return "floppyImage"
+	case *types.VmConfigFileInfo:
+		return "vmConfig"
+	case *types.VmLogFileInfo:
+		return "vmLog"
+	case *types.VmNvramFileInfo:
+		return "vmNvram"
+	case *types.VmSnapshotFileInfo:
+		return "vmSnapshot"
+	}
+	return "file"
+}
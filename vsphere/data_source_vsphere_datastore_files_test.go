@@ -0,0 +1,132 @@
+package vsphere
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestAccDataSourceVSphereDatastoreFiles(t *testing.T) {
+	sourceFile := "/tmp/tf_file_test.iso"
+	if err := ioutil.WriteFile(sourceFile, []byte("iso\n"), 0644); err != nil {
+		t.Errorf("error %s", err)
+		return
+	}
+	defer os.Remove(sourceFile)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccDataSourceVSphereDatastoreFilesPreCheck(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceVSphereDatastoreFilesConfig(sourceFile),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.vsphere_datastore_files.files", "files.#", "1"),
+					resource.TestCheckResourceAttr("data.vsphere_datastore_files.files", "files.0.path", "tf-test-datastore-files/tf_file_test.iso"),
+					resource.TestCheckResourceAttr("data.vsphere_datastore_files.files", "files.0.type", "isoImage"),
+					resource.TestCheckResourceAttr("data.vsphere_datastore_files.files", "files.0.size", "4"),
+				),
+			},
+		},
+	})
+}
+
+func testAccDataSourceVSphereDatastoreFilesPreCheck(t *testing.T) {
+	if os.Getenv("VSPHERE_DATACENTER") == "" {
+		t.Skip("set VSPHERE_DATACENTER to run vsphere_datastore_files acceptance tests")
+	}
+	if os.Getenv("VSPHERE_DATASTORE") == "" {
+		t.Skip("set VSPHERE_DATASTORE to run vsphere_datastore_files acceptance tests")
+	}
+	if os.Getenv("VSPHERE_DATASTORE_ID") == "" {
+		t.Skip("set VSPHERE_DATASTORE_ID to run vsphere_datastore_files acceptance tests")
+	}
+}
+
+func testAccDataSourceVSphereDatastoreFilesConfig(sourceFile string) string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+variable "datastore_id" {
+  default = "%s"
+}
+
+resource "vsphere_file" "iso" {
+  datacenter         = "${var.datacenter}"
+  datastore          = "${var.datastore}"
+  source_file        = "%s"
+  destination_file   = "tf-test-datastore-files/tf_file_test.iso"
+  create_directories = true
+}
+
+data "vsphere_datastore_files" "files" {
+  datastore_id   = "${var.datastore_id}"
+  folder         = "${dirname(vsphere_file.iso.destination_file)}"
+  match_patterns = ["*.iso"]
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_DATASTORE_ID"),
+		sourceFile,
+	)
+}
+
+func TestFlattenDatastoreFiles(t *testing.T) {
+	mtime := time.Date(2017, 10, 1, 12, 0, 0, 0, time.UTC)
+	results := []types.HostDatastoreBrowserSearchResults{
+		{
+			FolderPath: "[datastore1] isos",
+			File: []types.BaseFileInfo{
+				&types.IsoImageFileInfo{FileInfo: types.FileInfo{Path: "centos.iso", FileSize: 1024, Modification: &mtime}},
+				&types.FolderFileInfo{FileInfo: types.FileInfo{Path: "old"}},
+			},
+		},
+		{
+			FolderPath: "[datastore1] disks",
+			File: []types.BaseFileInfo{
+				&types.VmDiskFileInfo{FileInfo: types.FileInfo{Path: "disk.vmdk", FileSize: 512}},
+			},
+		},
+	}
+	expected := []map[string]interface{}{
+		{
+			"path":           "disks/disk.vmdk",
+			"datastore_path": "[datastore1] disks/disk.vmdk",
+			"size":           512,
+			"type":           "vmDisk",
+		},
+		{
+			"path":              "isos/centos.iso",
+			"datastore_path":    "[datastore1] isos/centos.iso",
+			"size":              1024,
+			"type":              "isoImage",
+			"modification_time": "2017-10-01T12:00:00Z",
+		},
+		{
+			"path":           "isos/old",
+			"datastore_path": "[datastore1] isos/old",
+			"size":           0,
+			"type":           "folder",
+		},
+	}
+	actual := flattenDatastoreFiles("datastore1", results)
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("expected %#v, got %#v", expected, actual)
+	}
+}
@@ -0,0 +1,86 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccDataSourceVSphereDatastore(t *testing.T) {
+	var tp *testing.T
+	testAccDataSourceVSphereDatastoreCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"basic",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccDataSourceVSphereDatastorePreCheck(tp)
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: testAccDataSourceVSphereDatastoreConfig(os.Getenv("VSPHERE_DATASTORE")),
+						Check: resource.ComposeTestCheckFunc(
+							resource.TestMatchResourceAttr("data.vsphere_datastore.datastore", "id", regexp.MustCompile("^datastore-")),
+							resource.TestCheckResourceAttr("data.vsphere_datastore.datastore", "accessible", "true"),
+							resource.TestMatchResourceAttr("data.vsphere_datastore.datastore", "type", regexp.MustCompile("^(VMFS|NFS|NFS41|CIFS|VFAT|vsan|VVOL)$")),
+							resource.TestMatchResourceAttr("data.vsphere_datastore.datastore", "host_system_ids.#", regexp.MustCompile("^[1-9][0-9]*$")),
+							resource.TestMatchResourceAttr("data.vsphere_datastore.datastore", "free_space", regexp.MustCompile("^[1-9][0-9]*$")),
+						),
+					},
+				},
+			},
+		},
+		{
+			"not found",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccDataSourceVSphereDatastorePreCheck(tp)
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config:      testAccDataSourceVSphereDatastoreConfig("terraform-test-missing-datastore"),
+						ExpectError: regexp.MustCompile("error fetching datastore"),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccDataSourceVSphereDatastoreCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+func testAccDataSourceVSphereDatastorePreCheck(t *testing.T) {
+	if os.Getenv("VSPHERE_DATACENTER") == "" {
+		t.Skip("set VSPHERE_DATACENTER to run vsphere_datastore acceptance tests")
+	}
+	if os.Getenv("VSPHERE_DATASTORE") == "" {
+		t.Skip("set VSPHERE_DATASTORE to run vsphere_datastore acceptance tests")
+	}
+}
+
+func testAccDataSourceVSphereDatastoreConfig(name string) string {
+	return fmt.Sprintf(`
+data "vsphere_datacenter" "dc" {
+  name = "%s"
+}
+
+data "vsphere_datastore" "datastore" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+`, os.Getenv("VSPHERE_DATACENTER"), name)
+}
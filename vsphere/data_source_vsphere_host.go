@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/vmware/govmomi/vim25/mo"
 )
 
 func dataSourceVSphereHost() *schema.Resource {
@@ -21,6 +22,92 @@ func dataSourceVSphereHost() *schema.Resource {
 				Description: "The managed object ID of the datacenter to look for the host in.",
 				Required:    true,
 			},
+			"cluster_id": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the cluster that the host is a member of. Empty for standalone hosts.",
+				Computed:    true,
+			},
+			"connection_state": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The connection state of the host, such as connected or disconnected.",
+				Computed:    true,
+			},
+			"power_state": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The power state of the host, such as poweredOn or standBy.",
+				Computed:    true,
+			},
+			"maintenance_mode": &schema.Schema{
+				Type:        schema.TypeBool,
+				Description: "Whether or not the host is in maintenance mode.",
+				Computed:    true,
+			},
+			"version": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The product version of the host, such as 6.5.0.",
+				Computed:    true,
+			},
+			"build": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The product build number of the host.",
+				Computed:    true,
+			},
+			"cpu_model": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The model of the CPUs in the host.",
+				Computed:    true,
+			},
+			"cpu_cores": &schema.Schema{
+				Type:        schema.TypeInt,
+				Description: "The number of physical CPU cores in the host.",
+				Computed:    true,
+			},
+			"cpu_threads": &schema.Schema{
+				Type:        schema.TypeInt,
+				Description: "The number of CPU threads in the host.",
+				Computed:    true,
+			},
+			"memory_size": &schema.Schema{
+				Type:        schema.TypeInt,
+				Description: "The amount of physical memory in the host, in MB.",
+				Computed:    true,
+			},
+			"physical_nics": &schema.Schema{
+				Type:        schema.TypeList,
+				Description: "The physical network adapters of the host.",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": &schema.Schema{
+							Type:        schema.TypeString,
+							Description: "The device name of the adapter, such as vmnic0.",
+							Computed:    true,
+						},
+						"mac": &schema.Schema{
+							Type:        schema.TypeString,
+							Description: "The MAC address of the adapter.",
+							Computed:    true,
+						},
+						"link_speed": &schema.Schema{
+							Type:        schema.TypeInt,
+							Description: "The link speed of the adapter, in Mbit/s. 0 if the link is down.",
+							Computed:    true,
+						},
+					},
+				},
+			},
+			"datastore_ids": &schema.Schema{
+				Type:        schema.TypeList,
+				Description: "The managed object IDs of the datastores attached to the host.",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"network_ids": &schema.Schema{
+				Type:        schema.TypeList,
+				Description: "The managed object IDs of the networks available on the host.",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
 		},
 	}
 }
@@ -41,5 +128,70 @@ func dataSourceVSphereHostRead(d *schema.ResourceData, meta interface{}) error {
 	id := hs.Reference().Value
 	d.SetId(id)
 
+	props, err := hostSystemProperties(hs)
+	if err != nil {
+		return fmt.Errorf("error fetching host properties: %s", err)
+	}
+	return flattenDataSourceVSphereHost(d, props)
+}
+
+// flattenDataSourceVSphereHost reads various fields from a HostSystem into
+// the passed in ResourceData.
+//
+// Some of the product, hardware, and network details are not available when
+// the host is disconnected, in which case they are left empty.
+func flattenDataSourceVSphereHost(d *schema.ResourceData, props *mo.HostSystem) error {
+	var clusterID string
+	if props.Parent != nil && props.Parent.Type == "ClusterComputeResource" {
+		clusterID = props.Parent.Value
+	}
+	d.Set("cluster_id", clusterID)
+	d.Set("connection_state", props.Runtime.ConnectionState)
+	d.Set("power_state", props.Runtime.PowerState)
+	d.Set("maintenance_mode", props.Runtime.InMaintenanceMode)
+
+	if product := props.Summary.Config.Product; product != nil {
+		d.Set("version", product.Version)
+		d.Set("build", product.Build)
+	}
+	if hw := props.Summary.Hardware; hw != nil {
+		d.Set("cpu_model", hw.CpuModel)
+		d.Set("cpu_cores", hw.NumCpuCores)
+		d.Set("cpu_threads", hw.NumCpuThreads)
+		d.Set("memory_size", hw.MemorySize/1024/1024)
+	}
+
+	var nics []map[string]interface{}
+	if props.Config != nil && props.Config.Network != nil {
+		for _, pnic := range props.Config.Network.Pnic {
+			var speed int32
+			if pnic.LinkSpeed != nil {
+				speed = pnic.LinkSpeed.SpeedMb
+			}
+			nics = append(nics, map[string]interface{}{
+				"name":       pnic.Device,
+				"mac":        pnic.Mac,
+				"link_speed": speed,
+			})
+		}
+	}
+	if err := d.Set("physical_nics", nics); err != nil {
+		return fmt.Errorf("error setting physical NICs: %s", err)
+	}
+
+	var datastoreIDs []string
+	for _, ref := range props.Datastore {
+		datastoreIDs = append(datastoreIDs, ref.Value)
+	}
+	if err := d.Set("datastore_ids", datastoreIDs); err != nil {
+		return fmt.Errorf("error setting datastore IDs: %s", err)
+	}
+	var networkIDs []string
+	for _, ref := range props.Network {
+		networkIDs = append(networkIDs, ref.Value)
+	}
+	if err := d.Set("network_ids", networkIDs); err != nil {
+		return fmt.Errorf("error setting network IDs: %s", err)
+	}
 	return nil
 }
@@ -32,6 +32,15 @@ func TestAccDataSourceVSphereHost(t *testing.T) {
 								"id",
 								testAccDataSourceVSphereHostExpectedRegexp(),
 							),
+							resource.TestCheckResourceAttr("data.vsphere_host.host", "connection_state", "connected"),
+							resource.TestCheckResourceAttr("data.vsphere_host.host", "power_state", "poweredOn"),
+							resource.TestCheckResourceAttr("data.vsphere_host.host", "maintenance_mode", "false"),
+							resource.TestMatchResourceAttr("data.vsphere_host.host", "version", regexp.MustCompile("^[0-9]+\\.[0-9]+")),
+							resource.TestMatchResourceAttr("data.vsphere_host.host", "cpu_cores", regexp.MustCompile("^[1-9][0-9]*$")),
+							resource.TestMatchResourceAttr("data.vsphere_host.host", "memory_size", regexp.MustCompile("^[1-9][0-9]*$")),
+							resource.TestMatchResourceAttr("data.vsphere_host.host", "physical_nics.#", regexp.MustCompile("^[1-9][0-9]*$")),
+							resource.TestMatchResourceAttr("data.vsphere_host.host", "physical_nics.0.name", regexp.MustCompile("^vmnic")),
+							resource.TestMatchResourceAttr("data.vsphere_host.host", "datastore_ids.#", regexp.MustCompile("^[1-9][0-9]*$")),
 						),
 					},
 				},
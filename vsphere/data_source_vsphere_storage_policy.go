@@ -0,0 +1,37 @@
+package vsphere
+
+import "github.com/hashicorp/terraform/helper/schema"
+
+func dataSourceVSphereStoragePolicy() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceVSphereStoragePolicyRead,
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Description: "The name of the storage policy.",
+				Required:    true,
+			},
+			"description": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The description of the storage policy.",
+			},
+		},
+	}
+}
+
+func dataSourceVSphereStoragePolicyRead(d *schema.ResourceData, meta interface{}) error {
+	client, err := meta.(*VSphereClient).PbmClient()
+	if err != nil {
+		return err
+	}
+
+	profile, err := storagePolicyByName(client, d.Get("name").(string))
+	if err != nil {
+		return err
+	}
+
+	d.SetId(profile.ProfileId.UniqueId)
+	d.Set("description", profile.Description)
+	return nil
+}
@@ -0,0 +1,78 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccDataSourceVSphereStoragePolicy(t *testing.T) {
+	var tp *testing.T
+	testAccDataSourceVSphereStoragePolicyCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"basic",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccDataSourceVSphereStoragePolicyPreCheck(tp)
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: testAccDataSourceVSphereStoragePolicyConfig(os.Getenv("VSPHERE_STORAGE_POLICY")),
+						Check: resource.ComposeTestCheckFunc(
+							resource.TestMatchResourceAttr(
+								"data.vsphere_storage_policy.policy",
+								"id",
+								regexp.MustCompile("^[0-9a-f-]+$"),
+							),
+						),
+					},
+				},
+			},
+		},
+		{
+			"not found",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccDataSourceVSphereStoragePolicyPreCheck(tp)
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config:      testAccDataSourceVSphereStoragePolicyConfig("terraform-test-missing-policy"),
+						ExpectError: regexp.MustCompile("could not find storage policy"),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccDataSourceVSphereStoragePolicyCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+func testAccDataSourceVSphereStoragePolicyPreCheck(t *testing.T) {
+	if os.Getenv("VSPHERE_STORAGE_POLICY") == "" {
+		t.Skip("set VSPHERE_STORAGE_POLICY to run vsphere_storage_policy acceptance tests")
+	}
+}
+
+func testAccDataSourceVSphereStoragePolicyConfig(name string) string {
+	return fmt.Sprintf(`
+data "vsphere_storage_policy" "policy" {
+  name = "%s"
+}
+`, name)
+}
@@ -0,0 +1,213 @@
+package vsphere
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func dataSourceVSphereVirtualMachine() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceVSphereVirtualMachineRead,
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Description: "The name or path of the virtual machine.",
+				Required:    true,
+			},
+			"datacenter_id": {
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the datacenter to look for the virtual machine in.",
+				Required:    true,
+			},
+			"uuid": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The UUID of the virtual machine.",
+			},
+			"guest_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The guest ID of the virtual machine.",
+			},
+			"alternate_guest_name": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The guest name of the virtual machine when guest_id is otherGuest or otherGuest64.",
+			},
+			"firmware": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The firmware type of the virtual machine. Can be one of bios or efi.",
+			},
+			"disks": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The virtual disks attached to the virtual machine, in device order.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"label": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The label of the disk.",
+						},
+						"size": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "The size of the disk, in GB.",
+						},
+						"thin_provisioned": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "True if the disk is thin provisioned.",
+						},
+						"eagerly_scrub": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "True if the disk is eager zeroed thick.",
+						},
+						"unit_number": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "The unit number of the disk on its controller.",
+						},
+						"controller_type": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The type of the controller the disk is attached to, in the format of the controller_type disk argument of the vsphere_virtual_machine resource.",
+						},
+					},
+				},
+			},
+			"network_interface_types": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The adapter types of the network interfaces on the virtual machine, in device order.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"snapshots": schemaVirtualMachineSnapshotTree(),
+		},
+	}
+}
+
+func dataSourceVSphereVirtualMachineRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	name := d.Get("name").(string)
+	dc, err := datacenterFromID(client, d.Get("datacenter_id").(string))
+	if err != nil {
+		return fmt.Errorf("error fetching datacenter: %s", err)
+	}
+	vm, err := virtualMachineFromPath(client, name, dc)
+	if err != nil {
+		return fmt.Errorf("error fetching virtual machine: %s", err)
+	}
+	props, err := virtualMachineProperties(vm)
+	if err != nil {
+		return fmt.Errorf("error fetching virtual machine properties: %s", err)
+	}
+	if props.Config == nil {
+		return fmt.Errorf("no configuration returned for virtual machine %q", vm.InventoryPath)
+	}
+
+	d.SetId(props.Config.Uuid)
+	d.Set("uuid", props.Config.Uuid)
+	d.Set("guest_id", props.Config.GuestId)
+	d.Set("alternate_guest_name", props.Config.AlternateGuestName)
+	d.Set("firmware", props.Config.Firmware)
+	devices := object.VirtualDeviceList(props.Config.Hardware.Device)
+	if err := d.Set("disks", flattenVirtualMachineDisks(devices)); err != nil {
+		return fmt.Errorf("error setting disks: %s", err)
+	}
+	if err := d.Set("network_interface_types", flattenVirtualMachineNetworkInterfaceTypes(devices)); err != nil {
+		return fmt.Errorf("error setting network_interface_types: %s", err)
+	}
+	var snapshots []map[string]interface{}
+	if props.Snapshot != nil {
+		snapshots = flattenVirtualMachineSnapshotTrees(props.Snapshot.RootSnapshotList, "")
+	}
+	if err := d.Set("snapshots", snapshots); err != nil {
+		return fmt.Errorf("error setting snapshots: %s", err)
+	}
+	return nil
+}
+
+// flattenVirtualMachineDisks returns the virtual disks in a device list.
+func flattenVirtualMachineDisks(devices object.VirtualDeviceList) []map[string]interface{} {
+	var disks []map[string]interface{}
+	for _, device := range devices.SelectByType((*types.VirtualDisk)(nil)) {
+		disk := device.(*types.VirtualDisk)
+		size := disk.CapacityInBytes
+		if size == 0 {
+			size = disk.CapacityInKB * 1024
+		}
+		m := map[string]interface{}{
+			"size":            int(size / 1024 / 1024 / 1024),
+			"controller_type": virtualDiskControllerType(devices.FindByKey(disk.ControllerKey)),
+		}
+		if disk.DeviceInfo != nil {
+			m["label"] = disk.DeviceInfo.GetDescription().Label
+		}
+		if disk.UnitNumber != nil {
+			m["unit_number"] = int(*disk.UnitNumber)
+		}
+		if backing, ok := disk.Backing.(*types.VirtualDiskFlatVer2BackingInfo); ok {
+			m["thin_provisioned"] = backing.ThinProvisioned != nil && *backing.ThinProvisioned
+			m["eagerly_scrub"] = backing.EagerlyScrub != nil && *backing.EagerlyScrub
+		}
+		disks = append(disks, m)
+	}
+	return disks
+}
+
+// virtualDiskControllerType returns the controller type of a disk controller,
+// using the values of the controller_type disk argument in the
+// vsphere_virtual_machine resource where possible.
+func virtualDiskControllerType(controller types.BaseVirtualDevice) string {
+	switch controller.(type) {
+	case nil:
+		return ""
+	case *types.VirtualLsiLogicController:
+		return "scsi-lsi-parallel"
+	case *types.VirtualBusLogicController:
+		return "scsi-buslogic"
+	case *types.ParaVirtualSCSIController:
+		return "scsi-paravirtual"
+	case *types.VirtualLsiLogicSASController:
+		return "scsi-lsi-sas"
+	case *types.VirtualIDEController:
+		return "ide"
+	case *types.VirtualAHCIController:
+		return "sata"
+	}
+	return object.VirtualDeviceList{}.Type(controller)
+}
+
+// flattenVirtualMachineNetworkInterfaceTypes returns the adapter types of the
+// network interfaces in a device list.
+func flattenVirtualMachineNetworkInterfaceTypes(devices object.VirtualDeviceList) []string {
+	var nicTypes []string
+	for _, device := range devices.SelectByType((*types.VirtualEthernetCard)(nil)) {
+		var t string
+		switch device.(type) {
+		case *types.VirtualVmxnet3:
+			t = "vmxnet3"
+		case *types.VirtualVmxnet2:
+			t = "vmxnet2"
+		case *types.VirtualE1000:
+			t = "e1000"
+		case *types.VirtualE1000e:
+			t = "e1000e"
+		case *types.VirtualPCNet32:
+			t = "pcnet32"
+		case *types.VirtualSriovEthernetCard:
+			t = "sriov"
+		default:
+			t = devices.TypeName(device)
+		}
+		nicTypes = append(nicTypes, t)
+	}
+	return nicTypes
+}
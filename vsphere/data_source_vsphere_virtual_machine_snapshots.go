@@ -0,0 +1,55 @@
+package vsphere
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func dataSourceVSphereVirtualMachineSnapshots() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceVSphereVirtualMachineSnapshotsRead,
+
+		Schema: map[string]*schema.Schema{
+			"virtual_machine_uuid": {
+				Type:        schema.TypeString,
+				Description: "The UUID of the virtual machine.",
+				Required:    true,
+			},
+			"current_snapshot_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The managed object ID of the current snapshot of the virtual machine.",
+			},
+			"snapshots": schemaVirtualMachineSnapshotTree(),
+		},
+	}
+}
+
+func dataSourceVSphereVirtualMachineSnapshotsRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	uuid := d.Get("virtual_machine_uuid").(string)
+	vm, err := virtualMachineFromUUID(client, uuid)
+	if err != nil {
+		return fmt.Errorf("error fetching virtual machine: %s", err)
+	}
+	props, err := virtualMachineProperties(vm)
+	if err != nil {
+		return fmt.Errorf("error fetching virtual machine properties: %s", err)
+	}
+
+	d.SetId(uuid)
+	var current string
+	var snapshots []map[string]interface{}
+	if props.Snapshot != nil {
+		if props.Snapshot.CurrentSnapshot != nil {
+			current = props.Snapshot.CurrentSnapshot.Value
+		}
+		snapshots = flattenVirtualMachineSnapshotTrees(props.Snapshot.RootSnapshotList, "")
+	}
+	d.Set("current_snapshot_id", current)
+	if err := d.Set("snapshots", snapshots); err != nil {
+		return fmt.Errorf("error setting snapshots: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package vsphere
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccDataSourceVSphereVirtualMachineSnapshots(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachineSnapshotPreCheck(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceVSphereVirtualMachineSnapshotsConfig(),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.vsphere_virtual_machine_snapshots.snapshots", "snapshots.#", "1"),
+					resource.TestCheckResourceAttr("data.vsphere_virtual_machine_snapshots.snapshots", "snapshots.0.name", "terraform-test-snapshot"),
+					resource.TestCheckResourceAttr("data.vsphere_virtual_machine_snapshots.snapshots", "snapshots.0.parent_id", ""),
+					resource.TestCheckResourceAttr("data.vsphere_virtual_machine_snapshots.snapshots", "snapshots.0.memory", "true"),
+					resource.TestCheckResourceAttrPair(
+						"data.vsphere_virtual_machine_snapshots.snapshots", "current_snapshot_id",
+						"vsphere_virtual_machine_snapshot.snapshot", "id",
+					),
+				),
+			},
+		},
+	})
+}
+
+func testAccDataSourceVSphereVirtualMachineSnapshotsConfig() string {
+	return fmt.Sprintf(`
+%s
+
+data "vsphere_virtual_machine_snapshots" "snapshots" {
+  virtual_machine_uuid = "${vsphere_virtual_machine_snapshot.snapshot.0.virtual_machine_uuid}"
+}
+`, testAccResourceVSphereVirtualMachineSnapshotConfig(true, "Managed by Terraform", ""))
+}
@@ -0,0 +1,120 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestAccDataSourceVSphereVirtualMachine(t *testing.T) {
+	var tp *testing.T
+	testAccDataSourceVSphereVirtualMachineCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"basic",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccDataSourceVSphereVirtualMachinePreCheck(tp)
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: testAccDataSourceVSphereVirtualMachineConfig(),
+						Check: resource.ComposeTestCheckFunc(
+							resource.TestMatchResourceAttr("data.vsphere_virtual_machine.template", "id", regexp.MustCompile("^[0-9a-f-]+$")),
+							resource.TestCheckResourceAttrPair(
+								"data.vsphere_virtual_machine.template", "id",
+								"data.vsphere_virtual_machine.template", "uuid",
+							),
+							resource.TestMatchResourceAttr("data.vsphere_virtual_machine.template", "guest_id", regexp.MustCompile(".+")),
+							resource.TestMatchResourceAttr("data.vsphere_virtual_machine.template", "firmware", regexp.MustCompile("^(bios|efi)$")),
+							resource.TestMatchResourceAttr("data.vsphere_virtual_machine.template", "disks.#", regexp.MustCompile("^[1-9][0-9]*$")),
+							resource.TestMatchResourceAttr("data.vsphere_virtual_machine.template", "disks.0.size", regexp.MustCompile("^[1-9][0-9]*$")),
+							resource.TestMatchResourceAttr("data.vsphere_virtual_machine.template", "network_interface_types.#", regexp.MustCompile("^[0-9]+$")),
+						),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccDataSourceVSphereVirtualMachineCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+func testAccDataSourceVSphereVirtualMachinePreCheck(t *testing.T) {
+	if os.Getenv("VSPHERE_DATACENTER") == "" {
+		t.Skip("set VSPHERE_DATACENTER to run vsphere_virtual_machine data source acceptance tests")
+	}
+	if os.Getenv("VSPHERE_TEMPLATE") == "" {
+		t.Skip("set VSPHERE_TEMPLATE to run vsphere_virtual_machine data source acceptance tests")
+	}
+}
+
+func testAccDataSourceVSphereVirtualMachineConfig() string {
+	return fmt.Sprintf(`
+data "vsphere_datacenter" "dc" {
+  name = "%s"
+}
+
+data "vsphere_virtual_machine" "template" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+`, os.Getenv("VSPHERE_DATACENTER"), os.Getenv("VSPHERE_TEMPLATE"))
+}
+
+func TestFlattenVirtualMachineDisks(t *testing.T) {
+	unit := int32(1)
+	devices := object.VirtualDeviceList{
+		&types.ParaVirtualSCSIController{
+			VirtualSCSIController: types.VirtualSCSIController{
+				VirtualController: types.VirtualController{
+					VirtualDevice: types.VirtualDevice{Key: 1000},
+				},
+			},
+		},
+		&types.VirtualDisk{
+			VirtualDevice: types.VirtualDevice{
+				Key:           2001,
+				ControllerKey: 1000,
+				UnitNumber:    &unit,
+				DeviceInfo:    &types.Description{Label: "Hard disk 1"},
+				Backing: &types.VirtualDiskFlatVer2BackingInfo{
+					ThinProvisioned: boolPtr(true),
+				},
+			},
+			CapacityInKB: 20 * 1024 * 1024,
+		},
+		&types.VirtualVmxnet3{},
+	}
+	expected := []map[string]interface{}{
+		{
+			"label":            "Hard disk 1",
+			"size":             20,
+			"unit_number":      1,
+			"controller_type":  "scsi-paravirtual",
+			"thin_provisioned": true,
+			"eagerly_scrub":    false,
+		},
+	}
+	if actual := flattenVirtualMachineDisks(devices); !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("expected %#v, got %#v", expected, actual)
+	}
+	if actual := flattenVirtualMachineNetworkInterfaceTypes(devices); !reflect.DeepEqual([]string{"vmxnet3"}, actual) {
+		t.Fatalf("expected vmxnet3, got %#v", actual)
+	}
+}
@@ -31,6 +31,21 @@ func datastoreFromID(client *govmomi.Client, id string) (*object.Datastore, erro
 	return ds.(*object.Datastore), nil
 }
 
+// datastoreFromPath loads a datastore via its path.
+//
+// Datacenter is optional here - if not provided, it's expected that the path
+// is sufficient enough for finder to determine the datacenter required.
+func datastoreFromPath(client *govmomi.Client, name string, dc *object.Datacenter) (*object.Datastore, error) {
+	finder := find.NewFinder(client.Client, false)
+	if dc != nil {
+		finder.SetDatacenter(dc)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return finder.Datastore(ctx, name)
+}
+
 // datastoreProperties is a convenience method that wraps fetching the
 // Datastore MO from its higher-level object.
 func datastoreProperties(ds *object.Datastore) (*mo.Datastore, error) {
@@ -0,0 +1,340 @@
+package vsphere
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// localDirectoryManifest walks a local directory and returns the SHA256
+// checksums of the files in it that match the supplied patterns, keyed by
+// their slash-separated path relative to the directory.
+func localDirectoryManifest(root string, include, exclude []string) (map[string]string, error) {
+	manifest := make(map[string]string)
+	err := filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		ok, err := matchFilePatterns(rel, include, exclude)
+		if err != nil || !ok {
+			return err
+		}
+		sum, err := fileSHA256(p)
+		if err != nil {
+			return err
+		}
+		manifest[rel] = sum
+		return nil
+	})
+	return manifest, err
+}
+
+// matchFilePatterns checks a slash-separated relative path against lists of
+// include and exclude glob patterns. A pattern matches if it matches either
+// the whole path or its base name. Paths are included if there are no include
+// patterns, or if any include pattern matches, and no exclude pattern
+// matches.
+func matchFilePatterns(rel string, include, exclude []string) (bool, error) {
+	matches := func(patterns []string) (bool, error) {
+		for _, pattern := range patterns {
+			for _, name := range []string{rel, path.Base(rel)} {
+				ok, err := path.Match(pattern, name)
+				if err != nil {
+					return false, fmt.Errorf("invalid pattern %q: %s", pattern, err)
+				}
+				if ok {
+					return true, nil
+				}
+			}
+		}
+		return false, nil
+	}
+	if len(include) > 0 {
+		ok, err := matches(include)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	ok, err := matches(exclude)
+	return !ok, err
+}
+
+// directoryManifestChanges compares a manifest of local files with the
+// manifest from the last upload, returning the files that need to be uploaded
+// and the files that need to be removed from the datastore, both sorted.
+func directoryManifestChanges(local, previous map[string]string) ([]string, []string) {
+	var upload, remove []string
+	for rel, sum := range local {
+		if previous[rel] != sum {
+			upload = append(upload, rel)
+		}
+	}
+	for rel := range previous {
+		if _, ok := local[rel]; !ok {
+			remove = append(remove, rel)
+		}
+	}
+	sort.Strings(upload)
+	sort.Strings(remove)
+	return upload, remove
+}
+
+// syncDirectoryToDatastore mirrors the files in a local manifest to a folder on
+// a datastore. Only files that are new or have changed since the previous
+// manifest are uploaded, and files in the previous manifest that are no longer
+// present locally are deleted. Uploads run in parallel, up to concurrency at a
+// time.
+func syncDirectoryToDatastore(client *govmomi.Client, dc *object.Datacenter, ds *object.Datastore, root, dest string, local, previous map[string]string, concurrency int) error {
+	upload, remove := directoryManifestChanges(local, previous)
+	fm := object.NewFileManager(client.Client)
+
+	// Create the destination folder and any intermediate folders first, parents
+	// before children.
+	dirs := map[string]struct{}{dest: {}}
+	for _, rel := range upload {
+		dirs[path.Join(dest, path.Dir(rel))] = struct{}{}
+	}
+	var sortedDirs []string
+	for dir := range dirs {
+		sortedDirs = append(sortedDirs, dir)
+	}
+	sort.Strings(sortedDirs)
+	for _, dir := range sortedDirs {
+		if err := makeDatastoreDirectory(fm, dc, ds, dir); err != nil {
+			return err
+		}
+	}
+
+	if err := uploadFilesToDatastore(client, dc, ds, root, dest, upload, concurrency); err != nil {
+		return err
+	}
+
+	for _, rel := range remove {
+		if err := deleteDatastoreFile(fm, dc, ds, path.Join(dest, rel)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteDirectoryFromDatastore removes the files in a manifest from a folder on
+// a datastore. Folders that were created for the files, and the folder itself,
+// are then removed if nothing else is left in them, so that files that were not
+// uploaded by Terraform are never deleted.
+func deleteDirectoryFromDatastore(client *govmomi.Client, dc *object.Datacenter, ds *object.Datastore, dest string, manifest map[string]string) error {
+	fm := object.NewFileManager(client.Client)
+	dirs := map[string]struct{}{dest: {}}
+	for rel := range manifest {
+		if err := deleteDatastoreFile(fm, dc, ds, path.Join(dest, rel)); err != nil {
+			return err
+		}
+		for dir := path.Dir(rel); dir != "."; dir = path.Dir(dir) {
+			dirs[path.Join(dest, dir)] = struct{}{}
+		}
+	}
+
+	// Children sort after their parents, so walk the folders in reverse order.
+	var sortedDirs []string
+	for dir := range dirs {
+		sortedDirs = append(sortedDirs, dir)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(sortedDirs)))
+	for _, dir := range sortedDirs {
+		empty, err := datastoreDirectoryIsEmpty(ds, dir)
+		if err != nil {
+			return fmt.Errorf("error listing folder %s: %s", dir, err)
+		}
+		if !empty {
+			log.Printf("[DEBUG] Folder %s on datastore %q is not empty, leaving it in place", dir, ds.Name())
+			continue
+		}
+		if err := deleteDatastoreFile(fm, dc, ds, dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteDatastoreFile removes a file or folder from a datastore. A file that
+// no longer exists is not an error.
+func deleteDatastoreFile(fm *object.FileManager, dc *object.Datacenter, ds *object.Datastore, p string) error {
+	log.Printf("[DEBUG] Removing %s from datastore %q", p, ds.Name())
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	task, err := fm.DeleteDatastoreFile(ctx, ds.Path(p), dc)
+	if err == nil {
+		err = task.Wait(ctx)
+	}
+	if err != nil && !isFileNotFoundError(err) {
+		return fmt.Errorf("error removing %s: %s", p, err)
+	}
+	return nil
+}
+
+// datastoreDirectoryIsEmpty checks if a folder on a datastore has no files or
+// folders in it. A folder that does not exist is reported as not empty, so
+// that nothing is done with it.
+func datastoreDirectoryIsEmpty(ds *object.Datastore, dir string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	browser, err := ds.Browser(ctx)
+	if err != nil {
+		return false, err
+	}
+	task, err := browser.SearchDatastore(ctx, ds.Path(dir), &types.HostDatastoreBrowserSearchSpec{})
+	if err != nil {
+		return false, err
+	}
+	info, err := task.WaitForResult(ctx, nil)
+	if err != nil {
+		if isFileNotFoundError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	results, ok := info.Result.(types.HostDatastoreBrowserSearchResults)
+	if !ok {
+		return false, fmt.Errorf("unexpected search result type %T", info.Result)
+	}
+	return len(results.File) == 0, nil
+}
+
+// datastoreDirectoryFiles lists the files under a folder on a datastore,
+// including the ones in sub-folders, keyed by their slash-separated path
+// relative to the folder.
+func datastoreDirectoryFiles(ds *object.Datastore, dir string) (map[string]*types.FileInfo, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	browser, err := ds.Browser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	spec := &types.HostDatastoreBrowserSearchSpec{
+		Details: &types.FileQueryFlags{
+			FileType:     true,
+			FileSize:     true,
+			Modification: true,
+		},
+	}
+	task, err := browser.SearchDatastoreSubFolders(ctx, ds.Path(dir), spec)
+	if err != nil {
+		return nil, err
+	}
+	info, err := task.WaitForResult(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	results, ok := info.Result.(types.ArrayOfHostDatastoreBrowserSearchResults)
+	if !ok {
+		return nil, fmt.Errorf("unexpected search result type %T", info.Result)
+	}
+	files := make(map[string]*types.FileInfo)
+	for _, result := range results.HostDatastoreBrowserSearchResults {
+		var folder object.DatastorePath
+		if !folder.FromString(result.FolderPath) {
+			return nil, fmt.Errorf("unexpected folder path %q", result.FolderPath)
+		}
+		for _, bfi := range result.File {
+			if _, ok := bfi.(*types.FolderFileInfo); ok {
+				continue
+			}
+			fi := bfi.GetFileInfo()
+			rel := strings.TrimPrefix(path.Join(folder.Path, fi.Path), path.Clean(dir)+"/")
+			files[rel] = fi
+		}
+	}
+	return files, nil
+}
+
+// fileManifestSHA256 returns a single hex-encoded SHA256 checksum for a
+// manifest, covering both the paths and the checksums of the files in it.
+func fileManifestSHA256(manifest map[string]string) string {
+	var paths []string
+	for rel := range manifest {
+		paths = append(paths, rel)
+	}
+	sort.Strings(paths)
+	h := sha256.New()
+	for _, rel := range paths {
+		fmt.Fprintf(h, "%s  %s\n", manifest[rel], rel)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// makeDatastoreDirectory creates a folder on a datastore, along with any
+// missing parents. A folder that already exists is not an error.
+func makeDatastoreDirectory(fm *object.FileManager, dc *object.Datacenter, ds *object.Datastore, dir string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	if err := fm.MakeDirectory(ctx, ds.Path(dir), dc, true); err != nil && !isFileAlreadyExistsError(err) {
+		return fmt.Errorf("error creating folder %s: %s", dir, err)
+	}
+	return nil
+}
+
+// uploadFilesToDatastore uploads files relative to root into the dest folder
+// on a datastore, running up to concurrency uploads at a time. The first error
+// encountered is returned once all started uploads have finished.
+func uploadFilesToDatastore(client *govmomi.Client, dc *object.Datacenter, ds *object.Datastore, root, dest string, files []string, concurrency int) error {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var firstErr error
+	sem := make(chan struct{}, concurrency)
+	for _, rel := range files {
+		mu.Lock()
+		failed := firstErr != nil
+		mu.Unlock()
+		if failed {
+			break
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(rel string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			p := path.Join(dest, rel)
+			log.Printf("[DEBUG] Uploading %s to datastore %q", p, ds.Name())
+			err := uploadFileToDatastore(client, dc, ds, filepath.Join(root, filepath.FromSlash(rel)), p)
+			if err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = fmt.Errorf("error uploading %s: %s", rel, err)
+				}
+				mu.Unlock()
+			}
+		}(rel)
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// uploadFileToDatastore uploads a single local file to a path on a datastore.
+func uploadFileToDatastore(client *govmomi.Client, dc *object.Datacenter, ds *object.Datastore, src, dst string) error {
+	dsurl, err := ds.URL(context.TODO(), dc, dst)
+	if err != nil {
+		return err
+	}
+	p := soap.DefaultUpload
+	return client.Client.UploadFile(src, dsurl, &p)
+}
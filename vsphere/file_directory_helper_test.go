@@ -0,0 +1,132 @@
+package vsphere
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMatchFilePatterns(t *testing.T) {
+	cases := []struct {
+		name     string
+		rel      string
+		include  []string
+		exclude  []string
+		expected bool
+	}{
+		{
+			name:     "no patterns",
+			rel:      "ks/base.cfg",
+			expected: true,
+		},
+		{
+			name:     "include by base name",
+			rel:      "isos/centos.iso",
+			include:  []string{"*.iso"},
+			expected: true,
+		},
+		{
+			name:     "include by path",
+			rel:      "isos/centos.iso",
+			include:  []string{"ks/*"},
+			expected: false,
+		},
+		{
+			name:     "exclude wins over include",
+			rel:      "ks/base.cfg.bak",
+			include:  []string{"ks/*"},
+			exclude:  []string{"*.bak"},
+			expected: false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := matchFilePatterns(tc.rel, tc.include, tc.exclude)
+			if err != nil {
+				t.Fatalf("bad: %s", err)
+			}
+			if actual != tc.expected {
+				t.Fatalf("expected %t, got %t", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestDirectoryManifestChanges(t *testing.T) {
+	previous := map[string]string{
+		"a.cfg":       "1",
+		"b.cfg":       "2",
+		"sub/old.cfg": "3",
+	}
+	local := map[string]string{
+		"a.cfg":       "1",
+		"b.cfg":       "changed",
+		"sub/new.cfg": "4",
+	}
+	upload, remove := directoryManifestChanges(local, previous)
+	if expected := []string{"b.cfg", "sub/new.cfg"}; !reflect.DeepEqual(expected, upload) {
+		t.Fatalf("expected upload to be %#v, got %#v", expected, upload)
+	}
+	if expected := []string{"sub/old.cfg"}; !reflect.DeepEqual(expected, remove) {
+		t.Fatalf("expected remove to be %#v, got %#v", expected, remove)
+	}
+}
+
+func TestLocalDirectoryManifest(t *testing.T) {
+	root, err := ioutil.TempDir("", "tf-vsphere-file")
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	defer os.RemoveAll(root)
+	files := map[string]string{
+		"ks.cfg":          "kickstart\n",
+		"isos/centos.iso": "iso\n",
+		"notes.txt":       "notes\n",
+	}
+	for rel, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatalf("bad: %s", err)
+		}
+		if err := ioutil.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatalf("bad: %s", err)
+		}
+	}
+
+	actual, err := localDirectoryManifest(root, nil, []string{"*.txt"})
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	expected := map[string]string{
+		"ks.cfg":          testAccVSphereFileSHA256("kickstart\n"),
+		"isos/centos.iso": testAccVSphereFileSHA256("iso\n"),
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestFileManifestSHA256(t *testing.T) {
+	manifest := map[string]string{
+		"ks.cfg":          testAccVSphereFileSHA256("kickstart\n"),
+		"isos/centos.iso": testAccVSphereFileSHA256("iso\n"),
+	}
+	expected := testAccVSphereFileSHA256(
+		testAccVSphereFileSHA256("iso\n") + "  isos/centos.iso\n" +
+			testAccVSphereFileSHA256("kickstart\n") + "  ks.cfg\n",
+	)
+	if actual := fileManifestSHA256(manifest); actual != expected {
+		t.Fatalf("expected %s, got %s", expected, actual)
+	}
+
+	renamed := map[string]string{
+		"ks.cfg":         manifest["ks.cfg"],
+		"isos/other.iso": manifest["isos/centos.iso"],
+	}
+	if fileManifestSHA256(renamed) == expected {
+		t.Fatalf("expected checksum to change when a file is renamed")
+	}
+}
@@ -176,6 +176,8 @@ func folderFromObject(client *govmomi.Client, obj interface{}, folderType rootPa
 		p, err = rootPathParticleDatastore.PathFromNewRoot(o.InventoryPath, folderType, relative)
 	case *object.HostSystem:
 		p, err = rootPathParticleHost.PathFromNewRoot(o.InventoryPath, folderType, relative)
+	case *object.ResourcePool:
+		p, err = rootPathParticleHost.PathFromNewRoot(o.InventoryPath, folderType, relative)
 	default:
 		return nil, fmt.Errorf("unsupported object type %T", o)
 	}
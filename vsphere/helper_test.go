@@ -5,12 +5,14 @@ import (
 	"fmt"
 	"os"
 	"regexp"
+	"sort"
 	"testing"
 	"time"
 
 	"github.com/hashicorp/terraform/helper/resource"
 	"github.com/hashicorp/terraform/terraform"
 	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/find"
 	"github.com/vmware/govmomi/object"
 	"github.com/vmware/govmomi/vim25/mo"
 	"github.com/vmware/govmomi/vim25/types"
@@ -117,6 +119,26 @@ func testGetPortGroup(s *terraform.State, resourceName string) (*types.HostPortG
 	return hostPortGroupFromName(tVars.client, ns, name)
 }
 
+// testGetVNIC is a convenience method to fetch a VMkernel adapter by resource
+// name. nil is returned if the adapter does not exist.
+func testGetVNIC(s *terraform.State, resourceName string) (*types.HostVirtualNic, error) {
+	tVars, err := testClientVariablesForResource(s, fmt.Sprintf("vsphere_vnic.%s", resourceName))
+	if err != nil {
+		return nil, err
+	}
+
+	hsID, device, err := splitHostVirtualNicID(tVars.resourceID)
+	if err != nil {
+		return nil, err
+	}
+	ns, err := hostNetworkSystemFromHostSystemID(tVars.client, hsID)
+	if err != nil {
+		return nil, fmt.Errorf("error loading host network system: %s", err)
+	}
+
+	return hostVirtualNicFromDevice(tVars.client, ns, device)
+}
+
 // testGetVirtualMachine is a convenience method to fetch a virtual machine by
 // resource name.
 func testGetVirtualMachine(s *terraform.State, resourceName string) (*object.VirtualMachine, error) {
@@ -353,3 +375,246 @@ func testGetDVPortgroupProperties(s *terraform.State, resourceName string) (*mo.
 	}
 	return dvPortgroupProperties(dvs)
 }
+
+// testGetDatastoreCluster is a convenience method to fetch a datastore cluster
+// by resource name.
+func testGetDatastoreCluster(s *terraform.State, resourceName string) (*object.StoragePod, error) {
+	tVars, err := testClientVariablesForResource(s, fmt.Sprintf("vsphere_datastore_cluster.%s", resourceName))
+	if err != nil {
+		return nil, err
+	}
+	return storagePodFromID(tVars.client, tVars.resourceID)
+}
+
+// testGetDatastoreClusterProperties is a convenience method that adds an extra
+// step to testGetDatastoreCluster to get the properties of a datastore
+// cluster.
+func testGetDatastoreClusterProperties(s *terraform.State, resourceName string) (*mo.StoragePod, error) {
+	pod, err := testGetDatastoreCluster(s, resourceName)
+	if err != nil {
+		return nil, err
+	}
+	return storagePodProperties(pod)
+}
+
+// testGetComputeCluster is a convenience method to fetch a compute cluster by
+// resource name.
+func testGetComputeCluster(s *terraform.State, resourceName string) (*object.ClusterComputeResource, error) {
+	tVars, err := testClientVariablesForResource(s, fmt.Sprintf("vsphere_compute_cluster.%s", resourceName))
+	if err != nil {
+		return nil, err
+	}
+	return clusterComputeResourceFromID(tVars.client, tVars.resourceID)
+}
+
+// testGetComputeClusterProperties is a convenience method that adds an extra
+// step to testGetComputeCluster to get the properties of a compute cluster.
+func testGetComputeClusterProperties(s *terraform.State, resourceName string) (*mo.ClusterComputeResource, error) {
+	cluster, err := testGetComputeCluster(s, resourceName)
+	if err != nil {
+		return nil, err
+	}
+	return clusterComputeResourceProperties(cluster)
+}
+
+// testGetHost is a convenience method to fetch a host by resource name.
+//
+// This looks up the host directly instead of using hostSystemFromID, so that
+// the returned error can be checked with isAnyNotFoundError.
+func testGetHost(s *terraform.State, resourceName string) (*object.HostSystem, error) {
+	tVars, err := testClientVariablesForResource(s, fmt.Sprintf("vsphere_host.%s", resourceName))
+	if err != nil {
+		return nil, err
+	}
+	finder := find.NewFinder(tVars.client.Client, false)
+	ref := types.ManagedObjectReference{
+		Type:  "HostSystem",
+		Value: tVars.resourceID,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	host, err := finder.ObjectReference(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	return host.(*object.HostSystem), nil
+}
+
+// testGetHostProperties is a convenience method that adds an extra step to
+// testGetHost to get the properties of a host.
+func testGetHostProperties(s *terraform.State, resourceName string) (*mo.HostSystem, error) {
+	host, err := testGetHost(s, resourceName)
+	if err != nil {
+		return nil, err
+	}
+	return hostSystemProperties(host)
+}
+
+// testGetResourcePool is a convenience method to fetch a resource pool by
+// resource name.
+func testGetResourcePool(s *terraform.State, resourceName string) (*object.ResourcePool, error) {
+	tVars, err := testClientVariablesForResource(s, fmt.Sprintf("vsphere_resource_pool.%s", resourceName))
+	if err != nil {
+		return nil, err
+	}
+	return resourcePoolFromID(tVars.client, tVars.resourceID)
+}
+
+// testGetResourcePoolProperties is a convenience method that adds an extra
+// step to testGetResourcePool to get the properties of a resource pool.
+func testGetResourcePoolProperties(s *terraform.State, resourceName string) (*mo.ResourcePool, error) {
+	pool, err := testGetResourcePool(s, resourceName)
+	if err != nil {
+		return nil, err
+	}
+	return resourcePoolProperties(pool)
+}
+
+// testGetVAppContainer is a convenience method to fetch a vApp container by
+// resource name.
+func testGetVAppContainer(s *terraform.State, resourceName string) (*object.VirtualApp, error) {
+	tVars, err := testClientVariablesForResource(s, fmt.Sprintf("vsphere_vapp_container.%s", resourceName))
+	if err != nil {
+		return nil, err
+	}
+	return virtualAppFromID(tVars.client, tVars.resourceID)
+}
+
+// testGetVAppContainerProperties is a convenience method that adds an extra
+// step to testGetVAppContainer to get the properties of a vApp container.
+func testGetVAppContainerProperties(s *terraform.State, resourceName string) (*mo.VirtualApp, error) {
+	vapp, err := testGetVAppContainer(s, resourceName)
+	if err != nil {
+		return nil, err
+	}
+	return virtualAppProperties(vapp)
+}
+
+// testAccHostConfigPreCheck checks for the environment variables needed by
+// testAccHostConfigDataSources.
+func testAccHostConfigPreCheck(t *testing.T) {
+	if os.Getenv("VSPHERE_ESXI_HOST") == "" {
+		t.Skip("set VSPHERE_ESXI_HOST to run host configuration acceptance tests")
+	}
+}
+
+// testAccHostConfigDataSources returns the data sources for the ESXi host that
+// the host configuration resources are tested against.
+func testAccHostConfigDataSources() string {
+	return fmt.Sprintf(`
+data "vsphere_datacenter" "datacenter" {
+  name = "%s"
+}
+
+data "vsphere_host" "esxi_host" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.datacenter.id}"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_ESXI_HOST"),
+	)
+}
+
+// testAccComputeClusterMembersPreCheck checks for the environment variables
+// needed by testAccComputeClusterMembersConfig.
+func testAccComputeClusterMembersPreCheck(t *testing.T) {
+	testAccResourceVSphereHostPreCheck(t)
+	if os.Getenv("VSPHERE_NETWORK_LABEL") == "" {
+		t.Skip("set VSPHERE_NETWORK_LABEL to run cluster group and rule acceptance tests")
+	}
+	if os.Getenv("VSPHERE_TEMPLATE") == "" {
+		t.Skip("set VSPHERE_TEMPLATE to run cluster group and rule acceptance tests")
+	}
+	if os.Getenv("VSPHERE_DATASTORE") == "" {
+		t.Skip("set VSPHERE_DATASTORE to run cluster group and rule acceptance tests")
+	}
+}
+
+// testAccComputeClusterMembersConfig returns a configuration for a cluster
+// named compute_cluster, with the host in VSPHERE_ESXI_ADD_HOST added to it as
+// host, and two virtual machines in it as vm. This is used by the tests for
+// resources that work with cluster groups, rules, and overrides.
+func testAccComputeClusterMembersConfig() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+variable "template" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+resource "vsphere_compute_cluster" "compute_cluster" {
+  name          = "terraform-compute-cluster-test"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+  drs_enabled   = true
+  ha_enabled    = true
+}
+
+resource "vsphere_host" "host" {
+  hostname   = "%s"
+  username   = "%s"
+  password   = "%s"
+  thumbprint = "%s"
+  cluster_id = "${vsphere_compute_cluster.compute_cluster.id}"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  count      = 2
+  name       = "terraform-test-${count.index}"
+  datacenter = "${var.datacenter}"
+  cluster    = "${vsphere_compute_cluster.compute_cluster.name}"
+
+  vcpu   = 1
+  memory = 1024
+
+  network_interface {
+    label = "${var.network_label}"
+  }
+
+  disk {
+    datastore = "${var.datastore}"
+    template  = "${var.template}"
+  }
+
+  depends_on = ["vsphere_host.host"]
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_NETWORK_LABEL"),
+		os.Getenv("VSPHERE_TEMPLATE"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_ESXI_ADD_HOST"),
+		os.Getenv("VSPHERE_ESXI_ADD_HOST_USER"),
+		os.Getenv("VSPHERE_ESXI_ADD_HOST_PASSWORD"),
+		os.Getenv("VSPHERE_ESXI_ADD_HOST_THUMBPRINT"),
+	)
+}
+
+// testAccComputeClusterMembersVMIDs returns the sorted managed object IDs of
+// the first count virtual machines in testAccComputeClusterMembersConfig.
+func testAccComputeClusterMembersVMIDs(s *terraform.State, count int) ([]string, error) {
+	var ids []string
+	for i := 0; i < count; i++ {
+		tVars, err := testClientVariablesForResource(s, fmt.Sprintf("vsphere_virtual_machine.vm.%d", i))
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, tVars.resourceAttributes["moid"])
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
@@ -0,0 +1,42 @@
+package vsphere
+
+import (
+	"context"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// hostDateTimeSystemFromHostSystemID locates a HostDateTimeSystem from a
+// specified HostSystem managed object ID.
+func hostDateTimeSystemFromHostSystemID(client *govmomi.Client, hsID string) (*object.HostDateTimeSystem, error) {
+	hs, err := hostSystemFromID(client, hsID)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return hs.ConfigManager().DateTimeSystem(ctx)
+}
+
+// hostDateTimeSystemProperties is a convenience method that wraps fetching
+// the HostDateTimeSystem MO from its higher-level object.
+func hostDateTimeSystemProperties(dts *object.HostDateTimeSystem) (*mo.HostDateTimeSystem, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	var props mo.HostDateTimeSystem
+	if err := dts.Properties(ctx, dts.Reference(), nil, &props); err != nil {
+		return nil, err
+	}
+	return &props, nil
+}
+
+// updateHostDateTimeConfig updates the date and time configuration of a host,
+// such as its NTP servers.
+func updateHostDateTimeConfig(dts *object.HostDateTimeSystem, config types.HostDateTimeConfig) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return dts.UpdateConfig(ctx, config)
+}
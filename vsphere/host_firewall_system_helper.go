@@ -0,0 +1,70 @@
+package vsphere
+
+import (
+	"context"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// hostFirewallSystemFromHostSystemID locates a HostFirewallSystem from a
+// specified HostSystem managed object ID.
+func hostFirewallSystemFromHostSystemID(client *govmomi.Client, hsID string) (*object.HostFirewallSystem, error) {
+	hs, err := hostSystemFromID(client, hsID)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return hs.ConfigManager().FirewallSystem(ctx)
+}
+
+// hostFirewallRulesetFromKey locates a firewall ruleset on the supplied
+// HostFirewallSystem by its key. nil is returned if the ruleset does not
+// exist.
+func hostFirewallRulesetFromKey(fs *object.HostFirewallSystem, key string) (*types.HostFirewallRuleset, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	info, err := fs.Info(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, ruleset := range info.Ruleset {
+		if ruleset.Key == key {
+			return &ruleset, nil
+		}
+	}
+	return nil, nil
+}
+
+// enableHostFirewallRuleset enables a firewall ruleset.
+func enableHostFirewallRuleset(fs *object.HostFirewallSystem, key string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return fs.EnableRuleset(ctx, key)
+}
+
+// disableHostFirewallRuleset disables a firewall ruleset.
+func disableHostFirewallRuleset(fs *object.HostFirewallSystem, key string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return fs.DisableRuleset(ctx, key)
+}
+
+// updateHostFirewallRuleset updates the allowed hosts of a firewall ruleset.
+// This local implementation may go away if this is exposed in the
+// higher-level object upstream.
+func updateHostFirewallRuleset(client *govmomi.Client, fs *object.HostFirewallSystem, key string, spec types.HostFirewallRulesetRulesetSpec) error {
+	req := &types.UpdateRuleset{
+		This: fs.Reference(),
+		Id:   key,
+		Spec: spec,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	_, err := methods.UpdateRuleset(ctx, client, req)
+	return err
+}
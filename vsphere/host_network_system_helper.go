@@ -7,6 +7,7 @@ import (
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/find"
 	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/methods"
 	"github.com/vmware/govmomi/vim25/mo"
 	"github.com/vmware/govmomi/vim25/types"
 )
@@ -108,3 +109,34 @@ func networkObjectFromHostSystem(client *govmomi.Client, hs *object.HostSystem,
 
 	return nil, fmt.Errorf("could not find a matching %q on host ID %q", name, hs.Reference().Value)
 }
+
+// hostDNSConfigFromHostNetworkSystem fetches the DNS configuration of the
+// host that the supplied HostNetworkSystem belongs to.
+func hostDNSConfigFromHostNetworkSystem(client *govmomi.Client, ns *object.HostNetworkSystem) (*types.HostDnsConfig, error) {
+	var mns mo.HostNetworkSystem
+	pc := client.PropertyCollector()
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	if err := pc.RetrieveOne(ctx, ns.Reference(), []string{"dnsConfig"}, &mns); err != nil {
+		return nil, fmt.Errorf("error fetching host network properties: %s", err)
+	}
+	if mns.DnsConfig == nil {
+		return nil, fmt.Errorf("host network system %q has no DNS configuration", ns.Reference().Value)
+	}
+	return mns.DnsConfig.GetHostDnsConfig(), nil
+}
+
+// updateHostDNSConfig updates the DNS configuration of the host that the
+// supplied HostNetworkSystem belongs to. This local implementation may go
+// away if this is exposed in the higher-level object upstream.
+func updateHostDNSConfig(client *govmomi.Client, ns *object.HostNetworkSystem, config types.BaseHostDnsConfig) error {
+	req := &types.UpdateDnsConfig{
+		This:   ns.Reference(),
+		Config: config,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	_, err := methods.UpdateDnsConfig(ctx, client, req)
+	return err
+}
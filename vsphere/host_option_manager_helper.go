@@ -0,0 +1,41 @@
+package vsphere
+
+import (
+	"context"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// hostOptionManagerFromHostSystemID locates the advanced settings
+// OptionManager from a specified HostSystem managed object ID.
+func hostOptionManagerFromHostSystemID(client *govmomi.Client, hsID string) (*object.OptionManager, error) {
+	hs, err := hostSystemFromID(client, hsID)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return hs.ConfigManager().OptionManager(ctx)
+}
+
+// hostOptionManagerProperties is a convenience method that wraps fetching the
+// OptionManager MO from its higher-level object.
+func hostOptionManagerProperties(om *object.OptionManager) (*mo.OptionManager, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	var props mo.OptionManager
+	if err := om.Properties(ctx, om.Reference(), nil, &props); err != nil {
+		return nil, err
+	}
+	return &props, nil
+}
+
+// updateHostOptions updates the supplied advanced settings on a host.
+func updateHostOptions(om *object.OptionManager, values []types.BaseOptionValue) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return om.Update(ctx, values)
+}
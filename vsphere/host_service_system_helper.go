@@ -0,0 +1,59 @@
+package vsphere
+
+import (
+	"context"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// hostServiceSystemFromHostSystemID locates a HostServiceSystem from a
+// specified HostSystem managed object ID.
+func hostServiceSystemFromHostSystemID(client *govmomi.Client, hsID string) (*object.HostServiceSystem, error) {
+	hs, err := hostSystemFromID(client, hsID)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return hs.ConfigManager().ServiceSystem(ctx)
+}
+
+// hostServiceFromKey locates a service on the supplied HostServiceSystem by
+// its key. nil is returned if the service does not exist.
+func hostServiceFromKey(ss *object.HostServiceSystem, key string) (*types.HostService, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	services, err := ss.Service(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, service := range services {
+		if service.Key == key {
+			return &service, nil
+		}
+	}
+	return nil, nil
+}
+
+// updateHostServicePolicy updates the startup policy of a service.
+func updateHostServicePolicy(ss *object.HostServiceSystem, key string, policy string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return ss.UpdatePolicy(ctx, key, policy)
+}
+
+// startHostService starts a service.
+func startHostService(ss *object.HostServiceSystem, key string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return ss.Start(ctx, key)
+}
+
+// stopHostService stops a service.
+func stopHostService(ss *object.HostServiceSystem, key string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return ss.Stop(ctx, key)
+}
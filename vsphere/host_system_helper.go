@@ -3,10 +3,13 @@ package vsphere
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/find"
 	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/mo"
 	"github.com/vmware/govmomi/vim25/types"
 )
 
@@ -50,6 +53,33 @@ func hostSystemFromID(client *govmomi.Client, id string) (*object.HostSystem, er
 	return ds.(*object.HostSystem), nil
 }
 
+// hostSystemFromPath loads a HostSystem from its path.
+//
+// Datacenter is optional here - if not provided, it's expected that the path
+// is sufficient enough for finder to determine the datacenter required.
+func hostSystemFromPath(client *govmomi.Client, name string, dc *object.Datacenter) (*object.HostSystem, error) {
+	finder := find.NewFinder(client.Client, false)
+	if dc != nil {
+		finder.SetDatacenter(dc)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return finder.HostSystem(ctx, name)
+}
+
+// hostSystemProperties is a convenience method that wraps fetching the
+// HostSystem MO from its higher-level object.
+func hostSystemProperties(host *object.HostSystem) (*mo.HostSystem, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	var props mo.HostSystem
+	if err := host.Properties(ctx, host.Reference(), nil, &props); err != nil {
+		return nil, err
+	}
+	return &props, nil
+}
+
 // hostSystemNameFromID returns the name of a host via its its managed object
 // reference ID.
 func hostSystemNameFromID(client *govmomi.Client, id string) (string, error) {
@@ -70,3 +100,187 @@ func hostSystemNameOrID(client *govmomi.Client, id string) string {
 	}
 	return name
 }
+
+// addHostToCluster adds a host to a ClusterComputeResource and returns the
+// resulting HostSystem.
+func addHostToCluster(client *govmomi.Client, cluster *object.ClusterComputeResource, spec types.HostConnectSpec, connected bool) (*object.HostSystem, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	task, err := cluster.AddHost(ctx, spec, connected, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer tcancel()
+	info, err := task.WaitForResult(tctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return hostSystemFromID(client, info.Result.(types.ManagedObjectReference).Value)
+}
+
+// addStandaloneHost adds a standalone host to a host folder and returns the
+// resulting HostSystem. The ComputeResource that vSphere creates to hold the
+// host is not returned.
+func addStandaloneHost(client *govmomi.Client, folder *object.Folder, spec types.HostConnectSpec, connected bool) (*object.HostSystem, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	task, err := folder.AddStandaloneHost(ctx, spec, connected, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer tcancel()
+	info, err := task.WaitForResult(tctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	cr := object.NewComputeResource(client.Client, info.Result.(types.ManagedObjectReference))
+	var props mo.ComputeResource
+	pctx, pcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer pcancel()
+	if err := cr.Properties(pctx, cr.Reference(), []string{"host"}, &props); err != nil {
+		return nil, err
+	}
+	if len(props.Host) < 1 {
+		return nil, fmt.Errorf("compute resource %q has no hosts", cr.Reference().Value)
+	}
+	return hostSystemFromID(client, props.Host[0].Value)
+}
+
+// hostMaintenanceTaskTimeout returns the amount of time to wait for a
+// maintenance mode task, which is the supplied timeout in seconds plus the
+// default API timeout. A timeout of zero waits for the default API timeout
+// only.
+func hostMaintenanceTaskTimeout(timeout int) time.Duration {
+	return time.Duration(timeout)*time.Second + defaultAPITimeout
+}
+
+// enterHostMaintenanceMode puts a host into maintenance mode. If the host is
+// in a DRS-enabled cluster, powered on virtual machines are evacuated off of
+// the host. timeout is the amount of time, in seconds, that the operation
+// waits for the host to enter maintenance mode before failing.
+func enterHostMaintenanceMode(host *object.HostSystem, timeout int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	task, err := host.EnterMaintenanceMode(ctx, int32(timeout), true, nil)
+	if err != nil {
+		return err
+	}
+	tctx, tcancel := context.WithTimeout(context.Background(), hostMaintenanceTaskTimeout(timeout))
+	defer tcancel()
+	return task.Wait(tctx)
+}
+
+// exitHostMaintenanceMode takes a host out of maintenance mode.
+func exitHostMaintenanceMode(host *object.HostSystem, timeout int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	task, err := host.ExitMaintenanceMode(ctx, int32(timeout))
+	if err != nil {
+		return err
+	}
+	tctx, tcancel := context.WithTimeout(context.Background(), hostMaintenanceTaskTimeout(timeout))
+	defer tcancel()
+	return task.Wait(tctx)
+}
+
+// disconnectHost disconnects a host from vCenter.
+func disconnectHost(host *object.HostSystem) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	task, err := host.Disconnect(ctx)
+	if err != nil {
+		return err
+	}
+	tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer tcancel()
+	return task.Wait(tctx)
+}
+
+// reconnectHost reconnects a host to vCenter using the supplied connection
+// spec.
+func reconnectHost(host *object.HostSystem, spec types.HostConnectSpec) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	task, err := host.Reconnect(ctx, &spec, nil)
+	if err != nil {
+		return err
+	}
+	tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer tcancel()
+	return task.Wait(tctx)
+}
+
+// changeHostLockdownMode changes the lockdown mode of a host through its
+// HostAccessManager. This requires vSphere 6.0 or higher.
+func changeHostLockdownMode(client *govmomi.Client, host *object.HostSystem, mode types.HostLockdownMode) error {
+	props, err := hostSystemProperties(host)
+	if err != nil {
+		return err
+	}
+	if props.ConfigManager.HostAccessManager == nil {
+		return fmt.Errorf("host %q does not support changing lockdown mode", props.Name)
+	}
+	req := &types.ChangeLockdownMode{
+		This: *props.ConfigManager.HostAccessManager,
+		Mode: mode,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	_, err = methods.ChangeLockdownMode(ctx, client, req)
+	return err
+}
+
+// moveHostToCluster moves a host into a ClusterComputeResource. This local
+// implementation may go away if this is exposed in the higher-level object
+// upstream.
+func moveHostToCluster(client *govmomi.Client, host *object.HostSystem, cluster *object.ClusterComputeResource) error {
+	req := &types.MoveInto_Task{
+		This: cluster.Reference(),
+		Host: []types.ManagedObjectReference{host.Reference()},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	resp, err := methods.MoveInto_Task(ctx, client, req)
+	if err != nil {
+		return err
+	}
+	task := object.NewTask(client.Client, resp.Returnval)
+	tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer tcancel()
+	return task.Wait(tctx)
+}
+
+// removeHost removes a host from inventory. Hosts in a cluster are removed
+// directly, while standalone hosts are removed by destroying the
+// ComputeResource that holds them.
+func removeHost(client *govmomi.Client, host *object.HostSystem) error {
+	props, err := hostSystemProperties(host)
+	if err != nil {
+		return err
+	}
+	if props.Parent == nil {
+		return fmt.Errorf("host %q has no parent", props.Name)
+	}
+	var obj object.Common
+	switch props.Parent.Type {
+	case "ClusterComputeResource":
+		obj = host.Common
+	default:
+		obj = object.NewComputeResource(client.Client, *props.Parent).Common
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	task, err := obj.Destroy(ctx)
+	if err != nil {
+		return err
+	}
+	tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer tcancel()
+	return task.Wait(tctx)
+}
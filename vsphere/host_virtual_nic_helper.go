@@ -0,0 +1,106 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// hostVirtualNicFromDevice locates a VMkernel adapter on the supplied
+// HostNetworkSystem by its device name, such as vmk1. nil is returned if the
+// adapter does not exist.
+func hostVirtualNicFromDevice(client *govmomi.Client, ns *object.HostNetworkSystem, device string) (*types.HostVirtualNic, error) {
+	var mns mo.HostNetworkSystem
+	pc := client.PropertyCollector()
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	if err := pc.RetrieveOne(ctx, ns.Reference(), []string{"networkInfo.vnic"}, &mns); err != nil {
+		return nil, fmt.Errorf("error fetching host network properties: %s", err)
+	}
+
+	for _, nic := range mns.NetworkInfo.Vnic {
+		if nic.Device == device {
+			return &nic, nil
+		}
+	}
+	return nil, nil
+}
+
+// addHostVirtualNic adds a VMkernel adapter to the host that the supplied
+// HostNetworkSystem belongs to, and returns the device name of the new
+// adapter.
+func addHostVirtualNic(ns *object.HostNetworkSystem, portgroup string, spec types.HostVirtualNicSpec) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return ns.AddVirtualNic(ctx, portgroup, spec)
+}
+
+// updateHostVirtualNic updates a VMkernel adapter.
+func updateHostVirtualNic(ns *object.HostNetworkSystem, device string, spec types.HostVirtualNicSpec) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return ns.UpdateVirtualNic(ctx, device, spec)
+}
+
+// removeHostVirtualNic removes a VMkernel adapter.
+func removeHostVirtualNic(ns *object.HostNetworkSystem, device string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return ns.RemoveVirtualNic(ctx, device)
+}
+
+// hostVirtualNicManagerFromHostSystemID locates a HostVirtualNicManager from
+// a specified HostSystem managed object ID.
+func hostVirtualNicManagerFromHostSystemID(client *govmomi.Client, hsID string) (*object.HostVirtualNicManager, error) {
+	hs, err := hostSystemFromID(client, hsID)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return hs.ConfigManager().VirtualNicManager(ctx)
+}
+
+// hostVirtualNicServices returns the services, or NIC types, that are enabled
+// on the VMkernel adapter with the supplied device name.
+func hostVirtualNicServices(vnm *object.HostVirtualNicManager, device string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	info, err := vnm.Info(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var services []string
+	for _, nc := range info.NetConfig {
+		for _, candidate := range nc.CandidateVnic {
+			if candidate.Device != device {
+				continue
+			}
+			for _, key := range nc.SelectedVnic {
+				if key == candidate.Key {
+					services = append(services, nc.NicType)
+				}
+			}
+		}
+	}
+	return services, nil
+}
+
+// selectHostVirtualNicService enables a service on a VMkernel adapter.
+func selectHostVirtualNicService(vnm *object.HostVirtualNicManager, device, service string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return vnm.SelectVnic(ctx, service, device)
+}
+
+// deselectHostVirtualNicService disables a service on a VMkernel adapter.
+func deselectHostVirtualNicService(vnm *object.HostVirtualNicManager, device, service string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return vnm.DeselectVnic(ctx, service, device)
+}
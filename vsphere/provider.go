@@ -69,30 +69,55 @@ func Provider() terraform.ResourceProvider {
 		},
 
 		ResourcesMap: map[string]*schema.Resource{
-			"vsphere_datacenter":                 resourceVSphereDatacenter(),
-			"vsphere_distributed_port_group":     resourceVSphereDistributedPortGroup(),
-			"vsphere_distributed_virtual_switch": resourceVSphereDistributedVirtualSwitch(),
-			"vsphere_file":                       resourceVSphereFile(),
-			"vsphere_folder":                     resourceVSphereFolder(),
-			"vsphere_host_port_group":            resourceVSphereHostPortGroup(),
-			"vsphere_host_virtual_switch":        resourceVSphereHostVirtualSwitch(),
-			"vsphere_license":                    resourceVSphereLicense(),
-			"vsphere_tag":                        resourceVSphereTag(),
-			"vsphere_tag_category":               resourceVSphereTagCategory(),
-			"vsphere_virtual_disk":               resourceVSphereVirtualDisk(),
-			"vsphere_virtual_machine":            resourceVSphereVirtualMachine(),
-			"vsphere_nas_datastore":              resourceVSphereNasDatastore(),
-			"vsphere_vmfs_datastore":             resourceVSphereVmfsDatastore(),
-			"vsphere_virtual_machine_snapshot":   resourceVSphereVirtualMachineSnapshot(),
+			"vsphere_datacenter":                            resourceVSphereDatacenter(),
+			"vsphere_compute_cluster":                       resourceVSphereComputeCluster(),
+			"vsphere_compute_cluster_host_group":            resourceVSphereComputeClusterHostGroup(),
+			"vsphere_compute_cluster_vm_affinity_rule":      resourceVSphereComputeClusterVMAffinityRule(),
+			"vsphere_compute_cluster_vm_anti_affinity_rule": resourceVSphereComputeClusterVMAntiAffinityRule(),
+			"vsphere_compute_cluster_vm_group":              resourceVSphereComputeClusterVMGroup(),
+			"vsphere_compute_cluster_vm_host_rule":          resourceVSphereComputeClusterVMHostRule(),
+			"vsphere_datastore_cluster":                     resourceVSphereDatastoreCluster(),
+			"vsphere_distributed_port_group":                resourceVSphereDistributedPortGroup(),
+			"vsphere_distributed_virtual_switch":            resourceVSphereDistributedVirtualSwitch(),
+			"vsphere_drs_vm_override":                       resourceVSphereDrsVMOverride(),
+			"vsphere_file":                                  resourceVSphereFile(),
+			"vsphere_folder":                                resourceVSphereFolder(),
+			"vsphere_ha_vm_override":                        resourceVSphereHaVMOverride(),
+			"vsphere_host":                                  resourceVSphereHost(),
+			"vsphere_host_advanced_settings":                resourceVSphereHostAdvancedSettings(),
+			"vsphere_host_dns":                              resourceVSphereHostDNS(),
+			"vsphere_host_firewall_ruleset":                 resourceVSphereHostFirewallRuleset(),
+			"vsphere_host_ntp":                              resourceVSphereHostNTP(),
+			"vsphere_host_port_group":                       resourceVSphereHostPortGroup(),
+			"vsphere_host_service":                          resourceVSphereHostService(),
+			"vsphere_host_virtual_switch":                   resourceVSphereHostVirtualSwitch(),
+			"vsphere_license":                               resourceVSphereLicense(),
+			"vsphere_resource_pool":                         resourceVSphereResourcePool(),
+			"vsphere_storage_drs_vm_override":               resourceVSphereStorageDrsVMOverride(),
+			"vsphere_tag":                                   resourceVSphereTag(),
+			"vsphere_tag_category":                          resourceVSphereTagCategory(),
+			"vsphere_vapp_container":                        resourceVSphereVAppContainer(),
+			"vsphere_vapp_entity":                           resourceVSphereVAppEntity(),
+			"vsphere_virtual_disk":                          resourceVSphereVirtualDisk(),
+			"vsphere_virtual_machine":                       resourceVSphereVirtualMachine(),
+			"vsphere_vnic":                                  resourceVSphereVNIC(),
+			"vsphere_nas_datastore":                         resourceVSphereNasDatastore(),
+			"vsphere_vmfs_datastore":                        resourceVSphereVmfsDatastore(),
+			"vsphere_virtual_machine_snapshot":              resourceVSphereVirtualMachineSnapshot(),
 		},
 
 		DataSourcesMap: map[string]*schema.Resource{
 			"vsphere_datacenter":                 dataSourceVSphereDatacenter(),
+			"vsphere_datastore":                  dataSourceVSphereDatastore(),
+			"vsphere_datastore_files":            dataSourceVSphereDatastoreFiles(),
 			"vsphere_distributed_virtual_switch": dataSourceVSphereDistributedVirtualSwitch(),
 			"vsphere_host":                       dataSourceVSphereHost(),
 			"vsphere_network":                    dataSourceVSphereNetwork(),
+			"vsphere_storage_policy":             dataSourceVSphereStoragePolicy(),
 			"vsphere_tag":                        dataSourceVSphereTag(),
 			"vsphere_tag_category":               dataSourceVSphereTagCategory(),
+			"vsphere_virtual_machine":            dataSourceVSphereVirtualMachine(),
+			"vsphere_virtual_machine_snapshots":  dataSourceVSphereVirtualMachineSnapshots(),
 			"vsphere_vmfs_disks":                 dataSourceVSphereVmfsDisks(),
 		},
 
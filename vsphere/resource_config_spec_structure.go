@@ -0,0 +1,117 @@
+package vsphere
+
+import (
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// schemaResourceConfigSpec returns schema items for resources that need to
+// work with a ResourceConfigSpec, such as resource pools and vApps.
+func schemaResourceConfigSpec() map[string]*schema.Schema {
+	s := make(map[string]*schema.Schema)
+	mergeSchema(s, schemaResourceAllocationInfo("cpu", "CPU", "MHz"))
+	mergeSchema(s, schemaResourceAllocationInfo("memory", "memory", "MB"))
+	return s
+}
+
+// schemaResourceAllocationInfo returns the schema items for a
+// ResourceAllocationInfo, with keys prefixed by the supplied resource type.
+func schemaResourceAllocationInfo(prefix, name, unit string) map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		prefix + "_share_level": {
+			Type:         schema.TypeString,
+			Description:  "The " + name + " allocation level. The level is a simplified view of shares. Levels map to a pre-determined set of numeric values for shares. Can be one of low, normal, high, or custom.",
+			Optional:     true,
+			Default:      string(types.SharesLevelNormal),
+			ValidateFunc: validation.StringInSlice(sharesLevelAllowedValues, false),
+		},
+		prefix + "_shares": {
+			Type:         schema.TypeInt,
+			Description:  "The number of " + name + " shares allocated. Ignored unless " + prefix + "_share_level is custom.",
+			Optional:     true,
+			Computed:     true,
+			ValidateFunc: validation.IntAtLeast(0),
+		},
+		prefix + "_reservation": {
+			Type:         schema.TypeInt,
+			Description:  "Amount of " + name + " (" + unit + ") that is guaranteed available.",
+			Optional:     true,
+			Default:      0,
+			ValidateFunc: validation.IntAtLeast(0),
+		},
+		prefix + "_expandable": {
+			Type:        schema.TypeBool,
+			Description: "Determines if the reservation can grow beyond the specified value, if the parent resource pool has unreserved resources.",
+			Optional:    true,
+			Default:     true,
+		},
+		prefix + "_limit": {
+			Type:         schema.TypeInt,
+			Description:  "The utilization of " + name + " will not exceed this limit, even if there are available resources. Set to -1 for unlimited.",
+			Optional:     true,
+			Default:      -1,
+			ValidateFunc: validation.IntAtLeast(-1),
+		},
+	}
+}
+
+// expandResourceConfigSpec reads certain ResourceData keys and returns a
+// ResourceConfigSpec.
+func expandResourceConfigSpec(d *schema.ResourceData) *types.ResourceConfigSpec {
+	obj := &types.ResourceConfigSpec{
+		CpuAllocation:    expandResourceAllocationInfo(d, "cpu"),
+		MemoryAllocation: expandResourceAllocationInfo(d, "memory"),
+	}
+	return obj
+}
+
+// flattenResourceConfigSpec reads various fields from a ResourceConfigSpec
+// into the passed in ResourceData.
+func flattenResourceConfigSpec(d *schema.ResourceData, obj types.ResourceConfigSpec) error {
+	if obj.CpuAllocation != nil {
+		if err := flattenResourceAllocationInfo(d, "cpu", obj.CpuAllocation.GetResourceAllocationInfo()); err != nil {
+			return err
+		}
+	}
+	if obj.MemoryAllocation != nil {
+		if err := flattenResourceAllocationInfo(d, "memory", obj.MemoryAllocation.GetResourceAllocationInfo()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// expandResourceAllocationInfo reads the ResourceData keys with the supplied
+// prefix and returns a ResourceAllocationInfo.
+func expandResourceAllocationInfo(d *schema.ResourceData, prefix string) *types.ResourceAllocationInfo {
+	obj := &types.ResourceAllocationInfo{
+		Reservation:           int64Ptr(int64(d.Get(prefix + "_reservation").(int))),
+		ExpandableReservation: boolPtr(d.Get(prefix + "_expandable").(bool)),
+		Limit:                 int64Ptr(int64(d.Get(prefix + "_limit").(int))),
+		Shares: &types.SharesInfo{
+			Level:  types.SharesLevel(d.Get(prefix + "_share_level").(string)),
+			Shares: int32(d.Get(prefix + "_shares").(int)),
+		},
+	}
+	return obj
+}
+
+// flattenResourceAllocationInfo reads various fields from a
+// ResourceAllocationInfo into the ResourceData keys with the supplied prefix.
+func flattenResourceAllocationInfo(d *schema.ResourceData, prefix string, obj *types.ResourceAllocationInfo) error {
+	if err := setInt64Ptr(d, prefix+"_reservation", obj.Reservation); err != nil {
+		return err
+	}
+	if err := setBoolPtr(d, prefix+"_expandable", obj.ExpandableReservation); err != nil {
+		return err
+	}
+	if err := setInt64Ptr(d, prefix+"_limit", obj.Limit); err != nil {
+		return err
+	}
+	if obj.Shares != nil {
+		d.Set(prefix+"_share_level", obj.Shares.Level)
+		d.Set(prefix+"_shares", obj.Shares.Shares)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package vsphere
+
+import (
+	"context"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// resourcePoolFromID locates a ResourcePool by its managed object reference
+// ID.
+func resourcePoolFromID(client *govmomi.Client, id string) (*object.ResourcePool, error) {
+	finder := find.NewFinder(client.Client, false)
+
+	ref := types.ManagedObjectReference{
+		Type:  "ResourcePool",
+		Value: id,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	pool, err := finder.ObjectReference(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	return pool.(*object.ResourcePool), nil
+}
+
+// resourcePoolFromPath loads a ResourcePool from its path.
+//
+// Datacenter is optional here - if not provided, it's expected that the path
+// is sufficient enough for finder to determine the datacenter required.
+func resourcePoolFromPath(client *govmomi.Client, name string, dc *object.Datacenter) (*object.ResourcePool, error) {
+	finder := find.NewFinder(client.Client, false)
+	if dc != nil {
+		finder.SetDatacenter(dc)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return finder.ResourcePool(ctx, name)
+}
+
+// resourcePoolProperties is a convenience method that wraps fetching the
+// ResourcePool MO from its higher-level object.
+func resourcePoolProperties(pool *object.ResourcePool) (*mo.ResourcePool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	var props mo.ResourcePool
+	if err := pool.Properties(ctx, pool.Reference(), nil, &props); err != nil {
+		return nil, err
+	}
+	return &props, nil
+}
+
+// resourcePoolVirtualMachines returns the virtual machines in a ResourcePool,
+// including those in any child resource pools and vApps.
+func resourcePoolVirtualMachines(pool *object.ResourcePool) ([]types.ManagedObjectReference, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	var props mo.ResourcePool
+	err := pool.Properties(ctx, pool.Reference(), []string{"vm", "resourcePool"}, &props)
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+	vms := props.Vm
+	// Child vApps are returned here too, and can be treated as resource pools.
+	for _, ref := range props.ResourcePool {
+		child, err := resourcePoolVirtualMachines(object.NewResourcePool(pool.Client(), ref))
+		if err != nil {
+			return nil, err
+		}
+		vms = append(vms, child...)
+	}
+	return vms, nil
+}
+
+// createResourcePool creates a ResourcePool as a child of the supplied parent
+// ResourcePool.
+func createResourcePool(parent *object.ResourcePool, name string, spec types.ResourceConfigSpec) (*object.ResourcePool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return parent.Create(ctx, name, spec)
+}
+
+// updateResourcePool updates the name and resource allocation of a
+// ResourcePool. An empty name leaves the name unchanged.
+func updateResourcePool(pool *object.ResourcePool, name string, spec *types.ResourceConfigSpec) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return pool.UpdateConfig(ctx, name, spec)
+}
+
+// moveIntoResourcePool moves the objects in refs, which can be resource
+// pools, vApps, or virtual machines, into the supplied ResourcePool. This
+// local implementation may go away if this is exposed in the higher-level
+// object upstream.
+func moveIntoResourcePool(client *govmomi.Client, pool *object.ResourcePool, refs []types.ManagedObjectReference) error {
+	req := &types.MoveIntoResourcePool{
+		This: pool.Reference(),
+		List: refs,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	_, err := methods.MoveIntoResourcePool(ctx, client, req)
+	return err
+}
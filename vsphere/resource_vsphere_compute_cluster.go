@@ -0,0 +1,282 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+)
+
+func resourceVSphereComputeCluster() *schema.Resource {
+	s := map[string]*schema.Schema{
+		"name": {
+			Type:         schema.TypeString,
+			Description:  "The name of the cluster.",
+			Required:     true,
+			ValidateFunc: validation.NoZeroValues,
+		},
+		"datacenter_id": {
+			Type:        schema.TypeString,
+			Description: "The managed object ID of the datacenter to create the cluster in.",
+			Required:    true,
+			ForceNew:    true,
+		},
+		"folder": {
+			Type:        schema.TypeString,
+			Description: "The path to the host folder to put the cluster in.",
+			Optional:    true,
+			StateFunc:   normalizeFolderPath,
+		},
+		"evc_mode": {
+			Type:        schema.TypeString,
+			Description: "The Enhanced vMotion Compatibility (EVC) mode key to apply to the cluster. When empty, EVC is disabled.",
+			Optional:    true,
+		},
+		"resource_pool_id": {
+			Type:        schema.TypeString,
+			Description: "The managed object ID of the cluster's root resource pool.",
+			Computed:    true,
+		},
+		// Tagging
+		vSphereTagAttributeKey: tagsSchema(),
+	}
+	mergeSchema(s, schemaClusterConfigSpecEx())
+
+	return &schema.Resource{
+		Create: resourceVSphereComputeClusterCreate,
+		Read:   resourceVSphereComputeClusterRead,
+		Update: resourceVSphereComputeClusterUpdate,
+		Delete: resourceVSphereComputeClusterDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceVSphereComputeClusterImport,
+		},
+		Schema: s,
+	}
+}
+
+func resourceVSphereComputeClusterCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	if err := validateClusterConfigSpecEx(d); err != nil {
+		return err
+	}
+	tagsClient, err := tagsClientIfDefined(d, meta)
+	if err != nil {
+		return err
+	}
+
+	dc, err := datacenterFromID(client, d.Get("datacenter_id").(string))
+	if err != nil {
+		return fmt.Errorf("cannot locate datacenter: %s", err)
+	}
+	folder, err := folderFromPath(client, d.Get("folder").(string), vSphereFolderTypeHost, dc)
+	if err != nil {
+		return fmt.Errorf("cannot locate folder: %s", err)
+	}
+
+	cluster, err := createClusterComputeResource(folder, d.Get("name").(string), *expandClusterConfigSpecEx(d))
+	if err != nil {
+		return fmt.Errorf("error creating cluster: %s", err)
+	}
+	d.SetId(cluster.Reference().Value)
+
+	if mode := d.Get("evc_mode").(string); mode != "" {
+		if err := updateClusterEVCMode(client, cluster, mode); err != nil {
+			return fmt.Errorf("error configuring EVC mode: %s", err)
+		}
+	}
+
+	// Apply any pending tags now
+	if tagsClient != nil {
+		if err := processTagDiff(tagsClient, d, cluster); err != nil {
+			return fmt.Errorf("error updating tags: %s", err)
+		}
+	}
+
+	return resourceVSphereComputeClusterRead(d, meta)
+}
+
+func resourceVSphereComputeClusterRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	cluster, err := clusterComputeResourceFromID(client, d.Id())
+	if err != nil {
+		return fmt.Errorf("cannot locate cluster: %s", err)
+	}
+	props, err := clusterComputeResourceProperties(cluster)
+	if err != nil {
+		return fmt.Errorf("error fetching cluster properties: %s", err)
+	}
+	d.Set("name", props.Name)
+
+	// Set the datacenter ID, for completion's sake when importing
+	dcp, err := rootPathParticleHost.SplitDatacenter(cluster.InventoryPath)
+	if err != nil {
+		return fmt.Errorf("error parsing datacenter from inventory path: %s", err)
+	}
+	dc, err := getDatacenter(client, dcp)
+	if err != nil {
+		return fmt.Errorf("error locating datacenter: %s", err)
+	}
+	d.Set("datacenter_id", dc.Reference().Value)
+
+	// Set the folder
+	folder, err := rootPathParticleHost.SplitRelativeFolder(cluster.InventoryPath)
+	if err != nil {
+		return fmt.Errorf("error parsing cluster path %q: %s", cluster.InventoryPath, err)
+	}
+	d.Set("folder", normalizeFolderPath(folder))
+
+	if props.ResourcePool != nil {
+		d.Set("resource_pool_id", props.ResourcePool.Value)
+	}
+
+	// Read in the DRS, HA, and proactive HA config
+	info, err := clusterConfigInfoEx(cluster)
+	if err != nil {
+		return fmt.Errorf("error fetching cluster configuration: %s", err)
+	}
+	if err := flattenClusterConfigInfoEx(d, info); err != nil {
+		return err
+	}
+
+	// Read in the EVC mode
+	mode, err := clusterEVCMode(client, cluster)
+	if err != nil {
+		return fmt.Errorf("error fetching EVC mode: %s", err)
+	}
+	d.Set("evc_mode", mode)
+
+	// Read tags if we have the ability to do so
+	if tagsClient, _ := meta.(*VSphereClient).TagsClient(); tagsClient != nil {
+		if err := readTagsForResource(tagsClient, cluster, d); err != nil {
+			return fmt.Errorf("error reading tags: %s", err)
+		}
+	}
+
+	return nil
+}
+
+func resourceVSphereComputeClusterUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	if err := validateClusterConfigSpecEx(d); err != nil {
+		return err
+	}
+	tagsClient, err := tagsClientIfDefined(d, meta)
+	if err != nil {
+		return err
+	}
+	cluster, err := clusterComputeResourceFromID(client, d.Id())
+	if err != nil {
+		return fmt.Errorf("cannot locate cluster: %s", err)
+	}
+
+	// Rename this cluster if our name has drifted.
+	if d.HasChange("name") {
+		if err := renameObject(client, cluster.Reference(), d.Get("name").(string)); err != nil {
+			return fmt.Errorf("could not rename cluster: %s", err)
+		}
+	}
+
+	// Update folder if necessary
+	if d.HasChange("folder") {
+		dc, err := datacenterFromID(client, d.Get("datacenter_id").(string))
+		if err != nil {
+			return fmt.Errorf("cannot locate datacenter: %s", err)
+		}
+		folder, err := folderFromPath(client, d.Get("folder").(string), vSphereFolderTypeHost, dc)
+		if err != nil {
+			return fmt.Errorf("cannot locate folder: %s", err)
+		}
+		if err := moveObjectToFolder(cluster.Reference(), folder); err != nil {
+			return fmt.Errorf("could not move cluster to folder %q: %s", d.Get("folder").(string), err)
+		}
+	}
+
+	// Update the DRS, HA, and proactive HA config if any of it has changed.
+	var configChanged bool
+	for k := range schemaClusterConfigSpecEx() {
+		if d.HasChange(k) {
+			configChanged = true
+			break
+		}
+	}
+	if configChanged {
+		if err := reconfigureClusterComputeResource(cluster, expandClusterConfigSpecEx(d)); err != nil {
+			return fmt.Errorf("error reconfiguring cluster: %s", err)
+		}
+	}
+
+	if d.HasChange("evc_mode") {
+		if err := updateClusterEVCMode(client, cluster, d.Get("evc_mode").(string)); err != nil {
+			return fmt.Errorf("error configuring EVC mode: %s", err)
+		}
+	}
+
+	// Apply any pending tags now
+	if tagsClient != nil {
+		if err := processTagDiff(tagsClient, d, cluster); err != nil {
+			return fmt.Errorf("error updating tags: %s", err)
+		}
+	}
+
+	return resourceVSphereComputeClusterRead(d, meta)
+}
+
+func resourceVSphereComputeClusterDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	cluster, err := clusterComputeResourceFromID(client, d.Id())
+	if err != nil {
+		return fmt.Errorf("cannot locate cluster: %s", err)
+	}
+
+	// Destroying a cluster removes any hosts in it from inventory, so we refuse
+	// to do so if there are any hosts left in it.
+	props, err := clusterComputeResourceProperties(cluster)
+	if err != nil {
+		return fmt.Errorf("error fetching cluster properties: %s", err)
+	}
+	if len(props.Host) > 0 {
+		return fmt.Errorf("cluster %q still contains %d host(s), please remove them before deleting", props.Name, len(props.Host))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	task, err := cluster.Destroy(ctx)
+	if err != nil {
+		return fmt.Errorf("error deleting cluster: %s", err)
+	}
+	tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer tcancel()
+	if err := task.Wait(tctx); err != nil {
+		return fmt.Errorf("error waiting for cluster deletion to complete: %s", err)
+	}
+
+	return nil
+}
+
+func resourceVSphereComputeClusterImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	// We import clusters by inventory path. A full path is required unless the
+	// default datacenter can be utilized.
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return nil, err
+	}
+	cluster, err := clusterComputeResourceFromPath(client, d.Id(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error locating cluster: %s", err)
+	}
+	d.SetId(cluster.Reference().Value)
+	return []*schema.ResourceData{d}, nil
+}
@@ -0,0 +1,211 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func resourceVSphereComputeClusterHostGroup() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereComputeClusterHostGroupCreate,
+		Read:   resourceVSphereComputeClusterHostGroupRead,
+		Update: resourceVSphereComputeClusterHostGroupUpdate,
+		Delete: resourceVSphereComputeClusterHostGroupDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceVSphereComputeClusterHostGroupImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"compute_cluster_id": {
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the cluster.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"name": {
+				Type:         schema.TypeString,
+				Description:  "The name of the host group.",
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.NoZeroValues,
+			},
+			"host_system_ids": {
+				Type:        schema.TypeSet,
+				Description: "The managed object IDs of the hosts in this group.",
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func resourceVSphereComputeClusterHostGroupCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	cluster, err := clusterComputeResourceFromID(client, d.Get("compute_cluster_id").(string))
+	if err != nil {
+		return fmt.Errorf("cannot locate cluster: %s", err)
+	}
+
+	// Refuse to take over a group that already exists, as it may be managed
+	// outside of this resource.
+	name := d.Get("name").(string)
+	existing, err := clusterGroup(cluster, name)
+	if err != nil {
+		return fmt.Errorf("error fetching cluster groups: %s", err)
+	}
+	if existing != nil {
+		return fmt.Errorf("group %q already exists in cluster %q", name, cluster.Reference().Value)
+	}
+
+	info, err := expandClusterHostGroup(d, client)
+	if err != nil {
+		return err
+	}
+	if err := updateClusterGroup(cluster, types.ArrayUpdateOperationAdd, info); err != nil {
+		return fmt.Errorf("error creating host group: %s", err)
+	}
+
+	d.SetId(fmt.Sprintf("%s:%s", cluster.Reference().Value, name))
+	return resourceVSphereComputeClusterHostGroupRead(d, meta)
+}
+
+func resourceVSphereComputeClusterHostGroupRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	cluster, err := clusterComputeResourceFromID(client, d.Get("compute_cluster_id").(string))
+	if err != nil {
+		return fmt.Errorf("cannot locate cluster: %s", err)
+	}
+
+	info, err := resourceVSphereComputeClusterHostGroupFind(cluster, d.Get("name").(string))
+	if err != nil {
+		return err
+	}
+	if info == nil {
+		log.Printf("[DEBUG] Virtual machine group %q not found in cluster %q, marking as gone", d.Get("name").(string), cluster.Reference().Value)
+		d.SetId("")
+		return nil
+	}
+
+	return flattenClusterHostGroup(d, info)
+}
+
+func resourceVSphereComputeClusterHostGroupUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	cluster, err := clusterComputeResourceFromID(client, d.Get("compute_cluster_id").(string))
+	if err != nil {
+		return fmt.Errorf("cannot locate cluster: %s", err)
+	}
+
+	info, err := expandClusterHostGroup(d, client)
+	if err != nil {
+		return err
+	}
+	if err := updateClusterGroup(cluster, types.ArrayUpdateOperationEdit, info); err != nil {
+		return fmt.Errorf("error updating host group: %s", err)
+	}
+
+	return resourceVSphereComputeClusterHostGroupRead(d, meta)
+}
+
+func resourceVSphereComputeClusterHostGroupDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	cluster, err := clusterComputeResourceFromID(client, d.Get("compute_cluster_id").(string))
+	if err != nil {
+		return fmt.Errorf("cannot locate cluster: %s", err)
+	}
+
+	existing, err := resourceVSphereComputeClusterHostGroupFind(cluster, d.Get("name").(string))
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		// Already gone
+		return nil
+	}
+	if err := removeClusterGroup(cluster, d.Get("name").(string)); err != nil {
+		return fmt.Errorf("error removing host group: %s", err)
+	}
+	return nil
+}
+
+func resourceVSphereComputeClusterHostGroupImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	// We import with the same ID that the resource is saved with, which is the
+	// cluster ID and the group name, separated by a colon.
+	clusterID, name, err := splitComputeClusterObjectID(d.Id())
+	if err != nil {
+		return nil, err
+	}
+	d.Set("compute_cluster_id", clusterID)
+	d.Set("name", name)
+	return []*schema.ResourceData{d}, nil
+}
+
+// resourceVSphereComputeClusterHostGroupFind locates the host group with the
+// supplied name in the cluster. nil is returned if the group does not exist,
+// and an error is returned if a group with the name exists but is not a host
+// group.
+func resourceVSphereComputeClusterHostGroupFind(cluster *object.ClusterComputeResource, name string) (*types.ClusterHostGroup, error) {
+	group, err := clusterGroup(cluster, name)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching cluster groups: %s", err)
+	}
+	if group == nil {
+		return nil, nil
+	}
+	info, ok := group.(*types.ClusterHostGroup)
+	if !ok {
+		return nil, fmt.Errorf("group %q in cluster %q is a %T, not a host group", name, cluster.Reference().Value, group)
+	}
+	return info, nil
+}
+
+// expandClusterHostGroup reads certain ResourceData keys and returns a
+// ClusterHostGroup.
+func expandClusterHostGroup(d *schema.ResourceData, client *govmomi.Client) (*types.ClusterHostGroup, error) {
+	var refs []types.ManagedObjectReference
+	for _, id := range sliceInterfacesToStrings(d.Get("host_system_ids").(*schema.Set).List()) {
+		host, err := hostSystemFromID(client, id)
+		if err != nil {
+			return nil, err
+		}
+		refs = append(refs, host.Reference())
+	}
+	obj := &types.ClusterHostGroup{
+		ClusterGroupInfo: types.ClusterGroupInfo{
+			Name: d.Get("name").(string),
+		},
+		Host: refs,
+	}
+	return obj, nil
+}
+
+// flattenClusterHostGroup reads various fields from a ClusterHostGroup into
+// the passed in ResourceData.
+func flattenClusterHostGroup(d *schema.ResourceData, obj *types.ClusterHostGroup) error {
+	var ids []string
+	for _, ref := range obj.Host {
+		ids = append(ids, ref.Value)
+	}
+	if err := d.Set("host_system_ids", ids); err != nil {
+		return fmt.Errorf("error setting host_system_ids: %s", err)
+	}
+	return nil
+}
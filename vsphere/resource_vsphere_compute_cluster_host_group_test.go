@@ -0,0 +1,177 @@
+package vsphere
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestAccResourceVSphereComputeClusterHostGroup(t *testing.T) {
+	var tp *testing.T
+	testAccResourceVSphereComputeClusterHostGroupCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"basic",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccComputeClusterMembersPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereComputeClusterHostGroupExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereComputeClusterHostGroupConfig(true),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereComputeClusterHostGroupExists(true),
+							testAccResourceVSphereComputeClusterHostGroupMatchMembership(true),
+						),
+					},
+				},
+			},
+		},
+		{
+			"update membership",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccComputeClusterMembersPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereComputeClusterHostGroupExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereComputeClusterHostGroupConfig(false),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereComputeClusterHostGroupExists(true),
+							testAccResourceVSphereComputeClusterHostGroupMatchMembership(false),
+						),
+					},
+					{
+						Config: testAccResourceVSphereComputeClusterHostGroupConfig(true),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereComputeClusterHostGroupExists(true),
+							testAccResourceVSphereComputeClusterHostGroupMatchMembership(true),
+						),
+					},
+				},
+			},
+		},
+		{
+			"import",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccComputeClusterMembersPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereComputeClusterHostGroupExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereComputeClusterHostGroupConfig(true),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereComputeClusterHostGroupExists(true),
+						),
+					},
+					{
+						ResourceName:      "vsphere_compute_cluster_host_group.cluster_host_group",
+						ImportState:       true,
+						ImportStateVerify: true,
+						Config:            testAccResourceVSphereComputeClusterHostGroupConfig(true),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccResourceVSphereComputeClusterHostGroupCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+// testAccResourceVSphereComputeClusterHostGroupGet fetches the host group in
+// the test cluster. nil is returned if the group does not exist.
+func testAccResourceVSphereComputeClusterHostGroupGet(s *terraform.State) (*types.ClusterHostGroup, error) {
+	cluster, err := testGetComputeCluster(s, "compute_cluster")
+	if err != nil {
+		return nil, err
+	}
+	return resourceVSphereComputeClusterHostGroupFind(cluster, "terraform-test-cluster-host-group")
+}
+
+func testAccResourceVSphereComputeClusterHostGroupExists(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		info, err := testAccResourceVSphereComputeClusterHostGroupGet(s)
+		if err != nil {
+			if isAnyNotFoundError(err) && expected == false {
+				// Expected missing
+				return nil
+			}
+			return err
+		}
+		exists := info != nil
+		if exists != expected {
+			return fmt.Errorf("expected host group to exist: %t, got %t", expected, exists)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereComputeClusterHostGroupMatchMembership checks that
+// the host group contains the test host, or nothing if withHost is false.
+func testAccResourceVSphereComputeClusterHostGroupMatchMembership(withHost bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		info, err := testAccResourceVSphereComputeClusterHostGroupGet(s)
+		if err != nil {
+			return err
+		}
+		if info == nil {
+			return errors.New("host group not found")
+		}
+		var expected []string
+		if withHost {
+			host, err := testGetHost(s, "host")
+			if err != nil {
+				return err
+			}
+			expected = append(expected, host.Reference().Value)
+		}
+		var actual []string
+		for _, ref := range info.Host {
+			actual = append(actual, ref.Value)
+		}
+		if !reflect.DeepEqual(expected, actual) {
+			return fmt.Errorf("expected host group members to be %v, got %v", expected, actual)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereComputeClusterHostGroupConfig(withHost bool) string {
+	var ids string
+	if withHost {
+		ids = `"${vsphere_host.host.id}"`
+	}
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_compute_cluster_host_group" "cluster_host_group" {
+  name               = "terraform-test-cluster-host-group"
+  compute_cluster_id = "${vsphere_compute_cluster.compute_cluster.id}"
+  host_system_ids    = [%s]
+}
+`,
+		testAccComputeClusterMembersConfig(),
+		ids,
+	)
+}
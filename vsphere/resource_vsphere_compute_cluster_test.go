@@ -0,0 +1,369 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestAccResourceVSphereComputeCluster(t *testing.T) {
+	var tp *testing.T
+	testAccResourceVSphereComputeClusterCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"basic",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereComputeClusterExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereComputeClusterConfigBasic(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereComputeClusterExists(true),
+							testAccResourceVSphereComputeClusterMatchConfig(func(info *types.ClusterConfigInfoEx) error {
+								if info.DrsConfig.Enabled != nil && *info.DrsConfig.Enabled {
+									return fmt.Errorf("expected DRS to be disabled")
+								}
+								if info.DasConfig.Enabled != nil && *info.DasConfig.Enabled {
+									return fmt.Errorf("expected HA to be disabled")
+								}
+								return nil
+							}),
+						),
+					},
+				},
+			},
+		},
+		{
+			"DRS and HA settings",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereComputeClusterExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereComputeClusterConfigBasic(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereComputeClusterExists(true),
+						),
+					},
+					{
+						Config: testAccResourceVSphereComputeClusterConfigDrsHA(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereComputeClusterExists(true),
+							testAccResourceVSphereComputeClusterMatchConfig(func(info *types.ClusterConfigInfoEx) error {
+								if info.DrsConfig.Enabled == nil || !*info.DrsConfig.Enabled {
+									return fmt.Errorf("expected DRS to be enabled")
+								}
+								if info.DrsConfig.DefaultVmBehavior != types.DrsBehaviorFullyAutomated {
+									return fmt.Errorf("expected DRS automation level to be fullyAutomated, got %q", info.DrsConfig.DefaultVmBehavior)
+								}
+								if info.DrsConfig.VmotionRate != 2 {
+									return fmt.Errorf("expected vmotionRate to be 2, got %d", info.DrsConfig.VmotionRate)
+								}
+								if info.DasConfig.Enabled == nil || !*info.DasConfig.Enabled {
+									return fmt.Errorf("expected HA to be enabled")
+								}
+								if _, ok := info.DasConfig.AdmissionControlPolicy.(*types.ClusterFailoverLevelAdmissionControlPolicy); !ok {
+									return fmt.Errorf("expected slot admission control policy, got %T", info.DasConfig.AdmissionControlPolicy)
+								}
+								if info.DasConfig.DefaultVmSettings == nil || info.DasConfig.DefaultVmSettings.IsolationResponse != string(types.ClusterDasVmSettingsIsolationResponsePowerOff) {
+									return fmt.Errorf("expected host isolation response to be powerOff")
+								}
+								return nil
+							}),
+							resource.TestCheckResourceAttr("vsphere_compute_cluster.compute_cluster", "drs_migration_threshold", "4"),
+							resource.TestCheckResourceAttr("vsphere_compute_cluster.compute_cluster", "ha_vm_restart_priority", "high"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"rename and move to folder",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereComputeClusterExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereComputeClusterConfigBasic(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereComputeClusterExists(true),
+						),
+					},
+					{
+						Config: testAccResourceVSphereComputeClusterConfigRenamedInFolder(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereComputeClusterExists(true),
+							testAccResourceVSphereComputeClusterHasName("terraform-compute-cluster-test-renamed"),
+							testAccResourceVSphereComputeClusterMatchInventoryPath("terraform-test-compute-cluster-folder"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"tags",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereComputeClusterExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereComputeClusterConfigTags(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereComputeClusterExists(true),
+							testAccResourceVSphereComputeClusterCheckTags("terraform-test-tag"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"import",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereComputeClusterExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereComputeClusterConfigDrsHA(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereComputeClusterExists(true),
+						),
+					},
+					{
+						ResourceName:      "vsphere_compute_cluster.compute_cluster",
+						ImportState:       true,
+						ImportStateVerify: true,
+						ImportStateIdFunc: func(s *terraform.State) (string, error) {
+							cluster, err := testGetComputeCluster(s, "compute_cluster")
+							if err != nil {
+								return "", err
+							}
+							return cluster.InventoryPath, nil
+						},
+						Config: testAccResourceVSphereComputeClusterConfigDrsHA(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereComputeClusterExists(true),
+						),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccResourceVSphereComputeClusterCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+func testAccResourceVSphereComputeClusterExists(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		cluster, err := testGetComputeCluster(s, "compute_cluster")
+		if err != nil {
+			if isAnyNotFoundError(err) && expected == false {
+				// Expected missing
+				return nil
+			}
+			return err
+		}
+		if !expected {
+			return fmt.Errorf("expected cluster %s to be missing", cluster.Reference().Value)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereComputeClusterHasName(expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetComputeClusterProperties(s, "compute_cluster")
+		if err != nil {
+			return err
+		}
+		actual := props.Name
+		if expected != actual {
+			return fmt.Errorf("expected cluster name to be %s, got %s", expected, actual)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereComputeClusterMatchInventoryPath(expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		cluster, err := testGetComputeCluster(s, "compute_cluster")
+		if err != nil {
+			return err
+		}
+
+		expected, err := rootPathParticleHost.PathFromNewRoot(cluster.InventoryPath, rootPathParticleHost, expected)
+		actual := path.Dir(cluster.InventoryPath)
+		if err != nil {
+			return fmt.Errorf("bad: %s", err)
+		}
+		if expected != actual {
+			return fmt.Errorf("expected path to be %s, got %s", expected, actual)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereComputeClusterMatchConfig(f func(*types.ClusterConfigInfoEx) error) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		cluster, err := testGetComputeCluster(s, "compute_cluster")
+		if err != nil {
+			return err
+		}
+		info, err := clusterConfigInfoEx(cluster)
+		if err != nil {
+			return err
+		}
+		return f(info)
+	}
+}
+
+// testAccResourceVSphereComputeClusterCheckTags is a check to ensure that any
+// tags that have been created with the supplied resource name have been
+// attached to the cluster.
+func testAccResourceVSphereComputeClusterCheckTags(tagResName string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		cluster, err := testGetComputeCluster(s, "compute_cluster")
+		if err != nil {
+			return err
+		}
+		tagsClient, err := testAccProvider.Meta().(*VSphereClient).TagsClient()
+		if err != nil {
+			return err
+		}
+		return testObjectHasTags(s, tagsClient, cluster, tagResName)
+	}
+}
+
+func testAccResourceVSphereComputeClusterConfigBasic() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+resource "vsphere_compute_cluster" "compute_cluster" {
+  name          = "terraform-compute-cluster-test"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+	)
+}
+
+func testAccResourceVSphereComputeClusterConfigDrsHA() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+resource "vsphere_compute_cluster" "compute_cluster" {
+  name          = "terraform-compute-cluster-test"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+
+  drs_enabled             = true
+  drs_automation_level    = "fullyAutomated"
+  drs_migration_threshold = 4
+
+  ha_enabled                  = true
+  ha_admission_control_policy = "slotPolicy"
+  ha_vm_restart_priority      = "high"
+  ha_host_isolation_response  = "powerOff"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+	)
+}
+
+func testAccResourceVSphereComputeClusterConfigRenamedInFolder() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+resource "vsphere_folder" "folder" {
+  path          = "terraform-test-compute-cluster-folder"
+  type          = "host"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_compute_cluster" "compute_cluster" {
+  name          = "terraform-compute-cluster-test-renamed"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+  folder        = "${vsphere_folder.folder.path}"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+	)
+}
+
+func testAccResourceVSphereComputeClusterConfigTags() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+resource "vsphere_tag_category" "terraform-test-category" {
+  name        = "terraform-test-tag-category"
+  cardinality = "MULTIPLE"
+
+  associable_types = [
+    "ClusterComputeResource",
+  ]
+}
+
+resource "vsphere_tag" "terraform-test-tag" {
+  name        = "terraform-test-tag"
+  category_id = "${vsphere_tag_category.terraform-test-category.id}"
+}
+
+resource "vsphere_compute_cluster" "compute_cluster" {
+  name          = "terraform-compute-cluster-test"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+  tags          = ["${vsphere_tag.terraform-test-tag.id}"]
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+	)
+}
@@ -0,0 +1,219 @@
+package vsphere
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func resourceVSphereComputeClusterVMAffinityRule() *schema.Resource {
+	s := map[string]*schema.Schema{
+		"compute_cluster_id": {
+			Type:        schema.TypeString,
+			Description: "The managed object ID of the cluster.",
+			Required:    true,
+			ForceNew:    true,
+		},
+		"name": {
+			Type:         schema.TypeString,
+			Description:  "The name of the rule.",
+			Required:     true,
+			ForceNew:     true,
+			ValidateFunc: validation.NoZeroValues,
+		},
+		"virtual_machine_uuids": {
+			Type:        schema.TypeSet,
+			Description: "The UUIDs of the virtual machines to keep on the same host. At least two are required.",
+			Required:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+		},
+	}
+	mergeSchema(s, schemaClusterRuleInfo())
+
+	return &schema.Resource{
+		Create: resourceVSphereComputeClusterVMAffinityRuleCreate,
+		Read:   resourceVSphereComputeClusterVMAffinityRuleRead,
+		Update: resourceVSphereComputeClusterVMAffinityRuleUpdate,
+		Delete: resourceVSphereComputeClusterVMAffinityRuleDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceVSphereComputeClusterVMAffinityRuleImport,
+		},
+		Schema: s,
+	}
+}
+
+func resourceVSphereComputeClusterVMAffinityRuleCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	cluster, err := clusterComputeResourceFromID(client, d.Get("compute_cluster_id").(string))
+	if err != nil {
+		return fmt.Errorf("cannot locate cluster: %s", err)
+	}
+
+	// Refuse to take over a rule that already exists, as it may be managed
+	// outside of this resource.
+	name := d.Get("name").(string)
+	existing, err := clusterRule(cluster, name)
+	if err != nil {
+		return fmt.Errorf("error fetching cluster rules: %s", err)
+	}
+	if existing != nil {
+		return fmt.Errorf("rule %q already exists in cluster %q", name, cluster.Reference().Value)
+	}
+
+	info, err := expandClusterVMAffinityRuleInfo(d, client, 0)
+	if err != nil {
+		return err
+	}
+	if err := updateClusterRule(cluster, types.ArrayUpdateOperationAdd, info); err != nil {
+		return fmt.Errorf("error creating VM affinity rule: %s", err)
+	}
+
+	d.SetId(fmt.Sprintf("%s:%s", cluster.Reference().Value, name))
+	return resourceVSphereComputeClusterVMAffinityRuleRead(d, meta)
+}
+
+func resourceVSphereComputeClusterVMAffinityRuleRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	cluster, err := clusterComputeResourceFromID(client, d.Get("compute_cluster_id").(string))
+	if err != nil {
+		return fmt.Errorf("cannot locate cluster: %s", err)
+	}
+
+	info, err := resourceVSphereComputeClusterVMAffinityRuleFind(cluster, d.Get("name").(string))
+	if err != nil {
+		return err
+	}
+	if info == nil {
+		log.Printf("[DEBUG] VM affinity rule %q not found in cluster %q, marking as gone", d.Get("name").(string), cluster.Reference().Value)
+		d.SetId("")
+		return nil
+	}
+
+	return flattenClusterVMAffinityRuleInfo(d, client, info)
+}
+
+func resourceVSphereComputeClusterVMAffinityRuleUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	cluster, err := clusterComputeResourceFromID(client, d.Get("compute_cluster_id").(string))
+	if err != nil {
+		return fmt.Errorf("cannot locate cluster: %s", err)
+	}
+
+	existing, err := resourceVSphereComputeClusterVMAffinityRuleFind(cluster, d.Get("name").(string))
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return fmt.Errorf("VM affinity rule %q not found in cluster %q", d.Get("name").(string), cluster.Reference().Value)
+	}
+	info, err := expandClusterVMAffinityRuleInfo(d, client, existing.Key)
+	if err != nil {
+		return err
+	}
+	if err := updateClusterRule(cluster, types.ArrayUpdateOperationEdit, info); err != nil {
+		return fmt.Errorf("error updating VM affinity rule: %s", err)
+	}
+
+	return resourceVSphereComputeClusterVMAffinityRuleRead(d, meta)
+}
+
+func resourceVSphereComputeClusterVMAffinityRuleDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	cluster, err := clusterComputeResourceFromID(client, d.Get("compute_cluster_id").(string))
+	if err != nil {
+		return fmt.Errorf("cannot locate cluster: %s", err)
+	}
+
+	existing, err := resourceVSphereComputeClusterVMAffinityRuleFind(cluster, d.Get("name").(string))
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		// Already gone
+		return nil
+	}
+	if err := removeClusterRule(cluster, existing.Key); err != nil {
+		return fmt.Errorf("error removing VM affinity rule: %s", err)
+	}
+	return nil
+}
+
+func resourceVSphereComputeClusterVMAffinityRuleImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	// We import with the same ID that the resource is saved with, which is the
+	// cluster ID and the rule name, separated by a colon.
+	clusterID, name, err := splitComputeClusterObjectID(d.Id())
+	if err != nil {
+		return nil, err
+	}
+	d.Set("compute_cluster_id", clusterID)
+	d.Set("name", name)
+	return []*schema.ResourceData{d}, nil
+}
+
+// resourceVSphereComputeClusterVMAffinityRuleFind locates the VM affinity rule
+// with the supplied name in the cluster. nil is returned if the rule does not
+// exist, and an error is returned if a rule with the name exists but is not a
+// VM affinity rule.
+func resourceVSphereComputeClusterVMAffinityRuleFind(cluster *object.ClusterComputeResource, name string) (*types.ClusterAffinityRuleSpec, error) {
+	rule, err := clusterRule(cluster, name)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching cluster rules: %s", err)
+	}
+	if rule == nil {
+		return nil, nil
+	}
+	info, ok := rule.(*types.ClusterAffinityRuleSpec)
+	if !ok {
+		return nil, fmt.Errorf("rule %q in cluster %q is a %T, not a VM affinity rule", name, cluster.Reference().Value, rule)
+	}
+	return info, nil
+}
+
+// expandClusterVMAffinityRuleInfo reads certain ResourceData keys and returns a
+// ClusterAffinityRuleSpec with the supplied key.
+func expandClusterVMAffinityRuleInfo(d *schema.ResourceData, client *govmomi.Client, key int32) (*types.ClusterAffinityRuleSpec, error) {
+	uuids := sliceInterfacesToStrings(d.Get("virtual_machine_uuids").(*schema.Set).List())
+	if len(uuids) < 2 {
+		return nil, errors.New("virtual_machine_uuids must contain at least 2 virtual machines")
+	}
+	refs, err := virtualMachineReferencesFromUUIDs(client, uuids)
+	if err != nil {
+		return nil, err
+	}
+	obj := &types.ClusterAffinityRuleSpec{
+		ClusterRuleInfo: expandClusterRuleInfo(d, key),
+		Vm:              refs,
+	}
+	return obj, nil
+}
+
+// flattenClusterVMAffinityRuleInfo reads various fields from a
+// ClusterAffinityRuleSpec into the passed in ResourceData.
+func flattenClusterVMAffinityRuleInfo(d *schema.ResourceData, client *govmomi.Client, obj *types.ClusterAffinityRuleSpec) error {
+	uuids, err := virtualMachineUUIDsFromReferences(client, obj.Vm)
+	if err != nil {
+		return err
+	}
+	if err := d.Set("virtual_machine_uuids", uuids); err != nil {
+		return fmt.Errorf("error setting virtual_machine_uuids: %s", err)
+	}
+	return flattenClusterRuleInfo(d, &obj.ClusterRuleInfo)
+}
@@ -0,0 +1,180 @@
+package vsphere
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestAccResourceVSphereComputeClusterVMAffinityRule(t *testing.T) {
+	var tp *testing.T
+	testAccResourceVSphereComputeClusterVMAffinityRuleCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"basic",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccComputeClusterMembersPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereComputeClusterVMAffinityRuleExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereComputeClusterVMAffinityRuleConfig(true),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereComputeClusterVMAffinityRuleExists(true),
+							testAccResourceVSphereComputeClusterVMAffinityRuleMatch(func(s *terraform.State, info *types.ClusterAffinityRuleSpec) error {
+								expected, err := testAccComputeClusterMembersVMIDs(s, 2)
+								if err != nil {
+									return err
+								}
+								var actual []string
+								for _, ref := range info.Vm {
+									actual = append(actual, ref.Value)
+								}
+								sort.Strings(actual)
+								if !reflect.DeepEqual(expected, actual) {
+									return fmt.Errorf("expected rule virtual machines to be %v, got %v", expected, actual)
+								}
+								if info.Enabled == nil || !*info.Enabled {
+									return errors.New("expected rule to be enabled")
+								}
+								return nil
+							}),
+						),
+					},
+				},
+			},
+		},
+		{
+			"disable",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccComputeClusterMembersPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereComputeClusterVMAffinityRuleExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereComputeClusterVMAffinityRuleConfig(true),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereComputeClusterVMAffinityRuleExists(true),
+						),
+					},
+					{
+						Config: testAccResourceVSphereComputeClusterVMAffinityRuleConfig(false),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereComputeClusterVMAffinityRuleExists(true),
+							testAccResourceVSphereComputeClusterVMAffinityRuleMatch(func(s *terraform.State, info *types.ClusterAffinityRuleSpec) error {
+								if info.Enabled == nil || *info.Enabled {
+									return errors.New("expected rule to be disabled")
+								}
+								return nil
+							}),
+						),
+					},
+				},
+			},
+		},
+		{
+			"import",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccComputeClusterMembersPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereComputeClusterVMAffinityRuleExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereComputeClusterVMAffinityRuleConfig(true),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereComputeClusterVMAffinityRuleExists(true),
+						),
+					},
+					{
+						ResourceName:      "vsphere_compute_cluster_vm_affinity_rule.cluster_vm_affinity_rule",
+						ImportState:       true,
+						ImportStateVerify: true,
+						Config:            testAccResourceVSphereComputeClusterVMAffinityRuleConfig(true),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccResourceVSphereComputeClusterVMAffinityRuleCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+// testAccResourceVSphereComputeClusterVMAffinityRuleGet fetches the VM
+// affinity rule in the test cluster. nil is returned if the rule does not
+// exist.
+func testAccResourceVSphereComputeClusterVMAffinityRuleGet(s *terraform.State) (*types.ClusterAffinityRuleSpec, error) {
+	cluster, err := testGetComputeCluster(s, "compute_cluster")
+	if err != nil {
+		return nil, err
+	}
+	return resourceVSphereComputeClusterVMAffinityRuleFind(cluster, "terraform-test-cluster-vm-affinity-rule")
+}
+
+func testAccResourceVSphereComputeClusterVMAffinityRuleExists(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		info, err := testAccResourceVSphereComputeClusterVMAffinityRuleGet(s)
+		if err != nil {
+			if isAnyNotFoundError(err) && expected == false {
+				// Expected missing
+				return nil
+			}
+			return err
+		}
+		exists := info != nil
+		if exists != expected {
+			return fmt.Errorf("expected VM affinity rule to exist: %t, got %t", expected, exists)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereComputeClusterVMAffinityRuleMatch(f func(*terraform.State, *types.ClusterAffinityRuleSpec) error) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		info, err := testAccResourceVSphereComputeClusterVMAffinityRuleGet(s)
+		if err != nil {
+			return err
+		}
+		if info == nil {
+			return errors.New("VM affinity rule not found")
+		}
+		return f(s, info)
+	}
+}
+
+func testAccResourceVSphereComputeClusterVMAffinityRuleConfig(enabled bool) string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_compute_cluster_vm_affinity_rule" "cluster_vm_affinity_rule" {
+  name                  = "terraform-test-cluster-vm-affinity-rule"
+  compute_cluster_id    = "${vsphere_compute_cluster.compute_cluster.id}"
+  virtual_machine_uuids = ["${vsphere_virtual_machine.vm.*.uuid}"]
+  enabled               = %t
+}
+`,
+		testAccComputeClusterMembersConfig(),
+		enabled,
+	)
+}
@@ -0,0 +1,180 @@
+package vsphere
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestAccResourceVSphereComputeClusterVMAntiAffinityRule(t *testing.T) {
+	var tp *testing.T
+	testAccResourceVSphereComputeClusterVMAntiAffinityRuleCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"basic",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccComputeClusterMembersPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereComputeClusterVMAntiAffinityRuleExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereComputeClusterVMAntiAffinityRuleConfig(true),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereComputeClusterVMAntiAffinityRuleExists(true),
+							testAccResourceVSphereComputeClusterVMAntiAffinityRuleMatch(func(s *terraform.State, info *types.ClusterAntiAffinityRuleSpec) error {
+								expected, err := testAccComputeClusterMembersVMIDs(s, 2)
+								if err != nil {
+									return err
+								}
+								var actual []string
+								for _, ref := range info.Vm {
+									actual = append(actual, ref.Value)
+								}
+								sort.Strings(actual)
+								if !reflect.DeepEqual(expected, actual) {
+									return fmt.Errorf("expected rule virtual machines to be %v, got %v", expected, actual)
+								}
+								if info.Enabled == nil || !*info.Enabled {
+									return errors.New("expected rule to be enabled")
+								}
+								return nil
+							}),
+						),
+					},
+				},
+			},
+		},
+		{
+			"disable",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccComputeClusterMembersPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereComputeClusterVMAntiAffinityRuleExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereComputeClusterVMAntiAffinityRuleConfig(true),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereComputeClusterVMAntiAffinityRuleExists(true),
+						),
+					},
+					{
+						Config: testAccResourceVSphereComputeClusterVMAntiAffinityRuleConfig(false),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereComputeClusterVMAntiAffinityRuleExists(true),
+							testAccResourceVSphereComputeClusterVMAntiAffinityRuleMatch(func(s *terraform.State, info *types.ClusterAntiAffinityRuleSpec) error {
+								if info.Enabled == nil || *info.Enabled {
+									return errors.New("expected rule to be disabled")
+								}
+								return nil
+							}),
+						),
+					},
+				},
+			},
+		},
+		{
+			"import",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccComputeClusterMembersPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereComputeClusterVMAntiAffinityRuleExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereComputeClusterVMAntiAffinityRuleConfig(true),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereComputeClusterVMAntiAffinityRuleExists(true),
+						),
+					},
+					{
+						ResourceName:      "vsphere_compute_cluster_vm_anti_affinity_rule.cluster_vm_anti_affinity_rule",
+						ImportState:       true,
+						ImportStateVerify: true,
+						Config:            testAccResourceVSphereComputeClusterVMAntiAffinityRuleConfig(true),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccResourceVSphereComputeClusterVMAntiAffinityRuleCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+// testAccResourceVSphereComputeClusterVMAntiAffinityRuleGet fetches the VM
+// anti-affinity rule in the test cluster. nil is returned if the rule does
+// not exist.
+func testAccResourceVSphereComputeClusterVMAntiAffinityRuleGet(s *terraform.State) (*types.ClusterAntiAffinityRuleSpec, error) {
+	cluster, err := testGetComputeCluster(s, "compute_cluster")
+	if err != nil {
+		return nil, err
+	}
+	return resourceVSphereComputeClusterVMAntiAffinityRuleFind(cluster, "terraform-test-cluster-vm-anti-affinity-rule")
+}
+
+func testAccResourceVSphereComputeClusterVMAntiAffinityRuleExists(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		info, err := testAccResourceVSphereComputeClusterVMAntiAffinityRuleGet(s)
+		if err != nil {
+			if isAnyNotFoundError(err) && expected == false {
+				// Expected missing
+				return nil
+			}
+			return err
+		}
+		exists := info != nil
+		if exists != expected {
+			return fmt.Errorf("expected VM anti-affinity rule to exist: %t, got %t", expected, exists)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereComputeClusterVMAntiAffinityRuleMatch(f func(*terraform.State, *types.ClusterAntiAffinityRuleSpec) error) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		info, err := testAccResourceVSphereComputeClusterVMAntiAffinityRuleGet(s)
+		if err != nil {
+			return err
+		}
+		if info == nil {
+			return errors.New("VM anti-affinity rule not found")
+		}
+		return f(s, info)
+	}
+}
+
+func testAccResourceVSphereComputeClusterVMAntiAffinityRuleConfig(enabled bool) string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_compute_cluster_vm_anti_affinity_rule" "cluster_vm_anti_affinity_rule" {
+  name                  = "terraform-test-cluster-vm-anti-affinity-rule"
+  compute_cluster_id    = "${vsphere_compute_cluster.compute_cluster.id}"
+  virtual_machine_uuids = ["${vsphere_virtual_machine.vm.*.uuid}"]
+  enabled               = %t
+}
+`,
+		testAccComputeClusterMembersConfig(),
+		enabled,
+	)
+}
@@ -0,0 +1,208 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func resourceVSphereComputeClusterVMGroup() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereComputeClusterVMGroupCreate,
+		Read:   resourceVSphereComputeClusterVMGroupRead,
+		Update: resourceVSphereComputeClusterVMGroupUpdate,
+		Delete: resourceVSphereComputeClusterVMGroupDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceVSphereComputeClusterVMGroupImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"compute_cluster_id": {
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the cluster.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"name": {
+				Type:         schema.TypeString,
+				Description:  "The name of the virtual machine group.",
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.NoZeroValues,
+			},
+			"virtual_machine_uuids": {
+				Type:        schema.TypeSet,
+				Description: "The UUIDs of the virtual machines in this group.",
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func resourceVSphereComputeClusterVMGroupCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	cluster, err := clusterComputeResourceFromID(client, d.Get("compute_cluster_id").(string))
+	if err != nil {
+		return fmt.Errorf("cannot locate cluster: %s", err)
+	}
+
+	// Refuse to take over a group that already exists, as it may be managed
+	// outside of this resource.
+	name := d.Get("name").(string)
+	existing, err := clusterGroup(cluster, name)
+	if err != nil {
+		return fmt.Errorf("error fetching cluster groups: %s", err)
+	}
+	if existing != nil {
+		return fmt.Errorf("group %q already exists in cluster %q", name, cluster.Reference().Value)
+	}
+
+	info, err := expandClusterVMGroup(d, client)
+	if err != nil {
+		return err
+	}
+	if err := updateClusterGroup(cluster, types.ArrayUpdateOperationAdd, info); err != nil {
+		return fmt.Errorf("error creating virtual machine group: %s", err)
+	}
+
+	d.SetId(fmt.Sprintf("%s:%s", cluster.Reference().Value, name))
+	return resourceVSphereComputeClusterVMGroupRead(d, meta)
+}
+
+func resourceVSphereComputeClusterVMGroupRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	cluster, err := clusterComputeResourceFromID(client, d.Get("compute_cluster_id").(string))
+	if err != nil {
+		return fmt.Errorf("cannot locate cluster: %s", err)
+	}
+
+	info, err := resourceVSphereComputeClusterVMGroupFind(cluster, d.Get("name").(string))
+	if err != nil {
+		return err
+	}
+	if info == nil {
+		log.Printf("[DEBUG] Virtual machine group %q not found in cluster %q, marking as gone", d.Get("name").(string), cluster.Reference().Value)
+		d.SetId("")
+		return nil
+	}
+
+	return flattenClusterVMGroup(d, client, info)
+}
+
+func resourceVSphereComputeClusterVMGroupUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	cluster, err := clusterComputeResourceFromID(client, d.Get("compute_cluster_id").(string))
+	if err != nil {
+		return fmt.Errorf("cannot locate cluster: %s", err)
+	}
+
+	info, err := expandClusterVMGroup(d, client)
+	if err != nil {
+		return err
+	}
+	if err := updateClusterGroup(cluster, types.ArrayUpdateOperationEdit, info); err != nil {
+		return fmt.Errorf("error updating virtual machine group: %s", err)
+	}
+
+	return resourceVSphereComputeClusterVMGroupRead(d, meta)
+}
+
+func resourceVSphereComputeClusterVMGroupDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	cluster, err := clusterComputeResourceFromID(client, d.Get("compute_cluster_id").(string))
+	if err != nil {
+		return fmt.Errorf("cannot locate cluster: %s", err)
+	}
+
+	existing, err := resourceVSphereComputeClusterVMGroupFind(cluster, d.Get("name").(string))
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		// Already gone
+		return nil
+	}
+	if err := removeClusterGroup(cluster, d.Get("name").(string)); err != nil {
+		return fmt.Errorf("error removing virtual machine group: %s", err)
+	}
+	return nil
+}
+
+func resourceVSphereComputeClusterVMGroupImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	// We import with the same ID that the resource is saved with, which is the
+	// cluster ID and the group name, separated by a colon.
+	clusterID, name, err := splitComputeClusterObjectID(d.Id())
+	if err != nil {
+		return nil, err
+	}
+	d.Set("compute_cluster_id", clusterID)
+	d.Set("name", name)
+	return []*schema.ResourceData{d}, nil
+}
+
+// resourceVSphereComputeClusterVMGroupFind locates the virtual machine group
+// with the supplied name in the cluster. nil is returned if the group does not
+// exist, and an error is returned if a group with the name exists but is not a
+// virtual machine group.
+func resourceVSphereComputeClusterVMGroupFind(cluster *object.ClusterComputeResource, name string) (*types.ClusterVmGroup, error) {
+	group, err := clusterGroup(cluster, name)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching cluster groups: %s", err)
+	}
+	if group == nil {
+		return nil, nil
+	}
+	info, ok := group.(*types.ClusterVmGroup)
+	if !ok {
+		return nil, fmt.Errorf("group %q in cluster %q is a %T, not a virtual machine group", name, cluster.Reference().Value, group)
+	}
+	return info, nil
+}
+
+// expandClusterVMGroup reads certain ResourceData keys and returns a
+// ClusterVmGroup.
+func expandClusterVMGroup(d *schema.ResourceData, client *govmomi.Client) (*types.ClusterVmGroup, error) {
+	uuids := sliceInterfacesToStrings(d.Get("virtual_machine_uuids").(*schema.Set).List())
+	refs, err := virtualMachineReferencesFromUUIDs(client, uuids)
+	if err != nil {
+		return nil, err
+	}
+	obj := &types.ClusterVmGroup{
+		ClusterGroupInfo: types.ClusterGroupInfo{
+			Name: d.Get("name").(string),
+		},
+		Vm: refs,
+	}
+	return obj, nil
+}
+
+// flattenClusterVMGroup reads various fields from a ClusterVmGroup into the
+// passed in ResourceData.
+func flattenClusterVMGroup(d *schema.ResourceData, client *govmomi.Client, obj *types.ClusterVmGroup) error {
+	uuids, err := virtualMachineUUIDsFromReferences(client, obj.Vm)
+	if err != nil {
+		return err
+	}
+	if err := d.Set("virtual_machine_uuids", uuids); err != nil {
+		return fmt.Errorf("error setting virtual_machine_uuids: %s", err)
+	}
+	return nil
+}
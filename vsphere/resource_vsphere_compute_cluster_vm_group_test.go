@@ -0,0 +1,172 @@
+package vsphere
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestAccResourceVSphereComputeClusterVMGroup(t *testing.T) {
+	var tp *testing.T
+	testAccResourceVSphereComputeClusterVMGroupCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"basic",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccComputeClusterMembersPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereComputeClusterVMGroupExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereComputeClusterVMGroupConfig(1),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereComputeClusterVMGroupExists(true),
+							testAccResourceVSphereComputeClusterVMGroupMatchMembership(1),
+						),
+					},
+				},
+			},
+		},
+		{
+			"update membership",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccComputeClusterMembersPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereComputeClusterVMGroupExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereComputeClusterVMGroupConfig(1),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereComputeClusterVMGroupExists(true),
+							testAccResourceVSphereComputeClusterVMGroupMatchMembership(1),
+						),
+					},
+					{
+						Config: testAccResourceVSphereComputeClusterVMGroupConfig(2),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereComputeClusterVMGroupExists(true),
+							testAccResourceVSphereComputeClusterVMGroupMatchMembership(2),
+						),
+					},
+				},
+			},
+		},
+		{
+			"import",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccComputeClusterMembersPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereComputeClusterVMGroupExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereComputeClusterVMGroupConfig(2),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereComputeClusterVMGroupExists(true),
+						),
+					},
+					{
+						ResourceName:      "vsphere_compute_cluster_vm_group.cluster_vm_group",
+						ImportState:       true,
+						ImportStateVerify: true,
+						Config:            testAccResourceVSphereComputeClusterVMGroupConfig(2),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccResourceVSphereComputeClusterVMGroupCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+// testAccResourceVSphereComputeClusterVMGroupGet fetches the virtual machine
+// group in the test cluster. nil is returned if the group does not exist.
+func testAccResourceVSphereComputeClusterVMGroupGet(s *terraform.State) (*types.ClusterVmGroup, error) {
+	cluster, err := testGetComputeCluster(s, "compute_cluster")
+	if err != nil {
+		return nil, err
+	}
+	return resourceVSphereComputeClusterVMGroupFind(cluster, "terraform-test-cluster-vm-group")
+}
+
+func testAccResourceVSphereComputeClusterVMGroupExists(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		info, err := testAccResourceVSphereComputeClusterVMGroupGet(s)
+		if err != nil {
+			if isAnyNotFoundError(err) && expected == false {
+				// Expected missing
+				return nil
+			}
+			return err
+		}
+		exists := info != nil
+		if exists != expected {
+			return fmt.Errorf("expected virtual machine group to exist: %t, got %t", expected, exists)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereComputeClusterVMGroupMatchMembership checks that the
+// virtual machine group contains the first count virtual machines in the test
+// configuration.
+func testAccResourceVSphereComputeClusterVMGroupMatchMembership(count int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		info, err := testAccResourceVSphereComputeClusterVMGroupGet(s)
+		if err != nil {
+			return err
+		}
+		if info == nil {
+			return errors.New("virtual machine group not found")
+		}
+		expected, err := testAccComputeClusterMembersVMIDs(s, count)
+		if err != nil {
+			return err
+		}
+		var actual []string
+		for _, ref := range info.Vm {
+			actual = append(actual, ref.Value)
+		}
+		sort.Strings(actual)
+		if !reflect.DeepEqual(expected, actual) {
+			return fmt.Errorf("expected virtual machine group members to be %v, got %v", expected, actual)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereComputeClusterVMGroupConfig(count int) string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_compute_cluster_vm_group" "cluster_vm_group" {
+  name                  = "terraform-test-cluster-vm-group"
+  compute_cluster_id    = "${vsphere_compute_cluster.compute_cluster.id}"
+  virtual_machine_uuids = ["${slice(vsphere_virtual_machine.vm.*.uuid, 0, %d)}"]
+}
+`,
+		testAccComputeClusterMembersConfig(),
+		count,
+	)
+}
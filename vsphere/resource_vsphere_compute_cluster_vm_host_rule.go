@@ -0,0 +1,223 @@
+package vsphere
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func resourceVSphereComputeClusterVMHostRule() *schema.Resource {
+	s := map[string]*schema.Schema{
+		"compute_cluster_id": {
+			Type:        schema.TypeString,
+			Description: "The managed object ID of the cluster.",
+			Required:    true,
+			ForceNew:    true,
+		},
+		"name": {
+			Type:         schema.TypeString,
+			Description:  "The name of the rule.",
+			Required:     true,
+			ForceNew:     true,
+			ValidateFunc: validation.NoZeroValues,
+		},
+		"vm_group_name": {
+			Type:         schema.TypeString,
+			Description:  "The name of the virtual machine group to use with this rule.",
+			Required:     true,
+			ValidateFunc: validation.NoZeroValues,
+		},
+		"affinity_host_group_name": {
+			Type:          schema.TypeString,
+			Description:   "The name of the host group that the virtual machines in vm_group_name should run on.",
+			Optional:      true,
+			ConflictsWith: []string{"anti_affinity_host_group_name"},
+		},
+		"anti_affinity_host_group_name": {
+			Type:          schema.TypeString,
+			Description:   "The name of the host group that the virtual machines in vm_group_name should not run on.",
+			Optional:      true,
+			ConflictsWith: []string{"affinity_host_group_name"},
+		},
+	}
+	mergeSchema(s, schemaClusterRuleInfo())
+
+	return &schema.Resource{
+		Create: resourceVSphereComputeClusterVMHostRuleCreate,
+		Read:   resourceVSphereComputeClusterVMHostRuleRead,
+		Update: resourceVSphereComputeClusterVMHostRuleUpdate,
+		Delete: resourceVSphereComputeClusterVMHostRuleDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceVSphereComputeClusterVMHostRuleImport,
+		},
+		Schema: s,
+	}
+}
+
+func resourceVSphereComputeClusterVMHostRuleCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	cluster, err := clusterComputeResourceFromID(client, d.Get("compute_cluster_id").(string))
+	if err != nil {
+		return fmt.Errorf("cannot locate cluster: %s", err)
+	}
+
+	// Refuse to take over a rule that already exists, as it may be managed
+	// outside of this resource.
+	name := d.Get("name").(string)
+	existing, err := clusterRule(cluster, name)
+	if err != nil {
+		return fmt.Errorf("error fetching cluster rules: %s", err)
+	}
+	if existing != nil {
+		return fmt.Errorf("rule %q already exists in cluster %q", name, cluster.Reference().Value)
+	}
+
+	info, err := expandClusterVMHostRuleInfo(d, 0)
+	if err != nil {
+		return err
+	}
+	if err := updateClusterRule(cluster, types.ArrayUpdateOperationAdd, info); err != nil {
+		return fmt.Errorf("error creating VM/host rule: %s", err)
+	}
+
+	d.SetId(fmt.Sprintf("%s:%s", cluster.Reference().Value, name))
+	return resourceVSphereComputeClusterVMHostRuleRead(d, meta)
+}
+
+func resourceVSphereComputeClusterVMHostRuleRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	cluster, err := clusterComputeResourceFromID(client, d.Get("compute_cluster_id").(string))
+	if err != nil {
+		return fmt.Errorf("cannot locate cluster: %s", err)
+	}
+
+	info, err := resourceVSphereComputeClusterVMHostRuleFind(cluster, d.Get("name").(string))
+	if err != nil {
+		return err
+	}
+	if info == nil {
+		log.Printf("[DEBUG] VM/host rule %q not found in cluster %q, marking as gone", d.Get("name").(string), cluster.Reference().Value)
+		d.SetId("")
+		return nil
+	}
+
+	return flattenClusterVMHostRuleInfo(d, info)
+}
+
+func resourceVSphereComputeClusterVMHostRuleUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	cluster, err := clusterComputeResourceFromID(client, d.Get("compute_cluster_id").(string))
+	if err != nil {
+		return fmt.Errorf("cannot locate cluster: %s", err)
+	}
+
+	existing, err := resourceVSphereComputeClusterVMHostRuleFind(cluster, d.Get("name").(string))
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return fmt.Errorf("VM/host rule %q not found in cluster %q", d.Get("name").(string), cluster.Reference().Value)
+	}
+	info, err := expandClusterVMHostRuleInfo(d, existing.Key)
+	if err != nil {
+		return err
+	}
+	if err := updateClusterRule(cluster, types.ArrayUpdateOperationEdit, info); err != nil {
+		return fmt.Errorf("error updating VM/host rule: %s", err)
+	}
+
+	return resourceVSphereComputeClusterVMHostRuleRead(d, meta)
+}
+
+func resourceVSphereComputeClusterVMHostRuleDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	cluster, err := clusterComputeResourceFromID(client, d.Get("compute_cluster_id").(string))
+	if err != nil {
+		return fmt.Errorf("cannot locate cluster: %s", err)
+	}
+
+	existing, err := resourceVSphereComputeClusterVMHostRuleFind(cluster, d.Get("name").(string))
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		// Already gone
+		return nil
+	}
+	if err := removeClusterRule(cluster, existing.Key); err != nil {
+		return fmt.Errorf("error removing VM/host rule: %s", err)
+	}
+	return nil
+}
+
+func resourceVSphereComputeClusterVMHostRuleImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	// We import with the same ID that the resource is saved with, which is the
+	// cluster ID and the rule name, separated by a colon.
+	clusterID, name, err := splitComputeClusterObjectID(d.Id())
+	if err != nil {
+		return nil, err
+	}
+	d.Set("compute_cluster_id", clusterID)
+	d.Set("name", name)
+	return []*schema.ResourceData{d}, nil
+}
+
+// resourceVSphereComputeClusterVMHostRuleFind locates the VM/host rule with
+// the supplied name in the cluster. nil is returned if the rule does not
+// exist, and an error is returned if a rule with the name exists but is not a
+// VM/host rule.
+func resourceVSphereComputeClusterVMHostRuleFind(cluster *object.ClusterComputeResource, name string) (*types.ClusterVmHostRuleInfo, error) {
+	rule, err := clusterRule(cluster, name)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching cluster rules: %s", err)
+	}
+	if rule == nil {
+		return nil, nil
+	}
+	info, ok := rule.(*types.ClusterVmHostRuleInfo)
+	if !ok {
+		return nil, fmt.Errorf("rule %q in cluster %q is a %T, not a VM/host rule", name, cluster.Reference().Value, rule)
+	}
+	return info, nil
+}
+
+// expandClusterVMHostRuleInfo reads certain ResourceData keys and returns a
+// ClusterVmHostRuleInfo with the supplied key.
+func expandClusterVMHostRuleInfo(d *schema.ResourceData, key int32) (*types.ClusterVmHostRuleInfo, error) {
+	obj := &types.ClusterVmHostRuleInfo{
+		ClusterRuleInfo:         expandClusterRuleInfo(d, key),
+		VmGroupName:             d.Get("vm_group_name").(string),
+		AffineHostGroupName:     d.Get("affinity_host_group_name").(string),
+		AntiAffineHostGroupName: d.Get("anti_affinity_host_group_name").(string),
+	}
+	if obj.AffineHostGroupName == "" && obj.AntiAffineHostGroupName == "" {
+		return nil, errors.New("one of affinity_host_group_name or anti_affinity_host_group_name must be set")
+	}
+	return obj, nil
+}
+
+// flattenClusterVMHostRuleInfo reads various fields from a
+// ClusterVmHostRuleInfo into the passed in ResourceData.
+func flattenClusterVMHostRuleInfo(d *schema.ResourceData, obj *types.ClusterVmHostRuleInfo) error {
+	d.Set("vm_group_name", obj.VmGroupName)
+	d.Set("affinity_host_group_name", obj.AffineHostGroupName)
+	d.Set("anti_affinity_host_group_name", obj.AntiAffineHostGroupName)
+	return flattenClusterRuleInfo(d, &obj.ClusterRuleInfo)
+}
@@ -0,0 +1,188 @@
+package vsphere
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestAccResourceVSphereComputeClusterVMHostRule(t *testing.T) {
+	var tp *testing.T
+	testAccResourceVSphereComputeClusterVMHostRuleCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"affinity",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccComputeClusterMembersPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereComputeClusterVMHostRuleExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereComputeClusterVMHostRuleConfig("affinity_host_group_name", true),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereComputeClusterVMHostRuleExists(true),
+							testAccResourceVSphereComputeClusterVMHostRuleMatch(func(info *types.ClusterVmHostRuleInfo) error {
+								if info.AffineHostGroupName != "terraform-test-cluster-host-group" {
+									return fmt.Errorf("expected affinity host group to be set, got %q", info.AffineHostGroupName)
+								}
+								if info.Enabled == nil || !*info.Enabled {
+									return errors.New("expected rule to be enabled")
+								}
+								return nil
+							}),
+						),
+					},
+				},
+			},
+		},
+		{
+			"update to anti-affinity and disable",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccComputeClusterMembersPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereComputeClusterVMHostRuleExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereComputeClusterVMHostRuleConfig("affinity_host_group_name", true),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereComputeClusterVMHostRuleExists(true),
+						),
+					},
+					{
+						Config: testAccResourceVSphereComputeClusterVMHostRuleConfig("anti_affinity_host_group_name", false),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereComputeClusterVMHostRuleExists(true),
+							testAccResourceVSphereComputeClusterVMHostRuleMatch(func(info *types.ClusterVmHostRuleInfo) error {
+								if info.AffineHostGroupName != "" {
+									return fmt.Errorf("expected affinity host group to be empty, got %q", info.AffineHostGroupName)
+								}
+								if info.AntiAffineHostGroupName != "terraform-test-cluster-host-group" {
+									return fmt.Errorf("expected anti-affinity host group to be set, got %q", info.AntiAffineHostGroupName)
+								}
+								if info.Enabled == nil || *info.Enabled {
+									return errors.New("expected rule to be disabled")
+								}
+								return nil
+							}),
+						),
+					},
+				},
+			},
+		},
+		{
+			"import",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccComputeClusterMembersPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereComputeClusterVMHostRuleExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereComputeClusterVMHostRuleConfig("affinity_host_group_name", true),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereComputeClusterVMHostRuleExists(true),
+						),
+					},
+					{
+						ResourceName:      "vsphere_compute_cluster_vm_host_rule.cluster_vm_host_rule",
+						ImportState:       true,
+						ImportStateVerify: true,
+						Config:            testAccResourceVSphereComputeClusterVMHostRuleConfig("affinity_host_group_name", true),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccResourceVSphereComputeClusterVMHostRuleCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+// testAccResourceVSphereComputeClusterVMHostRuleGet fetches the VM/host rule
+// in the test cluster. nil is returned if the rule does not exist.
+func testAccResourceVSphereComputeClusterVMHostRuleGet(s *terraform.State) (*types.ClusterVmHostRuleInfo, error) {
+	cluster, err := testGetComputeCluster(s, "compute_cluster")
+	if err != nil {
+		return nil, err
+	}
+	return resourceVSphereComputeClusterVMHostRuleFind(cluster, "terraform-test-cluster-vm-host-rule")
+}
+
+func testAccResourceVSphereComputeClusterVMHostRuleExists(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		info, err := testAccResourceVSphereComputeClusterVMHostRuleGet(s)
+		if err != nil {
+			if isAnyNotFoundError(err) && expected == false {
+				// Expected missing
+				return nil
+			}
+			return err
+		}
+		exists := info != nil
+		if exists != expected {
+			return fmt.Errorf("expected VM/host rule to exist: %t, got %t", expected, exists)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereComputeClusterVMHostRuleMatch(f func(*types.ClusterVmHostRuleInfo) error) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		info, err := testAccResourceVSphereComputeClusterVMHostRuleGet(s)
+		if err != nil {
+			return err
+		}
+		if info == nil {
+			return errors.New("VM/host rule not found")
+		}
+		return f(info)
+	}
+}
+
+func testAccResourceVSphereComputeClusterVMHostRuleConfig(hostGroupKey string, enabled bool) string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_compute_cluster_vm_group" "cluster_vm_group" {
+  name                  = "terraform-test-cluster-vm-group"
+  compute_cluster_id    = "${vsphere_compute_cluster.compute_cluster.id}"
+  virtual_machine_uuids = ["${vsphere_virtual_machine.vm.*.uuid}"]
+}
+
+resource "vsphere_compute_cluster_host_group" "cluster_host_group" {
+  name               = "terraform-test-cluster-host-group"
+  compute_cluster_id = "${vsphere_compute_cluster.compute_cluster.id}"
+  host_system_ids    = ["${vsphere_host.host.id}"]
+}
+
+resource "vsphere_compute_cluster_vm_host_rule" "cluster_vm_host_rule" {
+  compute_cluster_id = "${vsphere_compute_cluster.compute_cluster.id}"
+  name               = "terraform-test-cluster-vm-host-rule"
+  vm_group_name      = "${vsphere_compute_cluster_vm_group.cluster_vm_group.name}"
+  %s = "${vsphere_compute_cluster_host_group.cluster_host_group.name}"
+  enabled            = %t
+}
+`,
+		testAccComputeClusterMembersConfig(),
+		hostGroupKey,
+		enabled,
+	)
+}
@@ -0,0 +1,301 @@
+package vsphere
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func resourceVSphereDatastoreCluster() *schema.Resource {
+	s := map[string]*schema.Schema{
+		"name": {
+			Type:         schema.TypeString,
+			Description:  "The name of the datastore cluster.",
+			Required:     true,
+			ValidateFunc: validation.NoZeroValues,
+		},
+		"datacenter_id": {
+			Type:        schema.TypeString,
+			Description: "The managed object ID of the datacenter to create the datastore cluster in.",
+			Required:    true,
+			ForceNew:    true,
+		},
+		"folder": {
+			Type:        schema.TypeString,
+			Description: "The path to the datastore folder to put the datastore cluster in.",
+			Optional:    true,
+			StateFunc:   normalizeFolderPath,
+		},
+		"datastore_ids": {
+			Type:        schema.TypeSet,
+			Description: "The managed object IDs of the datastores that are members of this datastore cluster.",
+			Optional:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+		},
+		// Tagging
+		vSphereTagAttributeKey: tagsSchema(),
+	}
+	mergeSchema(s, schemaStorageDrsPodConfigSpec())
+
+	return &schema.Resource{
+		Create: resourceVSphereDatastoreClusterCreate,
+		Read:   resourceVSphereDatastoreClusterRead,
+		Update: resourceVSphereDatastoreClusterUpdate,
+		Delete: resourceVSphereDatastoreClusterDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceVSphereDatastoreClusterImport,
+		},
+		Schema: s,
+	}
+}
+
+func resourceVSphereDatastoreClusterCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	tagsClient, err := tagsClientIfDefined(d, meta)
+	if err != nil {
+		return err
+	}
+
+	dc, err := datacenterFromID(client, d.Get("datacenter_id").(string))
+	if err != nil {
+		return fmt.Errorf("cannot locate datacenter: %s", err)
+	}
+	folder, err := folderFromPath(client, d.Get("folder").(string), vSphereFolderTypeDatastore, dc)
+	if err != nil {
+		return fmt.Errorf("cannot locate folder: %s", err)
+	}
+
+	pod, err := createStoragePod(folder, d.Get("name").(string))
+	if err != nil {
+		return fmt.Errorf("error creating datastore cluster: %s", err)
+	}
+	d.SetId(pod.Reference().Value)
+
+	// Configure storage DRS. This is done before datastores are added so that
+	// the cluster does not start balancing with the default settings.
+	spec := types.StorageDrsConfigSpec{
+		PodConfigSpec: expandStorageDrsPodConfigSpec(d),
+	}
+	if err := applyStorageDrsConfig(client, pod, spec); err != nil {
+		return fmt.Errorf("error configuring storage DRS: %s", err)
+	}
+
+	ids := sliceInterfacesToStrings(d.Get("datastore_ids").(*schema.Set).List())
+	if err := moveDatastoresToFolder(pod.Folder, ids); err != nil {
+		return fmt.Errorf("error adding datastores to datastore cluster: %s", err)
+	}
+
+	// Apply any pending tags now
+	if tagsClient != nil {
+		if err := processTagDiff(tagsClient, d, pod); err != nil {
+			return fmt.Errorf("error updating tags: %s", err)
+		}
+	}
+
+	return resourceVSphereDatastoreClusterRead(d, meta)
+}
+
+func resourceVSphereDatastoreClusterRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	pod, err := storagePodFromID(client, d.Id())
+	if err != nil {
+		return fmt.Errorf("cannot locate datastore cluster: %s", err)
+	}
+	props, err := storagePodProperties(pod)
+	if err != nil {
+		return fmt.Errorf("error fetching datastore cluster properties: %s", err)
+	}
+	d.Set("name", props.Name)
+
+	// Set the datacenter ID, for completion's sake when importing
+	dcp, err := rootPathParticleDatastore.SplitDatacenter(pod.InventoryPath)
+	if err != nil {
+		return fmt.Errorf("error parsing datacenter from inventory path: %s", err)
+	}
+	dc, err := getDatacenter(client, dcp)
+	if err != nil {
+		return fmt.Errorf("error locating datacenter: %s", err)
+	}
+	d.Set("datacenter_id", dc.Reference().Value)
+
+	// Set the folder
+	folder, err := rootPathParticleDatastore.SplitRelativeFolder(pod.InventoryPath)
+	if err != nil {
+		return fmt.Errorf("error parsing datastore cluster path %q: %s", pod.InventoryPath, err)
+	}
+	d.Set("folder", normalizeFolderPath(folder))
+
+	// Update member datastores
+	var ids []string
+	for _, ref := range props.ChildEntity {
+		if ref.Type == "Datastore" {
+			ids = append(ids, ref.Value)
+		}
+	}
+	if err := d.Set("datastore_ids", ids); err != nil {
+		return fmt.Errorf("error setting datastore_ids: %s", err)
+	}
+
+	// Read in storage DRS config
+	if props.PodStorageDrsEntry != nil {
+		if err := flattenStorageDrsPodConfigInfo(d, props.PodStorageDrsEntry.StorageDrsConfig.PodConfig); err != nil {
+			return err
+		}
+	}
+
+	// Read tags if we have the ability to do so
+	if tagsClient, _ := meta.(*VSphereClient).TagsClient(); tagsClient != nil {
+		if err := readTagsForResource(tagsClient, pod, d); err != nil {
+			return fmt.Errorf("error reading tags: %s", err)
+		}
+	}
+
+	return nil
+}
+
+func resourceVSphereDatastoreClusterUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	tagsClient, err := tagsClientIfDefined(d, meta)
+	if err != nil {
+		return err
+	}
+	pod, err := storagePodFromID(client, d.Id())
+	if err != nil {
+		return fmt.Errorf("cannot locate datastore cluster: %s", err)
+	}
+
+	// Rename this datastore cluster if our name has drifted.
+	if d.HasChange("name") {
+		if err := renameObject(client, pod.Reference(), d.Get("name").(string)); err != nil {
+			return fmt.Errorf("could not rename datastore cluster: %s", err)
+		}
+	}
+
+	// Update folder if necessary
+	if d.HasChange("folder") {
+		dc, err := datacenterFromID(client, d.Get("datacenter_id").(string))
+		if err != nil {
+			return fmt.Errorf("cannot locate datacenter: %s", err)
+		}
+		folder, err := folderFromPath(client, d.Get("folder").(string), vSphereFolderTypeDatastore, dc)
+		if err != nil {
+			return fmt.Errorf("cannot locate folder: %s", err)
+		}
+		if err := moveObjectToFolder(pod.Reference(), folder); err != nil {
+			return fmt.Errorf("could not move datastore cluster to folder %q: %s", d.Get("folder").(string), err)
+		}
+		// Reload the datastore cluster so that its inventory path reflects the
+		// new folder when moving datastores out of it below.
+		if pod, err = storagePodFromID(client, d.Id()); err != nil {
+			return fmt.Errorf("cannot locate datastore cluster: %s", err)
+		}
+	}
+
+	// Update storage DRS config if any of it has changed.
+	var sdrsChanged bool
+	for k := range schemaStorageDrsPodConfigSpec() {
+		if d.HasChange(k) {
+			sdrsChanged = true
+			break
+		}
+	}
+	if sdrsChanged {
+		spec := types.StorageDrsConfigSpec{
+			PodConfigSpec: expandStorageDrsPodConfigSpec(d),
+		}
+		if err := applyStorageDrsConfig(client, pod, spec); err != nil {
+			return fmt.Errorf("error configuring storage DRS: %s", err)
+		}
+	}
+
+	// Process datastore membership changes. Removed datastores go back to the
+	// folder that the datastore cluster is in.
+	if d.HasChange("datastore_ids") {
+		o, n := d.GetChange("datastore_ids")
+		removed := sliceInterfacesToStrings(o.(*schema.Set).Difference(n.(*schema.Set)).List())
+		added := sliceInterfacesToStrings(n.(*schema.Set).Difference(o.(*schema.Set)).List())
+		if err := moveDatastoresOutOfStoragePod(client, pod, removed); err != nil {
+			return fmt.Errorf("error removing datastores from datastore cluster: %s", err)
+		}
+		if err := moveDatastoresToFolder(pod.Folder, added); err != nil {
+			return fmt.Errorf("error adding datastores to datastore cluster: %s", err)
+		}
+	}
+
+	// Apply any pending tags now
+	if tagsClient != nil {
+		if err := processTagDiff(tagsClient, d, pod); err != nil {
+			return fmt.Errorf("error updating tags: %s", err)
+		}
+	}
+
+	return resourceVSphereDatastoreClusterRead(d, meta)
+}
+
+func resourceVSphereDatastoreClusterDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	pod, err := storagePodFromID(client, d.Id())
+	if err != nil {
+		return fmt.Errorf("cannot locate datastore cluster: %s", err)
+	}
+
+	// Move our member datastores out of the datastore cluster first. We don't
+	// destroy the datastore cluster if there are any datastores left in it that
+	// we don't manage.
+	ids := sliceInterfacesToStrings(d.Get("datastore_ids").(*schema.Set).List())
+	if err := moveDatastoresOutOfStoragePod(client, pod, ids); err != nil {
+		return fmt.Errorf("error removing datastores from datastore cluster: %s", err)
+	}
+	ne, err := folderHasChildren(pod.Folder)
+	if err != nil {
+		return fmt.Errorf("error checking for datastore cluster contents: %s", err)
+	}
+	if ne {
+		return errors.New("datastore cluster still contains datastores not managed by this resource, please remove them before deleting")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	task, err := pod.Destroy(ctx)
+	if err != nil {
+		return fmt.Errorf("error deleting datastore cluster: %s", err)
+	}
+	tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer tcancel()
+	if err := task.Wait(tctx); err != nil {
+		return fmt.Errorf("error waiting for datastore cluster deletion to complete: %s", err)
+	}
+
+	return nil
+}
+
+func resourceVSphereDatastoreClusterImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	// We import datastore clusters by inventory path. A full path is required
+	// unless the default datacenter can be utilized.
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return nil, err
+	}
+	pod, err := storagePodFromPath(client, d.Id(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error locating datastore cluster: %s", err)
+	}
+	d.SetId(pod.Reference().Value)
+	return []*schema.ResourceData{d}, nil
+}
@@ -0,0 +1,486 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccResourceVSphereDatastoreCluster(t *testing.T) {
+	var tp *testing.T
+	testAccResourceVSphereDatastoreClusterCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"basic",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereDatastoreClusterExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereDatastoreClusterConfigBasic(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDatastoreClusterExists(true),
+							testAccResourceVSphereDatastoreClusterHasSdrsEnabled(true),
+							testAccResourceVSphereDatastoreClusterHasSdrsAutomationLevel("manual"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"storage DRS settings",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereDatastoreClusterExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereDatastoreClusterConfigBasic(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDatastoreClusterExists(true),
+						),
+					},
+					{
+						Config: testAccResourceVSphereDatastoreClusterConfigSdrs(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDatastoreClusterExists(true),
+							testAccResourceVSphereDatastoreClusterHasSdrsAutomationLevel("automated"),
+							testAccResourceVSphereDatastoreClusterHasSdrsSpaceUtilizationThreshold(70),
+							resource.TestCheckResourceAttr("vsphere_datastore_cluster.datastore_cluster", "sdrs_io_latency_threshold", "20"),
+							resource.TestCheckResourceAttr("vsphere_datastore_cluster.datastore_cluster", "sdrs_load_balance_interval", "120"),
+							resource.TestCheckResourceAttr("vsphere_datastore_cluster.datastore_cluster", "sdrs_default_intra_vm_affinity", "false"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"member datastores",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereDatastoreClusterPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereDatastoreClusterExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereDatastoreClusterConfigMembers(true),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDatastoreClusterExists(true),
+							testAccResourceVSphereDatastoreClusterHasMembers([]string{"terraform-test-nas"}),
+						),
+					},
+					{
+						Config: testAccResourceVSphereDatastoreClusterConfigMembers(false),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDatastoreClusterExists(true),
+							testAccResourceVSphereDatastoreClusterHasMembers(nil),
+						),
+					},
+				},
+			},
+		},
+		{
+			"rename and move to folder",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereDatastoreClusterExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereDatastoreClusterConfigBasic(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDatastoreClusterExists(true),
+						),
+					},
+					{
+						Config: testAccResourceVSphereDatastoreClusterConfigRenamedInFolder(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDatastoreClusterExists(true),
+							testAccResourceVSphereDatastoreClusterHasName("terraform-datastore-cluster-test-renamed"),
+							testAccResourceVSphereDatastoreClusterMatchInventoryPath("terraform-test-datastore-cluster-folder"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"tags",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereDatastoreClusterExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereDatastoreClusterConfigTags(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDatastoreClusterExists(true),
+							testAccResourceVSphereDatastoreClusterCheckTags("terraform-test-tag"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"import",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereDatastoreClusterExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereDatastoreClusterConfigSdrs(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDatastoreClusterExists(true),
+						),
+					},
+					{
+						ResourceName:      "vsphere_datastore_cluster.datastore_cluster",
+						ImportState:       true,
+						ImportStateVerify: true,
+						ImportStateIdFunc: func(s *terraform.State) (string, error) {
+							pod, err := testGetDatastoreCluster(s, "datastore_cluster")
+							if err != nil {
+								return "", err
+							}
+							return pod.InventoryPath, nil
+						},
+						Config: testAccResourceVSphereDatastoreClusterConfigSdrs(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDatastoreClusterExists(true),
+						),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccResourceVSphereDatastoreClusterCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+func testAccResourceVSphereDatastoreClusterPreCheck(t *testing.T) {
+	if os.Getenv("VSPHERE_ESXI_HOST") == "" {
+		t.Skip("set VSPHERE_ESXI_HOST to run vsphere_datastore_cluster acceptance tests")
+	}
+	if os.Getenv("VSPHERE_NAS_HOST") == "" {
+		t.Skip("set VSPHERE_NAS_HOST to run vsphere_datastore_cluster acceptance tests")
+	}
+	if os.Getenv("VSPHERE_NFS_PATH") == "" {
+		t.Skip("set VSPHERE_NFS_PATH to run vsphere_datastore_cluster acceptance tests")
+	}
+}
+
+func testAccResourceVSphereDatastoreClusterExists(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		pod, err := testGetDatastoreCluster(s, "datastore_cluster")
+		if err != nil {
+			if isAnyNotFoundError(err) && expected == false {
+				// Expected missing
+				return nil
+			}
+			return err
+		}
+		if !expected {
+			return fmt.Errorf("expected datastore cluster %s to be missing", pod.Reference().Value)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereDatastoreClusterHasName(expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetDatastoreClusterProperties(s, "datastore_cluster")
+		if err != nil {
+			return err
+		}
+		actual := props.Name
+		if expected != actual {
+			return fmt.Errorf("expected datastore cluster name to be %s, got %s", expected, actual)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereDatastoreClusterMatchInventoryPath(expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		pod, err := testGetDatastoreCluster(s, "datastore_cluster")
+		if err != nil {
+			return err
+		}
+
+		expected, err := rootPathParticleDatastore.PathFromNewRoot(pod.InventoryPath, rootPathParticleDatastore, expected)
+		actual := path.Dir(pod.InventoryPath)
+		if err != nil {
+			return fmt.Errorf("bad: %s", err)
+		}
+		if expected != actual {
+			return fmt.Errorf("expected path to be %s, got %s", expected, actual)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereDatastoreClusterHasSdrsEnabled(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetDatastoreClusterProperties(s, "datastore_cluster")
+		if err != nil {
+			return err
+		}
+		actual := props.PodStorageDrsEntry.StorageDrsConfig.PodConfig.Enabled
+		if expected != actual {
+			return fmt.Errorf("expected storage DRS enabled to be %t, got %t", expected, actual)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereDatastoreClusterHasSdrsAutomationLevel(expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetDatastoreClusterProperties(s, "datastore_cluster")
+		if err != nil {
+			return err
+		}
+		actual := props.PodStorageDrsEntry.StorageDrsConfig.PodConfig.DefaultVmBehavior
+		if expected != actual {
+			return fmt.Errorf("expected storage DRS automation level to be %q, got %q", expected, actual)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereDatastoreClusterHasSdrsSpaceUtilizationThreshold(expected int32) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetDatastoreClusterProperties(s, "datastore_cluster")
+		if err != nil {
+			return err
+		}
+		actual := props.PodStorageDrsEntry.StorageDrsConfig.PodConfig.SpaceLoadBalanceConfig.SpaceUtilizationThreshold
+		if expected != actual {
+			return fmt.Errorf("expected space utilization threshold to be %d, got %d", expected, actual)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereDatastoreClusterHasMembers(expected []string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetDatastoreClusterProperties(s, "datastore_cluster")
+		if err != nil {
+			return err
+		}
+		tVars, err := testClientVariablesForResource(s, "vsphere_datastore_cluster.datastore_cluster")
+		if err != nil {
+			return err
+		}
+		var actual []string
+		for _, ref := range props.ChildEntity {
+			ds, err := datastoreFromID(tVars.client, ref.Value)
+			if err != nil {
+				return err
+			}
+			actual = append(actual, ds.Name())
+		}
+		sort.Strings(expected)
+		sort.Strings(actual)
+		if !reflect.DeepEqual(expected, actual) {
+			return fmt.Errorf("expected datastore cluster members to be %#v, got %#v", expected, actual)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereDatastoreClusterCheckTags is a check to ensure that
+// any tags that have been created with the supplied resource name have been
+// attached to the datastore cluster.
+func testAccResourceVSphereDatastoreClusterCheckTags(tagResName string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		pod, err := testGetDatastoreCluster(s, "datastore_cluster")
+		if err != nil {
+			return err
+		}
+		tagsClient, err := testAccProvider.Meta().(*VSphereClient).TagsClient()
+		if err != nil {
+			return err
+		}
+		return testObjectHasTags(s, tagsClient, pod, tagResName)
+	}
+}
+
+func testAccResourceVSphereDatastoreClusterConfigBasic() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+resource "vsphere_datastore_cluster" "datastore_cluster" {
+  name          = "terraform-datastore-cluster-test"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+	)
+}
+
+func testAccResourceVSphereDatastoreClusterConfigSdrs() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+resource "vsphere_datastore_cluster" "datastore_cluster" {
+  name          = "terraform-datastore-cluster-test"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+
+  sdrs_automation_level            = "automated"
+  sdrs_default_intra_vm_affinity   = false
+  sdrs_load_balance_interval       = 120
+  sdrs_space_utilization_threshold = 70
+  sdrs_io_latency_threshold        = 20
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+	)
+}
+
+func testAccResourceVSphereDatastoreClusterConfigMembers(member bool) string {
+	var ids string
+	if member {
+		ids = `"${vsphere_nas_datastore.datastore.id}"`
+	}
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "nfs_host" {
+  default = "%s"
+}
+
+variable "nfs_path" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_host" "esxi_host" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_nas_datastore" "datastore" {
+  name            = "terraform-test-nas"
+  host_system_ids = ["${data.vsphere_host.esxi_host.id}"]
+
+  type         = "NFS"
+  remote_hosts = ["${var.nfs_host}"]
+  remote_path  = "${var.nfs_path}"
+}
+
+resource "vsphere_datastore_cluster" "datastore_cluster" {
+  name          = "terraform-datastore-cluster-test"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+  datastore_ids = [%s]
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_NAS_HOST"),
+		os.Getenv("VSPHERE_NFS_PATH"),
+		os.Getenv("VSPHERE_ESXI_HOST"),
+		ids,
+	)
+}
+
+func testAccResourceVSphereDatastoreClusterConfigRenamedInFolder() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+resource "vsphere_folder" "folder" {
+  path          = "terraform-test-datastore-cluster-folder"
+  type          = "datastore"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_datastore_cluster" "datastore_cluster" {
+  name          = "terraform-datastore-cluster-test-renamed"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+  folder        = "${vsphere_folder.folder.path}"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+	)
+}
+
+func testAccResourceVSphereDatastoreClusterConfigTags() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+resource "vsphere_tag_category" "terraform-test-category" {
+  name        = "terraform-test-tag-category"
+  cardinality = "MULTIPLE"
+
+  associable_types = [
+    "StoragePod",
+  ]
+}
+
+resource "vsphere_tag" "terraform-test-tag" {
+  name        = "terraform-test-tag"
+  category_id = "${vsphere_tag_category.terraform-test-category.id}"
+}
+
+resource "vsphere_datastore_cluster" "datastore_cluster" {
+  name          = "terraform-datastore-cluster-test"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+  tags          = ["${vsphere_tag.terraform-test-tag.id}"]
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+	)
+}
@@ -0,0 +1,252 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func resourceVSphereDrsVMOverride() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereDrsVMOverrideCreate,
+		Read:   resourceVSphereDrsVMOverrideRead,
+		Update: resourceVSphereDrsVMOverrideUpdate,
+		Delete: resourceVSphereDrsVMOverrideDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceVSphereDrsVMOverrideImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"compute_cluster_id": {
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the cluster.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"virtual_machine_uuid": {
+				Type:        schema.TypeString,
+				Description: "The UUID of the virtual machine to override settings for.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"drs_enabled": {
+				Type:         schema.TypeString,
+				Description:  "Overrides whether DRS is enabled for this virtual machine. Can be one of true or false. When not set, the cluster setting is used.",
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice([]string{"true", "false"}, false),
+			},
+			"drs_automation_level": {
+				Type:        schema.TypeString,
+				Description: "Overrides the DRS automation level for this virtual machine. Can be one of manual, partiallyAutomated, or fullyAutomated. When not set, the cluster setting is used.",
+				Optional:    true,
+				ValidateFunc: validation.StringInSlice(
+					[]string{
+						string(types.DrsBehaviorManual),
+						string(types.DrsBehaviorPartiallyAutomated),
+						string(types.DrsBehaviorFullyAutomated),
+					},
+					false,
+				),
+			},
+		},
+	}
+}
+
+func resourceVSphereDrsVMOverrideCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	cluster, vm, err := resourceVSphereDrsVMOverrideObjects(d, meta)
+	if err != nil {
+		return err
+	}
+
+	// Refuse to take over an override that already exists, as it may be
+	// managed outside of this resource.
+	existing, err := clusterDrsVMConfig(cluster, vm)
+	if err != nil {
+		return fmt.Errorf("error fetching cluster DRS configuration: %s", err)
+	}
+	if existing != nil {
+		return fmt.Errorf("a DRS override for virtual machine %q already exists in cluster %q", d.Get("virtual_machine_uuid").(string), cluster.Reference().Value)
+	}
+	if err := resourceVSphereDrsVMOverrideApply(d, cluster, vm, types.ArrayUpdateOperationAdd); err != nil {
+		return err
+	}
+
+	d.SetId(fmt.Sprintf("%s:%s", cluster.Reference().Value, d.Get("virtual_machine_uuid").(string)))
+	return resourceVSphereDrsVMOverrideRead(d, meta)
+}
+
+func resourceVSphereDrsVMOverrideRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	cluster, vm, err := resourceVSphereDrsVMOverrideObjects(d, meta)
+	if err != nil {
+		return err
+	}
+
+	info, err := clusterDrsVMConfig(cluster, vm)
+	if err != nil {
+		return fmt.Errorf("error fetching cluster DRS configuration: %s", err)
+	}
+	if info == nil {
+		log.Printf("[DEBUG] DRS override for virtual machine %q not found in cluster %q, marking as gone", d.Get("virtual_machine_uuid").(string), cluster.Reference().Value)
+		d.SetId("")
+		return nil
+	}
+
+	return flattenClusterDrsVMConfigInfo(d, info)
+}
+
+func resourceVSphereDrsVMOverrideUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	cluster, vm, err := resourceVSphereDrsVMOverrideObjects(d, meta)
+	if err != nil {
+		return err
+	}
+
+	// An edit leaves any field that is not set in the spec as it is, so if an
+	// override has been removed, the entry is removed and added again instead.
+	op := types.ArrayUpdateOperationEdit
+	if drsVMOverrideCleared(d) {
+		if err := removeClusterDrsVMConfig(cluster, vm); err != nil {
+			return err
+		}
+		op = types.ArrayUpdateOperationAdd
+	}
+	if err := resourceVSphereDrsVMOverrideApply(d, cluster, vm, op); err != nil {
+		return err
+	}
+	return resourceVSphereDrsVMOverrideRead(d, meta)
+}
+
+func resourceVSphereDrsVMOverrideDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	cluster, vm, err := resourceVSphereDrsVMOverrideObjects(d, meta)
+	if err != nil {
+		return err
+	}
+
+	return removeClusterDrsVMConfig(cluster, vm)
+}
+
+func resourceVSphereDrsVMOverrideImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	// We import with the same ID that the resource is saved with, which is the
+	// cluster ID and the virtual machine UUID, separated by a colon.
+	parts := strings.SplitN(d.Id(), ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("invalid ID %q, expected COMPUTE_CLUSTER_ID:VIRTUAL_MACHINE_UUID", d.Id())
+	}
+	d.Set("compute_cluster_id", parts[0])
+	d.Set("virtual_machine_uuid", parts[1])
+	return []*schema.ResourceData{d}, nil
+}
+
+// resourceVSphereDrsVMOverrideObjects fetches the cluster and virtual machine
+// for the resource.
+func resourceVSphereDrsVMOverrideObjects(d *schema.ResourceData, meta interface{}) (*object.ClusterComputeResource, *object.VirtualMachine, error) {
+	client := meta.(*VSphereClient).vimClient
+	cluster, err := clusterComputeResourceFromID(client, d.Get("compute_cluster_id").(string))
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot locate cluster: %s", err)
+	}
+	vm, err := virtualMachineFromUUID(client, d.Get("virtual_machine_uuid").(string))
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot locate virtual machine: %s", err)
+	}
+	return cluster, vm, nil
+}
+
+// resourceVSphereDrsVMOverrideApply applies the override in the resource to
+// the cluster, using the supplied operation.
+func resourceVSphereDrsVMOverrideApply(d *schema.ResourceData, cluster *object.ClusterComputeResource, vm *object.VirtualMachine, op types.ArrayUpdateOperation) error {
+	info, err := expandClusterDrsVMConfigInfo(d, vm)
+	if err != nil {
+		return err
+	}
+	spec := &types.ClusterConfigSpecEx{
+		DrsVmConfigSpec: []types.ClusterDrsVmConfigSpec{
+			{
+				ArrayUpdateSpec: types.ArrayUpdateSpec{
+					Operation: op,
+				},
+				Info: info,
+			},
+		},
+	}
+	if err := reconfigureClusterComputeResource(cluster, spec); err != nil {
+		return fmt.Errorf("error applying DRS override: %s", err)
+	}
+	return nil
+}
+
+// removeClusterDrsVMConfig removes the DRS configuration entry for a virtual
+// machine from a cluster.
+func removeClusterDrsVMConfig(cluster *object.ClusterComputeResource, vm *object.VirtualMachine) error {
+	spec := &types.ClusterConfigSpecEx{
+		DrsVmConfigSpec: []types.ClusterDrsVmConfigSpec{
+			{
+				ArrayUpdateSpec: types.ArrayUpdateSpec{
+					Operation: types.ArrayUpdateOperationRemove,
+					RemoveKey: vm.Reference(),
+				},
+			},
+		},
+	}
+	if err := reconfigureClusterComputeResource(cluster, spec); err != nil {
+		return fmt.Errorf("error removing DRS override: %s", err)
+	}
+	return nil
+}
+
+// drsVMOverrideCleared returns true if any override that was set in the
+// resource has been removed.
+func drsVMOverrideCleared(d *schema.ResourceData) bool {
+	for _, k := range []string{"drs_enabled", "drs_automation_level"} {
+		o, n := d.GetChange(k)
+		if o.(string) != "" && n.(string) == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// expandClusterDrsVMConfigInfo reads the resource and returns a
+// ClusterDrsVmConfigInfo for the virtual machine.
+func expandClusterDrsVMConfigInfo(d *schema.ResourceData, vm *object.VirtualMachine) (*types.ClusterDrsVmConfigInfo, error) {
+	enabled, err := getBoolStringPtr(d, "drs_enabled")
+	if err != nil {
+		return nil, err
+	}
+	obj := &types.ClusterDrsVmConfigInfo{
+		Key:      vm.Reference(),
+		Enabled:  enabled,
+		Behavior: types.DrsBehavior(d.Get("drs_automation_level").(string)),
+	}
+	return obj, nil
+}
+
+// flattenClusterDrsVMConfigInfo reads various fields from a
+// ClusterDrsVmConfigInfo into the passed in ResourceData.
+func flattenClusterDrsVMConfigInfo(d *schema.ResourceData, obj *types.ClusterDrsVmConfigInfo) error {
+	if err := setBoolStringPtr(d, "drs_enabled", obj.Enabled); err != nil {
+		return err
+	}
+	d.Set("drs_automation_level", obj.Behavior)
+	return nil
+}
@@ -0,0 +1,157 @@
+package vsphere
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestAccResourceVSphereDrsVMOverride(t *testing.T) {
+	var tp *testing.T
+	testAccResourceVSphereDrsVMOverrideCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"automation level",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccComputeClusterMembersPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereDrsVMOverrideExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereDrsVMOverrideConfig(`drs_automation_level = "fullyAutomated"`),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDrsVMOverrideExists(true),
+							testAccResourceVSphereDrsVMOverrideMatch(func(info *types.ClusterDrsVmConfigInfo) error {
+								if info.Behavior != types.DrsBehaviorFullyAutomated {
+									return fmt.Errorf("expected automation level to be fullyAutomated, got %q", info.Behavior)
+								}
+								return nil
+							}),
+						),
+					},
+					{
+						Config: testAccResourceVSphereDrsVMOverrideConfig(`drs_enabled = "false"`),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDrsVMOverrideExists(true),
+							testAccResourceVSphereDrsVMOverrideMatch(func(info *types.ClusterDrsVmConfigInfo) error {
+								if info.Enabled == nil || *info.Enabled {
+									return errors.New("expected DRS to be disabled for virtual machine")
+								}
+								if info.Behavior != "" {
+									return fmt.Errorf("expected automation level override to be removed, got %q", info.Behavior)
+								}
+								return nil
+							}),
+						),
+					},
+				},
+			},
+		},
+		{
+			"import",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccComputeClusterMembersPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereDrsVMOverrideExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereDrsVMOverrideConfig(`drs_enabled = "false"`),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDrsVMOverrideExists(true),
+						),
+					},
+					{
+						ResourceName:      "vsphere_drs_vm_override.override",
+						ImportState:       true,
+						ImportStateVerify: true,
+						Config:            testAccResourceVSphereDrsVMOverrideConfig(`drs_enabled = "false"`),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccResourceVSphereDrsVMOverrideCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+// testAccResourceVSphereDrsVMOverrideGetConfig fetches the DRS override for
+// the first virtual machine in the test cluster. nil is returned if there is
+// no override.
+func testAccResourceVSphereDrsVMOverrideGetConfig(s *terraform.State) (*types.ClusterDrsVmConfigInfo, error) {
+	cluster, err := testGetComputeCluster(s, "compute_cluster")
+	if err != nil {
+		return nil, err
+	}
+	tVars, err := testClientVariablesForResource(s, "vsphere_virtual_machine.vm.0")
+	if err != nil {
+		return nil, err
+	}
+	vm, err := virtualMachineFromUUID(tVars.client, tVars.resourceAttributes["uuid"])
+	if err != nil {
+		return nil, err
+	}
+	return clusterDrsVMConfig(cluster, vm)
+}
+
+func testAccResourceVSphereDrsVMOverrideExists(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		info, err := testAccResourceVSphereDrsVMOverrideGetConfig(s)
+		if err != nil {
+			if isAnyNotFoundError(err) && expected == false {
+				// Expected missing
+				return nil
+			}
+			return err
+		}
+		exists := info != nil
+		if exists != expected {
+			return fmt.Errorf("expected DRS override to exist: %t, got %t", expected, exists)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereDrsVMOverrideMatch(f func(*types.ClusterDrsVmConfigInfo) error) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		info, err := testAccResourceVSphereDrsVMOverrideGetConfig(s)
+		if err != nil {
+			return err
+		}
+		if info == nil {
+			return errors.New("DRS override not found")
+		}
+		return f(info)
+	}
+}
+
+func testAccResourceVSphereDrsVMOverrideConfig(extra string) string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_drs_vm_override" "override" {
+  compute_cluster_id   = "${vsphere_compute_cluster.compute_cluster.id}"
+  virtual_machine_uuid = "${vsphere_virtual_machine.vm.0.uuid}"
+  %s
+}
+`,
+		testAccComputeClusterMembersConfig(),
+		extra,
+	)
+}
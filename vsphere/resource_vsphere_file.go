@@ -1,11 +1,19 @@
 package vsphere
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"log"
+	"os"
+	"path"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/find"
 	"github.com/vmware/govmomi/object"
@@ -22,6 +30,10 @@ type file struct {
 	destinationFile   string
 	createDirectories bool
 	copyFile          bool
+	sourceDirectory   string
+	includePatterns   []string
+	excludePatterns   []string
+	uploadConcurrency int
 }
 
 func resourceVSphereFile() *schema.Resource {
@@ -31,6 +43,9 @@ func resourceVSphereFile() *schema.Resource {
 		Update: resourceVSphereFileUpdate,
 		Delete: resourceVSphereFileDelete,
 
+		SchemaVersion: 2,
+		MigrateState:  resourceVSphereFileMigrateState,
+
 		Schema: map[string]*schema.Schema{
 			"datacenter": {
 				Type:     schema.TypeString,
@@ -55,9 +70,36 @@ func resourceVSphereFile() *schema.Resource {
 			},
 
 			"source_file": {
-				Type:     schema.TypeString,
-				Required: true,
-				ForceNew: true,
+				Type:          schema.TypeString,
+				Optional:      true,
+				ForceNew:      true,
+				ConflictsWith: []string{"source_directory"},
+			},
+
+			// In directory mode destination_file is the destination folder.
+			"source_directory": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"source_file", "source_datacenter", "source_datastore"},
+			},
+
+			"include_patterns": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
+			"exclude_patterns": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
+			"upload_concurrency": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      4,
+				ValidateFunc: validation.IntBetween(1, 16),
 			},
 
 			"destination_file": {
@@ -69,6 +111,55 @@ func resourceVSphereFile() *schema.Resource {
 				Type:     schema.TypeBool,
 				Optional: true,
 			},
+
+			// source_sha256 holds the checksum of the file on the datastore as
+			// last seen by Terraform, or of the manifest in directory mode. It
+			// cannot be set in configuration: it is compared with the checksum of
+			// the local source when planning, so that changes on either side show
+			// up as a diff, and the update uploads the file or directory again.
+			// It is Optional rather than Computed only because a Computed
+			// attribute never shows up as a diff.
+			"source_sha256": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateFunc:     validateNotConfigurable,
+				DiffSuppressFunc: suppressFileSourceSHA256Diff,
+			},
+
+			"size": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+
+			"modification_time": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"manifest": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"path": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"sha256": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"size": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"modification_time": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
 		},
 	}
 }
@@ -102,8 +193,6 @@ func resourceVSphereFileCreate(d *schema.ResourceData, meta interface{}) error {
 
 	if v, ok := d.GetOk("source_file"); ok {
 		f.sourceFile = v.(string)
-	} else {
-		return fmt.Errorf("source_file argument is required")
 	}
 
 	if v, ok := d.GetOk("destination_file"); ok {
@@ -116,12 +205,53 @@ func resourceVSphereFileCreate(d *schema.ResourceData, meta interface{}) error {
 		f.createDirectories = v.(bool)
 	}
 
+	if _, ok := d.GetOk("source_directory"); ok {
+		expandFileDirectory(d, &f)
+		manifest, err := syncFileDirectory(client, &f, nil)
+		if err != nil {
+			return err
+		}
+		id, err := fileResourceID(client, f.datacenter, f.datastore, f.destinationFile)
+		if err != nil {
+			return err
+		}
+		d.SetId(id)
+		if err := d.Set("manifest", flattenFileManifest(manifest)); err != nil {
+			return fmt.Errorf("error setting manifest: %s", err)
+		}
+		log.Printf("[INFO] Uploaded directory %s to %s", f.sourceDirectory, f.destinationFile)
+		return resourceVSphereFileRead(d, meta)
+	}
+
+	if f.sourceFile == "" {
+		return fmt.Errorf("one of source_file or source_directory must be set")
+	}
+
+	// Hash the local file before uploading it, so that the hash describes the
+	// uploaded content.
+	var sum string
+	if !f.copyFile {
+		var err error
+		if sum, err = fileSHA256(f.sourceFile); err != nil {
+			return fmt.Errorf("error hashing %s: %s", f.sourceFile, err)
+		}
+	}
+
 	err := createFile(client, &f)
 	if err != nil {
 		return err
 	}
 
-	d.SetId(fmt.Sprintf("[%v] %v/%v", f.datastore, f.datacenter, f.destinationFile))
+	id, err := fileResourceID(client, f.datacenter, f.datastore, f.destinationFile)
+	if err != nil {
+		return err
+	}
+	d.SetId(id)
+	d.Set("source_sha256", sum)
+	// Clear any remote attributes from a previous upload so that Read does not
+	// treat the new upload as drift.
+	d.Set("size", 0)
+	d.Set("modification_time", "")
 	log.Printf("[INFO] Created file: %s", f.destinationFile)
 
 	return resourceVSphereFileRead(d, meta)
@@ -217,8 +347,6 @@ func resourceVSphereFileRead(d *schema.ResourceData, meta interface{}) error {
 
 	if v, ok := d.GetOk("source_file"); ok {
 		f.sourceFile = v.(string)
-	} else {
-		return fmt.Errorf("source_file argument is required")
 	}
 
 	if v, ok := d.GetOk("destination_file"); ok {
@@ -240,17 +368,44 @@ func resourceVSphereFileRead(d *schema.ResourceData, meta interface{}) error {
 	if err != nil {
 		return fmt.Errorf("error %s", err)
 	}
+	// This also replaces IDs in the old format, which are left alone by the
+	// state migration.
+	d.SetId(fileResourceIDFromObjects(dc, ds, f.destinationFile))
+
+	if _, ok := d.GetOk("source_directory"); ok {
+		return readFileDirectory(d, ds, &f)
+	}
 
-	_, err = ds.Stat(context.TODO(), f.destinationFile)
+	info, err := ds.Stat(context.TODO(), f.destinationFile)
 	if err != nil {
 		log.Printf("[DEBUG] resourceVSphereFileRead - stat failed on: %v", f.destinationFile)
-		d.SetId("")
+		switch err.(type) {
+		case object.DatastoreNoSuchFileError, object.DatastoreNoSuchDirectoryError:
+			d.SetId("")
+			return nil
+		}
+		return err
+	}
 
-		_, ok := err.(object.DatastoreNoSuchFileError)
-		if !ok {
-			return err
+	// If the file has been changed outside of Terraform, record the checksum of
+	// what is now on the datastore. The plan then shows source_sha256 changing,
+	// which replaces the file.
+	fileInfo := info.GetFileInfo()
+	var mtime string
+	if fileInfo.Modification != nil {
+		mtime = fileInfo.Modification.Format(time.RFC3339)
+	}
+	oldSize, oldMtime := d.Get("size").(int), d.Get("modification_time").(string)
+	if oldMtime != "" && (oldSize != int(fileInfo.FileSize) || oldMtime != mtime) {
+		log.Printf("[DEBUG] resourceVSphereFileRead - %s has changed on the datastore", f.destinationFile)
+		sum, err := datastoreFileSHA256(ds, f.destinationFile)
+		if err != nil {
+			return fmt.Errorf("error hashing %s on the datastore: %s", f.destinationFile, err)
 		}
+		d.Set("source_sha256", sum)
 	}
+	d.Set("size", fileInfo.FileSize)
+	d.Set("modification_time", mtime)
 
 	return nil
 }
@@ -321,9 +476,67 @@ func resourceVSphereFileUpdate(d *schema.ResourceData, meta interface{}) error {
 		if err != nil {
 			return err
 		}
+
+		id, err := fileResourceID(client, newDatacenter, newDatastore, newDestinationFile)
+		if err != nil {
+			return err
+		}
+		d.SetId(id)
+		// Moving the file can change its modification time, so the remote
+		// attributes are read again rather than checked for drift.
+		d.Set("size", 0)
+		d.Set("modification_time", "")
 	}
 
-	return nil
+	if _, ok := d.GetOk("source_directory"); !ok && d.HasChange("source_sha256") {
+		// The file has changed locally or on the datastore, so upload or copy it
+		// again.
+		client := meta.(*VSphereClient).vimClient
+		f := file{
+			sourceDatacenter: d.Get("source_datacenter").(string),
+			datacenter:       d.Get("datacenter").(string),
+			sourceDatastore:  d.Get("source_datastore").(string),
+			datastore:        d.Get("datastore").(string),
+			sourceFile:       d.Get("source_file").(string),
+			destinationFile:  d.Get("destination_file").(string),
+		}
+		f.copyFile = f.sourceDatacenter != "" || f.sourceDatastore != ""
+		var sum string
+		if !f.copyFile {
+			var err error
+			if sum, err = fileSHA256(f.sourceFile); err != nil {
+				return fmt.Errorf("error hashing %s: %s", f.sourceFile, err)
+			}
+		}
+		if err := createFile(client, &f); err != nil {
+			return err
+		}
+		d.Set("source_sha256", sum)
+		d.Set("size", 0)
+		d.Set("modification_time", "")
+		log.Printf("[INFO] Uploaded file again: %s", f.destinationFile)
+	}
+
+	if _, ok := d.GetOk("source_directory"); ok {
+		if d.HasChange("source_directory") || d.HasChange("include_patterns") || d.HasChange("exclude_patterns") || d.HasChange("source_sha256") {
+			client := meta.(*VSphereClient).vimClient
+			f := file{
+				datacenter:      d.Get("datacenter").(string),
+				datastore:       d.Get("datastore").(string),
+				destinationFile: d.Get("destination_file").(string),
+			}
+			expandFileDirectory(d, &f)
+			manifest, err := syncFileDirectory(client, &f, expandFileManifest(d.Get("manifest").([]interface{})))
+			if err != nil {
+				return err
+			}
+			if err := d.Set("manifest", flattenFileManifest(manifest)); err != nil {
+				return fmt.Errorf("error setting manifest: %s", err)
+			}
+		}
+	}
+
+	return resourceVSphereFileRead(d, meta)
 }
 
 func resourceVSphereFileDelete(d *schema.ResourceData, meta interface{}) error {
@@ -343,8 +556,6 @@ func resourceVSphereFileDelete(d *schema.ResourceData, meta interface{}) error {
 
 	if v, ok := d.GetOk("source_file"); ok {
 		f.sourceFile = v.(string)
-	} else {
-		return fmt.Errorf("source_file argument is required")
 	}
 
 	if v, ok := d.GetOk("destination_file"); ok {
@@ -355,6 +566,27 @@ func resourceVSphereFileDelete(d *schema.ResourceData, meta interface{}) error {
 
 	client := meta.(*VSphereClient).vimClient
 
+	if _, ok := d.GetOk("source_directory"); ok {
+		// Only remove the files that were uploaded, and the destination folder
+		// if nothing else is left in it.
+		dc, err := getDatacenter(client, f.datacenter)
+		if err != nil {
+			return err
+		}
+		finder := find.NewFinder(client.Client, true)
+		finder = finder.SetDatacenter(dc)
+		ds, err := getDatastore(finder, f.datastore)
+		if err != nil {
+			return fmt.Errorf("error %s", err)
+		}
+		manifest := expandFileManifest(d.Get("manifest").([]interface{}))
+		if err := deleteDirectoryFromDatastore(client, dc, ds, f.destinationFile, manifest); err != nil {
+			return err
+		}
+		d.SetId("")
+		return nil
+	}
+
 	err := deleteFile(client, &f)
 	if err != nil {
 		return err
@@ -403,3 +635,205 @@ func getDatastore(f *find.Finder, ds string) (*object.Datastore, error) {
 		return dso, err
 	}
 }
+
+// fileResourceID returns the ID of a vsphere_file resource, made up of the
+// managed object IDs of the datacenter and datastore the file is in, and the
+// path of the file in the datastore, separated by slashes.
+func fileResourceID(client *govmomi.Client, datacenter, datastore, path string) (string, error) {
+	dc, err := getDatacenter(client, datacenter)
+	if err != nil {
+		return "", fmt.Errorf("error finding datacenter: %s", err)
+	}
+	finder := find.NewFinder(client.Client, true)
+	finder = finder.SetDatacenter(dc)
+	ds, err := getDatastore(finder, datastore)
+	if err != nil {
+		return "", fmt.Errorf("error finding datastore: %s", err)
+	}
+	return fileResourceIDFromObjects(dc, ds, path), nil
+}
+
+// fileResourceIDFromObjects returns the ID of a vsphere_file resource for a
+// datacenter and datastore that have already been looked up.
+func fileResourceIDFromObjects(dc *object.Datacenter, ds *object.Datastore, path string) string {
+	return fmt.Sprintf("%s/%s/%s", dc.Reference().Value, ds.Reference().Value, path)
+}
+
+// fileSHA256 returns the hex-encoded SHA256 checksum of a local file.
+func fileSHA256(name string) (string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// datastoreFileSHA256 returns the hex-encoded SHA256 checksum of a file on a
+// datastore, by downloading it.
+func datastoreFileSHA256(ds *object.Datastore, name string) (string, error) {
+	p := soap.DefaultDownload
+	r, _, err := ds.Download(context.TODO(), name, &p)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// suppressFileSourceSHA256Diff suppresses the diff on source_sha256 unless the
+// checksum in state no longer matches the file or directory that would be
+// uploaded. When the file is copied within vSphere, there is no source
+// checksum, and any checksum in state means the copy was changed outside of
+// Terraform.
+func suppressFileSourceSHA256Diff(k, old, new string, d *schema.ResourceData) bool {
+	if old == "" {
+		return true
+	}
+	if _, ok := d.GetOk("source_directory"); ok {
+		f := file{}
+		expandFileDirectory(d, &f)
+		local, err := localDirectoryManifest(f.sourceDirectory, f.includePatterns, f.excludePatterns)
+		if err != nil {
+			log.Printf("[WARN] could not read %s: %s", f.sourceDirectory, err)
+			return true
+		}
+		return old == fileManifestSHA256(local)
+	}
+	if d.Get("source_datacenter").(string) != "" || d.Get("source_datastore").(string) != "" {
+		return false
+	}
+	sum, err := fileSHA256(d.Get("source_file").(string))
+	if err != nil {
+		// A missing local file is left to fail at upload time.
+		log.Printf("[WARN] could not hash %s: %s", d.Get("source_file").(string), err)
+		return true
+	}
+	return old == sum
+}
+
+// expandFileDirectory reads the directory mode arguments into a file.
+func expandFileDirectory(d *schema.ResourceData, f *file) {
+	f.sourceDirectory = d.Get("source_directory").(string)
+	for _, v := range d.Get("include_patterns").([]interface{}) {
+		f.includePatterns = append(f.includePatterns, v.(string))
+	}
+	for _, v := range d.Get("exclude_patterns").([]interface{}) {
+		f.excludePatterns = append(f.excludePatterns, v.(string))
+	}
+	f.uploadConcurrency = d.Get("upload_concurrency").(int)
+}
+
+// expandFileManifest converts a manifest from ResourceData into a map of the
+// SHA256 checksums of the files, keyed by their path.
+func expandFileManifest(l []interface{}) map[string]string {
+	manifest := make(map[string]string)
+	for _, v := range l {
+		entry := v.(map[string]interface{})
+		manifest[entry["path"].(string)] = entry["sha256"].(string)
+	}
+	return manifest
+}
+
+// flattenFileManifest converts a map of SHA256 checksums keyed by path into a
+// manifest for ResourceData, sorted by path. The size and modification time of
+// the files are left empty, and are filled in on the next read.
+func flattenFileManifest(manifest map[string]string) []interface{} {
+	var paths []string
+	for rel := range manifest {
+		paths = append(paths, rel)
+	}
+	sort.Strings(paths)
+	var l []interface{}
+	for _, rel := range paths {
+		l = append(l, map[string]interface{}{
+			"path":              rel,
+			"sha256":            manifest[rel],
+			"size":              0,
+			"modification_time": "",
+		})
+	}
+	return l
+}
+
+// syncFileDirectory mirrors the source directory of a file onto its
+// destination folder, given the manifest of the previous upload. The new
+// manifest is returned.
+func syncFileDirectory(client *govmomi.Client, f *file, previous map[string]string) (map[string]string, error) {
+	local, err := localDirectoryManifest(f.sourceDirectory, f.includePatterns, f.excludePatterns)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %s", f.sourceDirectory, err)
+	}
+	dc, err := getDatacenter(client, f.datacenter)
+	if err != nil {
+		return nil, fmt.Errorf("error %s", err)
+	}
+	finder := find.NewFinder(client.Client, true)
+	finder = finder.SetDatacenter(dc)
+	ds, err := getDatastore(finder, f.datastore)
+	if err != nil {
+		return nil, fmt.Errorf("error %s", err)
+	}
+	if err := syncDirectoryToDatastore(client, dc, ds, f.sourceDirectory, f.destinationFile, local, previous, f.uploadConcurrency); err != nil {
+		return nil, err
+	}
+	return local, nil
+}
+
+// readFileDirectory reads a directory mode file. The resource is re-created if
+// the destination folder is missing. Files in the manifest that have been
+// removed from the datastore are dropped from it, and files whose size or
+// modification time has changed are downloaded to record their new checksum.
+// source_sha256 is then set to the checksum of the manifest, which is compared
+// with the local directory when planning, so that the files are uploaded again.
+func readFileDirectory(d *schema.ResourceData, ds *object.Datastore, f *file) error {
+	files, err := datastoreDirectoryFiles(ds, f.destinationFile)
+	if err != nil {
+		if isFileNotFoundError(err) {
+			log.Printf("[DEBUG] resourceVSphereFileRead - folder not found: %v", f.destinationFile)
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("error listing %s on the datastore: %s", f.destinationFile, err)
+	}
+
+	var manifest []interface{}
+	for _, v := range d.Get("manifest").([]interface{}) {
+		entry := v.(map[string]interface{})
+		rel := entry["path"].(string)
+		info, ok := files[rel]
+		if !ok {
+			log.Printf("[DEBUG] resourceVSphereFileRead - %s has been removed from the datastore", rel)
+			continue
+		}
+		var mtime string
+		if info.Modification != nil {
+			mtime = info.Modification.Format(time.RFC3339)
+		}
+		oldSize, oldMtime := entry["size"].(int), entry["modification_time"].(string)
+		if oldMtime != "" && (oldSize != int(info.FileSize) || oldMtime != mtime) {
+			log.Printf("[DEBUG] resourceVSphereFileRead - %s has changed on the datastore", rel)
+			sum, err := datastoreFileSHA256(ds, path.Join(f.destinationFile, rel))
+			if err != nil {
+				return fmt.Errorf("error hashing %s on the datastore: %s", rel, err)
+			}
+			entry["sha256"] = sum
+		}
+		entry["size"] = int(info.FileSize)
+		entry["modification_time"] = mtime
+		manifest = append(manifest, entry)
+	}
+	if err := d.Set("manifest", manifest); err != nil {
+		return fmt.Errorf("error setting manifest: %s", err)
+	}
+	d.Set("source_sha256", fileManifestSHA256(expandFileManifest(manifest)))
+	return nil
+}
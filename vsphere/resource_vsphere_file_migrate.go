@@ -0,0 +1,96 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform/terraform"
+)
+
+// resourceVSphereFileMigrateState is the master state migration function for
+// the vsphere_file resource.
+func resourceVSphereFileMigrateState(version int, os *terraform.InstanceState, meta interface{}) (*terraform.InstanceState, error) {
+	// Guard against a nil state.
+	if os == nil {
+		return nil, nil
+	}
+
+	// Guard against empty state, can't do anything with it
+	if os.Empty() {
+		return os, nil
+	}
+
+	var migrateFunc func(*terraform.InstanceState, interface{}) error
+	switch version {
+	case 0:
+		log.Printf("[DEBUG] Migrating vsphere_file state: old v%d state: %#v", version, os)
+		migrateFunc = resourceVSphereFileMigrateStateV1
+	case 1:
+		log.Printf("[DEBUG] Migrating vsphere_file state: old v%d state: %#v", version, os)
+		migrateFunc = resourceVSphereFileMigrateStateV2
+	default:
+		// Migration is complete
+		log.Printf("[DEBUG] Migrating vsphere_file state: completed v%d state: %#v", version, os)
+		return os, nil
+	}
+	if err := migrateFunc(os, meta); err != nil {
+		return nil, err
+	}
+	version++
+	log.Printf("[DEBUG] Migrating vsphere_file state: new v%d state: %#v", version, os)
+	return resourceVSphereFileMigrateState(version, os, meta)
+}
+
+// resourceVSphereFileMigrateStateV1 migrates the state of the vsphere_file
+// from version 0 to version 1.
+func resourceVSphereFileMigrateStateV1(s *terraform.InstanceState, meta interface{}) error {
+	// The old ID was in the format "[datastore] datacenter/path", which is
+	// ambiguous when the datacenter is in a folder or was not specified. The new
+	// ID is made of the datacenter and datastore managed object IDs and the
+	// path, which can only be looked up with a connection to vSphere. The old ID
+	// is kept here so that the migration works offline, and is replaced on the
+	// next read.
+	//
+	// The new computed attributes are left empty, and are populated on the next
+	// read without being treated as drift.
+	return nil
+}
+
+// resourceVSphereFileMigrateStateV2 migrates the state of the vsphere_file
+// from version 1 to version 2.
+func resourceVSphereFileMigrateStateV2(s *terraform.InstanceState, meta interface{}) error {
+	// The manifest was a map of checksums keyed by the path of each file, and is
+	// now a list of objects, sorted by path. The size and modification time of
+	// the files are left empty, and are populated on the next read without
+	// being treated as drift.
+	manifest := make(map[string]string)
+	for k, v := range s.Attributes {
+		if !strings.HasPrefix(k, "manifest.") {
+			continue
+		}
+		if k != "manifest.%" {
+			manifest[strings.TrimPrefix(k, "manifest.")] = v
+		}
+		delete(s.Attributes, k)
+	}
+	if len(manifest) < 1 {
+		return nil
+	}
+	var paths []string
+	for rel := range manifest {
+		paths = append(paths, rel)
+	}
+	sort.Strings(paths)
+	s.Attributes["manifest.#"] = strconv.Itoa(len(paths))
+	for i, rel := range paths {
+		prefix := fmt.Sprintf("manifest.%d.", i)
+		s.Attributes[prefix+"path"] = rel
+		s.Attributes[prefix+"sha256"] = manifest[rel]
+		s.Attributes[prefix+"size"] = "0"
+		s.Attributes[prefix+"modification_time"] = ""
+	}
+	return nil
+}
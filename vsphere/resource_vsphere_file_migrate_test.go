@@ -0,0 +1,84 @@
+package vsphere
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestResourceVSphereFileMigrateState(t *testing.T) {
+	// The migration must work without a connection to vSphere, so no provider
+	// metadata is supplied. The old ID is replaced on the next read.
+	id := "[datastore1] dc1/tf_file_test.vmdk"
+	is := &terraform.InstanceState{
+		ID: id,
+		Attributes: map[string]string{
+			"datacenter":       "dc1",
+			"datastore":        "datastore1",
+			"destination_file": "tf_file_test.vmdk",
+		},
+	}
+	is, err := resourceVSphereFileMigrateState(0, is, nil)
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	if is.ID != id {
+		t.Fatalf("expected ID to be %q, got %q", id, is.ID)
+	}
+	if is.Attributes["destination_file"] != "tf_file_test.vmdk" {
+		t.Fatalf("expected destination_file to be kept, got %q", is.Attributes["destination_file"])
+	}
+}
+
+func TestResourceVSphereFileMigrateStateV2(t *testing.T) {
+	is := &terraform.InstanceState{
+		ID: "datacenter-21/datastore-12/tf_file_test_dir",
+		Attributes: map[string]string{
+			"destination_file":     "tf_file_test_dir",
+			"manifest.%":           "2",
+			"manifest.ks/base.cfg": "aaaa",
+			"manifest.isos/c7.iso": "bbbb",
+		},
+	}
+	is, err := resourceVSphereFileMigrateState(1, is, nil)
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	expected := map[string]string{
+		"destination_file":             "tf_file_test_dir",
+		"manifest.#":                   "2",
+		"manifest.0.path":              "isos/c7.iso",
+		"manifest.0.sha256":            "bbbb",
+		"manifest.0.size":              "0",
+		"manifest.0.modification_time": "",
+		"manifest.1.path":              "ks/base.cfg",
+		"manifest.1.sha256":            "aaaa",
+		"manifest.1.size":              "0",
+		"manifest.1.modification_time": "",
+	}
+	if !reflect.DeepEqual(expected, is.Attributes) {
+		t.Fatalf("expected %#v, got %#v", expected, is.Attributes)
+	}
+}
+
+func TestResourceVSphereFileMigrateState_empty(t *testing.T) {
+	var is *terraform.InstanceState
+	var meta interface{}
+
+	// should handle nil
+	is, err := resourceVSphereFileMigrateState(0, is, meta)
+	if err != nil {
+		t.Fatalf("err: %#v", err)
+	}
+	if is != nil {
+		t.Fatalf("expected nil instancestate, got: %#v", is)
+	}
+
+	// should handle non-nil but empty
+	is = &terraform.InstanceState{}
+	_, err = resourceVSphereFileMigrateState(0, is, meta)
+	if err != nil {
+		t.Fatalf("err: %#v", err)
+	}
+}
@@ -1,9 +1,12 @@
 package vsphere
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io/ioutil"
 	"os"
+	"regexp"
 	"testing"
 
 	"github.com/hashicorp/terraform/helper/resource"
@@ -243,6 +246,285 @@ func TestAccVSphereFile_uploadAndCopyAndUpdate(t *testing.T) {
 	os.Remove(sourceFile)
 }
 
+// file upload, then the local file is changed and uploaded again, then the
+// file is changed on the datastore and uploaded again
+func TestAccVSphereFile_uploadChangedContent(t *testing.T) {
+	testVmdkFile := "/tmp/tf_test.vmdk"
+	datacenter := os.Getenv("VSPHERE_DATACENTER")
+	datastore := os.Getenv("VSPHERE_DATASTORE")
+	testMethod := "changed"
+	resourceName := "vsphere_file." + testMethod
+	destinationFile := "tf_file_test.vmdk"
+	config := fmt.Sprintf(
+		testAccCheckVSphereFileConfig,
+		testMethod,
+		datacenter,
+		datastore,
+		testVmdkFile,
+		destinationFile,
+	)
+
+	err := ioutil.WriteFile(testVmdkFile, []byte("# Disk DescriptorFile\n"), 0644)
+	if err != nil {
+		t.Errorf("error %s", err)
+		return
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckVSphereFileDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVSphereFileExists(resourceName, destinationFile, true),
+					resource.TestCheckResourceAttr(resourceName, "source_sha256", testAccVSphereFileSHA256("# Disk DescriptorFile\n")),
+					resource.TestCheckResourceAttr(resourceName, "size", "22"),
+				),
+			},
+			{
+				PreConfig: func() {
+					if err := ioutil.WriteFile(testVmdkFile, []byte("# Disk DescriptorFile (changed)\n"), 0644); err != nil {
+						t.Fatalf("error %s", err)
+					}
+				},
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVSphereFileExists(resourceName, destinationFile, true),
+					resource.TestCheckResourceAttr(resourceName, "source_sha256", testAccVSphereFileSHA256("# Disk DescriptorFile (changed)\n")),
+					resource.TestCheckResourceAttr(resourceName, "size", "32"),
+				),
+			},
+			{
+				PreConfig: func() {
+					if err := testAccVSphereFileUploadContent(datacenter, datastore, destinationFile, "# Changed on the datastore\n"); err != nil {
+						t.Fatalf("error %s", err)
+					}
+				},
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVSphereFileExists(resourceName, destinationFile, true),
+					resource.TestCheckResourceAttr(resourceName, "source_sha256", testAccVSphereFileSHA256("# Disk DescriptorFile (changed)\n")),
+					resource.TestCheckResourceAttr(resourceName, "size", "32"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(
+					testAccCheckVSphereFileSHA256Config,
+					testMethod,
+					datacenter,
+					datastore,
+					testVmdkFile,
+					destinationFile,
+				),
+				ExpectError: regexp.MustCompile("cannot be set in configuration"),
+			},
+		},
+	})
+	os.Remove(testVmdkFile)
+}
+
+// testAccVSphereFileUploadContent replaces a file on a datastore outside of
+// Terraform.
+func testAccVSphereFileUploadContent(datacenter, datastore, path, content string) error {
+	src, err := ioutil.TempFile("", "tf-vsphere-file")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(src.Name())
+	if _, err := src.WriteString(content); err != nil {
+		return err
+	}
+	if err := src.Close(); err != nil {
+		return err
+	}
+
+	client := testAccProvider.Meta().(*VSphereClient).vimClient
+	dc, err := getDatacenter(client, datacenter)
+	if err != nil {
+		return err
+	}
+	finder := find.NewFinder(client.Client, true)
+	finder = finder.SetDatacenter(dc)
+	ds, err := getDatastore(finder, datastore)
+	if err != nil {
+		return err
+	}
+	return uploadFileToDatastore(client, dc, ds, src.Name(), path)
+}
+
+// directory upload, then a local file is removed and another is changed
+func TestAccVSphereFile_uploadDirectory(t *testing.T) {
+	sourceDirectory, err := ioutil.TempDir("", "tf-vsphere-file")
+	if err != nil {
+		t.Errorf("error %s", err)
+		return
+	}
+	defer os.RemoveAll(sourceDirectory)
+	if err := os.MkdirAll(sourceDirectory+"/ks", 0755); err != nil {
+		t.Errorf("error %s", err)
+		return
+	}
+	for _, name := range []string{"ks/base.cfg", "ks/extra.cfg", "notes.txt"} {
+		if err := ioutil.WriteFile(sourceDirectory+"/"+name, []byte(name+"\n"), 0644); err != nil {
+			t.Errorf("error %s", err)
+			return
+		}
+	}
+
+	datacenter := os.Getenv("VSPHERE_DATACENTER")
+	datastore := os.Getenv("VSPHERE_DATASTORE")
+	resourceName := "vsphere_file.directory"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckVSphereFileDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckVSphereFileDirectoryConfig(datacenter, datastore, sourceDirectory),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVSphereFileExists(resourceName, "tf_file_test_dir/ks/base.cfg", true),
+					testAccCheckVSphereFileExists(resourceName, "tf_file_test_dir/ks/extra.cfg", true),
+					testAccCheckVSphereFileExists(resourceName, "tf_file_test_dir/notes.txt", false),
+					resource.TestCheckResourceAttr(resourceName, "manifest.#", "2"),
+				),
+			},
+			{
+				PreConfig: func() {
+					if err := os.Remove(sourceDirectory + "/ks/extra.cfg"); err != nil {
+						t.Fatalf("error %s", err)
+					}
+					if err := ioutil.WriteFile(sourceDirectory+"/ks/base.cfg", []byte("changed\n"), 0644); err != nil {
+						t.Fatalf("error %s", err)
+					}
+				},
+				Config: testAccCheckVSphereFileDirectoryConfig(datacenter, datastore, sourceDirectory),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVSphereFileExists(resourceName, "tf_file_test_dir/ks/base.cfg", true),
+					testAccCheckVSphereFileExists(resourceName, "tf_file_test_dir/ks/extra.cfg", false),
+					resource.TestCheckResourceAttr(resourceName, "manifest.#", "1"),
+					resource.TestCheckResourceAttr(resourceName, "manifest.0.path", "ks/base.cfg"),
+					resource.TestCheckResourceAttr(resourceName, "manifest.0.sha256", testAccVSphereFileSHA256("changed\n")),
+				),
+			},
+			{
+				PreConfig: func() {
+					if err := testAccVSphereFileDeleteRemote(datacenter, datastore, "tf_file_test_dir/ks/base.cfg"); err != nil {
+						t.Fatalf("error %s", err)
+					}
+				},
+				Config: testAccCheckVSphereFileDirectoryConfig(datacenter, datastore, sourceDirectory),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVSphereFileExists(resourceName, "tf_file_test_dir/ks/base.cfg", true),
+					resource.TestCheckResourceAttr(resourceName, "manifest.#", "1"),
+				),
+			},
+		},
+	})
+}
+
+// testAccVSphereFileDeleteRemote removes a file from a datastore outside of
+// Terraform.
+func testAccVSphereFileDeleteRemote(datacenter, datastore, path string) error {
+	client := testAccProvider.Meta().(*VSphereClient).vimClient
+	dc, err := getDatacenter(client, datacenter)
+	if err != nil {
+		return err
+	}
+	finder := find.NewFinder(client.Client, true)
+	finder = finder.SetDatacenter(dc)
+	ds, err := getDatastore(finder, datastore)
+	if err != nil {
+		return err
+	}
+	return deleteDatastoreFile(object.NewFileManager(client.Client), dc, ds, path)
+}
+
+// directory upload, then a file that was not uploaded by Terraform is added to
+// the destination folder, and is left in place on destroy
+func TestAccVSphereFile_uploadDirectoryKeepsOtherFiles(t *testing.T) {
+	sourceDirectory, err := ioutil.TempDir("", "tf-vsphere-file")
+	if err != nil {
+		t.Errorf("error %s", err)
+		return
+	}
+	defer os.RemoveAll(sourceDirectory)
+	if err := os.MkdirAll(sourceDirectory+"/ks", 0755); err != nil {
+		t.Errorf("error %s", err)
+		return
+	}
+	if err := ioutil.WriteFile(sourceDirectory+"/ks/base.cfg", []byte("ks/base.cfg\n"), 0644); err != nil {
+		t.Errorf("error %s", err)
+		return
+	}
+
+	datacenter := os.Getenv("VSPHERE_DATACENTER")
+	datastore := os.Getenv("VSPHERE_DATASTORE")
+	resourceName := "vsphere_file.directory"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckVSphereFileDirectoryDestroyKeepsOtherFiles(datacenter, datastore),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckVSphereFileDirectoryConfig(datacenter, datastore, sourceDirectory),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVSphereFileExists(resourceName, "tf_file_test_dir/ks/base.cfg", true),
+					func(s *terraform.State) error {
+						return testAccVSphereFileUploadContent(datacenter, datastore, "tf_file_test_dir/other.txt", "other\n")
+					},
+				),
+			},
+		},
+	})
+}
+
+// testAccCheckVSphereFileDirectoryDestroyKeepsOtherFiles checks that only the
+// uploaded files were removed, then cleans up the destination folder.
+func testAccCheckVSphereFileDirectoryDestroyKeepsOtherFiles(datacenter, datastore string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*VSphereClient).vimClient
+		dc, err := getDatacenter(client, datacenter)
+		if err != nil {
+			return err
+		}
+		finder := find.NewFinder(client.Client, true)
+		finder = finder.SetDatacenter(dc)
+		ds, err := getDatastore(finder, datastore)
+		if err != nil {
+			return err
+		}
+		if _, err := ds.Stat(context.TODO(), "tf_file_test_dir/ks/base.cfg"); err == nil {
+			return fmt.Errorf("tf_file_test_dir/ks/base.cfg still exists")
+		}
+		if _, err := ds.Stat(context.TODO(), "tf_file_test_dir/other.txt"); err != nil {
+			return fmt.Errorf("expected tf_file_test_dir/other.txt to be kept: %s", err)
+		}
+		return deleteFile(client, &file{datacenter: datacenter, datastore: datastore, destinationFile: "tf_file_test_dir"})
+	}
+}
+
+func testAccCheckVSphereFileDirectoryConfig(datacenter, datastore, sourceDirectory string) string {
+	return fmt.Sprintf(`
+resource "vsphere_file" "directory" {
+  datacenter         = "%s"
+  datastore          = "%s"
+  source_directory   = "%s"
+  destination_file   = "tf_file_test_dir"
+  exclude_patterns   = ["*.txt"]
+  upload_concurrency = 2
+}
+`, datacenter, datastore, sourceDirectory)
+}
+
+func testAccVSphereFileSHA256(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
 func testAccCheckVSphereFileDestroy(s *terraform.State) error {
 	client := testAccProvider.Meta().(*VSphereClient).vimClient
 	finder := find.NewFinder(client.Client, true)
@@ -331,6 +613,15 @@ resource "vsphere_file" "%s" {
 	destination_file = "%s"
 }
 `
+const testAccCheckVSphereFileSHA256Config = `
+resource "vsphere_file" "%s" {
+	datacenter = "%s"
+	datastore = "%s"
+	source_file = "%s"
+	destination_file = "%s"
+	source_sha256 = "0000"
+}
+`
 const testAccCheckVSphereFileCopyConfig = `
 resource "vsphere_file" "%s" {
 	datacenter = "%s"
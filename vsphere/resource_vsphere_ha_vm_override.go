@@ -0,0 +1,314 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func resourceVSphereHaVMOverride() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereHaVMOverrideCreate,
+		Read:   resourceVSphereHaVMOverrideRead,
+		Update: resourceVSphereHaVMOverrideUpdate,
+		Delete: resourceVSphereHaVMOverrideDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceVSphereHaVMOverrideImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"compute_cluster_id": {
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the cluster.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"virtual_machine_uuid": {
+				Type:        schema.TypeString,
+				Description: "The UUID of the virtual machine to override settings for.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			// ClusterDasVmSettings
+			"ha_vm_restart_priority": {
+				Type:        schema.TypeString,
+				Description: "The restart priority for this virtual machine when vSphere detects a host failure. Can be one of clusterRestartPriority, lowest, low, medium, high, highest, or disabled.",
+				Optional:    true,
+				Default:     string(types.ClusterDasVmSettingsRestartPriorityClusterRestartPriority),
+				ValidateFunc: validation.StringInSlice(
+					[]string{
+						string(types.ClusterDasVmSettingsRestartPriorityClusterRestartPriority),
+						string(types.ClusterDasVmSettingsRestartPriorityLowest),
+						string(types.ClusterDasVmSettingsRestartPriorityLow),
+						string(types.ClusterDasVmSettingsRestartPriorityMedium),
+						string(types.ClusterDasVmSettingsRestartPriorityHigh),
+						string(types.ClusterDasVmSettingsRestartPriorityHighest),
+						string(types.ClusterDasVmSettingsRestartPriorityDisabled),
+					},
+					false,
+				),
+			},
+			"ha_vm_restart_timeout": {
+				Type:         schema.TypeInt,
+				Description:  "The maximum time, in seconds, that vSphere HA will wait for this virtual machine to be ready before starting virtual machines with a lower restart priority. Use -1 to use the cluster setting.",
+				Optional:     true,
+				Default:      -1,
+				ValidateFunc: validation.IntAtLeast(-1),
+			},
+			"ha_host_isolation_response": {
+				Type:        schema.TypeString,
+				Description: "The action to take on this virtual machine when a host has detected that it has been isolated from the rest of the cluster. Can be one of clusterIsolationResponse, none, powerOff, or shutdown.",
+				Optional:    true,
+				Default:     string(types.ClusterDasVmSettingsIsolationResponseClusterIsolationResponse),
+				ValidateFunc: validation.StringInSlice(
+					[]string{
+						string(types.ClusterDasVmSettingsIsolationResponseClusterIsolationResponse),
+						string(types.ClusterDasVmSettingsIsolationResponseNone),
+						string(types.ClusterDasVmSettingsIsolationResponsePowerOff),
+						string(types.ClusterDasVmSettingsIsolationResponseShutdown),
+					},
+					false,
+				),
+			},
+			// ClusterVmToolsMonitoringSettings
+			"ha_vm_monitoring_use_cluster_defaults": {
+				Type:        schema.TypeBool,
+				Description: "When true, the VM monitoring settings of the cluster are used for this virtual machine, and the other ha_vm_* monitoring settings in this resource are ignored.",
+				Optional:    true,
+				Default:     true,
+			},
+			"ha_vm_monitoring": {
+				Type:        schema.TypeString,
+				Description: "The type of virtual machine monitoring to use for this virtual machine. Can be one of vmMonitoringDisabled, vmMonitoringOnly, or vmAndAppMonitoring.",
+				Optional:    true,
+				Default:     string(types.ClusterDasConfigInfoVmMonitoringStateVmMonitoringDisabled),
+				ValidateFunc: validation.StringInSlice(
+					[]string{
+						string(types.ClusterDasConfigInfoVmMonitoringStateVmMonitoringDisabled),
+						string(types.ClusterDasConfigInfoVmMonitoringStateVmMonitoringOnly),
+						string(types.ClusterDasConfigInfoVmMonitoringStateVmAndAppMonitoring),
+					},
+					false,
+				),
+			},
+			"ha_vm_failure_interval": {
+				Type:         schema.TypeInt,
+				Description:  "If a heartbeat from this virtual machine is not received within this configured interval, the virtual machine is marked as failed. The value is in seconds.",
+				Optional:     true,
+				Default:      30,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"ha_vm_minimum_uptime": {
+				Type:         schema.TypeInt,
+				Description:  "The time, in seconds, that HA waits after powering on this virtual machine before monitoring for heartbeats.",
+				Optional:     true,
+				Default:      120,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"ha_vm_maximum_resets": {
+				Type:         schema.TypeInt,
+				Description:  "The maximum number of resets that HA will perform to this virtual machine when responding to a failure event.",
+				Optional:     true,
+				Default:      3,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"ha_vm_maximum_failure_window": {
+				Type:         schema.TypeInt,
+				Description:  "The length of the reset window in which ha_vm_maximum_resets can operate, in seconds. Use -1 for no window.",
+				Optional:     true,
+				Default:      -1,
+				ValidateFunc: validation.IntAtLeast(-1),
+			},
+		},
+	}
+}
+
+func resourceVSphereHaVMOverrideCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	cluster, vm, err := resourceVSphereHaVMOverrideObjects(d, meta)
+	if err != nil {
+		return err
+	}
+
+	// Refuse to take over an override that already exists, as it may be
+	// managed outside of this resource.
+	existing, err := clusterDasVMConfig(cluster, vm)
+	if err != nil {
+		return fmt.Errorf("error fetching cluster HA configuration: %s", err)
+	}
+	if existing != nil {
+		return fmt.Errorf("an HA override for virtual machine %q already exists in cluster %q", d.Get("virtual_machine_uuid").(string), cluster.Reference().Value)
+	}
+	if err := resourceVSphereHaVMOverrideApply(d, cluster, vm, types.ArrayUpdateOperationAdd); err != nil {
+		return err
+	}
+
+	d.SetId(fmt.Sprintf("%s:%s", cluster.Reference().Value, d.Get("virtual_machine_uuid").(string)))
+	return resourceVSphereHaVMOverrideRead(d, meta)
+}
+
+func resourceVSphereHaVMOverrideRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	cluster, vm, err := resourceVSphereHaVMOverrideObjects(d, meta)
+	if err != nil {
+		return err
+	}
+
+	info, err := clusterDasVMConfig(cluster, vm)
+	if err != nil {
+		return fmt.Errorf("error fetching cluster HA configuration: %s", err)
+	}
+	if info == nil {
+		log.Printf("[DEBUG] HA override for virtual machine %q not found in cluster %q, marking as gone", d.Get("virtual_machine_uuid").(string), cluster.Reference().Value)
+		d.SetId("")
+		return nil
+	}
+
+	return flattenClusterDasVMConfigInfo(d, info)
+}
+
+func resourceVSphereHaVMOverrideUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	cluster, vm, err := resourceVSphereHaVMOverrideObjects(d, meta)
+	if err != nil {
+		return err
+	}
+	if err := resourceVSphereHaVMOverrideApply(d, cluster, vm, types.ArrayUpdateOperationEdit); err != nil {
+		return err
+	}
+	return resourceVSphereHaVMOverrideRead(d, meta)
+}
+
+func resourceVSphereHaVMOverrideDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	cluster, vm, err := resourceVSphereHaVMOverrideObjects(d, meta)
+	if err != nil {
+		return err
+	}
+
+	spec := &types.ClusterConfigSpecEx{
+		DasVmConfigSpec: []types.ClusterDasVmConfigSpec{
+			{
+				ArrayUpdateSpec: types.ArrayUpdateSpec{
+					Operation: types.ArrayUpdateOperationRemove,
+					RemoveKey: vm.Reference(),
+				},
+			},
+		},
+	}
+	if err := reconfigureClusterComputeResource(cluster, spec); err != nil {
+		return fmt.Errorf("error removing HA override: %s", err)
+	}
+	return nil
+}
+
+func resourceVSphereHaVMOverrideImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	// We import with the same ID that the resource is saved with, which is the
+	// cluster ID and the virtual machine UUID, separated by a colon.
+	parts := strings.SplitN(d.Id(), ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("invalid ID %q, expected COMPUTE_CLUSTER_ID:VIRTUAL_MACHINE_UUID", d.Id())
+	}
+	d.Set("compute_cluster_id", parts[0])
+	d.Set("virtual_machine_uuid", parts[1])
+	return []*schema.ResourceData{d}, nil
+}
+
+// resourceVSphereHaVMOverrideObjects fetches the cluster and virtual machine
+// for the resource.
+func resourceVSphereHaVMOverrideObjects(d *schema.ResourceData, meta interface{}) (*object.ClusterComputeResource, *object.VirtualMachine, error) {
+	client := meta.(*VSphereClient).vimClient
+	cluster, err := clusterComputeResourceFromID(client, d.Get("compute_cluster_id").(string))
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot locate cluster: %s", err)
+	}
+	vm, err := virtualMachineFromUUID(client, d.Get("virtual_machine_uuid").(string))
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot locate virtual machine: %s", err)
+	}
+	return cluster, vm, nil
+}
+
+// resourceVSphereHaVMOverrideApply applies the override in the resource to
+// the cluster, using the supplied operation.
+func resourceVSphereHaVMOverrideApply(d *schema.ResourceData, cluster *object.ClusterComputeResource, vm *object.VirtualMachine, op types.ArrayUpdateOperation) error {
+	spec := &types.ClusterConfigSpecEx{
+		DasVmConfigSpec: []types.ClusterDasVmConfigSpec{
+			{
+				ArrayUpdateSpec: types.ArrayUpdateSpec{
+					Operation: op,
+				},
+				Info: expandClusterDasVMConfigInfo(d, vm),
+			},
+		},
+	}
+	if err := reconfigureClusterComputeResource(cluster, spec); err != nil {
+		return fmt.Errorf("error applying HA override: %s", err)
+	}
+	return nil
+}
+
+// expandClusterDasVMConfigInfo reads the resource and returns a
+// ClusterDasVmConfigInfo for the virtual machine.
+func expandClusterDasVMConfigInfo(d *schema.ResourceData, vm *object.VirtualMachine) *types.ClusterDasVmConfigInfo {
+	obj := &types.ClusterDasVmConfigInfo{
+		Key: vm.Reference(),
+		DasSettings: &types.ClusterDasVmSettings{
+			RestartPriority:        d.Get("ha_vm_restart_priority").(string),
+			RestartPriorityTimeout: int32(d.Get("ha_vm_restart_timeout").(int)),
+			IsolationResponse:      d.Get("ha_host_isolation_response").(string),
+			VmToolsMonitoringSettings: &types.ClusterVmToolsMonitoringSettings{
+				ClusterSettings:  boolPtr(d.Get("ha_vm_monitoring_use_cluster_defaults").(bool)),
+				VmMonitoring:     d.Get("ha_vm_monitoring").(string),
+				FailureInterval:  int32(d.Get("ha_vm_failure_interval").(int)),
+				MinUpTime:        int32(d.Get("ha_vm_minimum_uptime").(int)),
+				MaxFailures:      int32(d.Get("ha_vm_maximum_resets").(int)),
+				MaxFailureWindow: int32(d.Get("ha_vm_maximum_failure_window").(int)),
+			},
+		},
+	}
+	return obj
+}
+
+// flattenClusterDasVMConfigInfo reads various fields from a
+// ClusterDasVmConfigInfo into the passed in ResourceData.
+func flattenClusterDasVMConfigInfo(d *schema.ResourceData, obj *types.ClusterDasVmConfigInfo) error {
+	settings := obj.DasSettings
+	if settings == nil {
+		return nil
+	}
+	d.Set("ha_vm_restart_priority", settings.RestartPriority)
+	d.Set("ha_vm_restart_timeout", settings.RestartPriorityTimeout)
+	d.Set("ha_host_isolation_response", settings.IsolationResponse)
+
+	monitoring := settings.VmToolsMonitoringSettings
+	if monitoring == nil {
+		return nil
+	}
+	if err := setBoolPtr(d, "ha_vm_monitoring_use_cluster_defaults", monitoring.ClusterSettings); err != nil {
+		return err
+	}
+	d.Set("ha_vm_monitoring", monitoring.VmMonitoring)
+	d.Set("ha_vm_failure_interval", monitoring.FailureInterval)
+	d.Set("ha_vm_minimum_uptime", monitoring.MinUpTime)
+	d.Set("ha_vm_maximum_resets", monitoring.MaxFailures)
+	d.Set("ha_vm_maximum_failure_window", monitoring.MaxFailureWindow)
+	return nil
+}
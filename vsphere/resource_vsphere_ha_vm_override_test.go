@@ -0,0 +1,187 @@
+package vsphere
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestAccResourceVSphereHaVMOverride(t *testing.T) {
+	var tp *testing.T
+	testAccResourceVSphereHaVMOverrideCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"restart priority",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccComputeClusterMembersPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereHaVMOverrideExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereHaVMOverrideConfig(`ha_vm_restart_priority = "highest"`),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereHaVMOverrideExists(true),
+							testAccResourceVSphereHaVMOverrideMatch(func(info *types.ClusterDasVmConfigInfo) error {
+								if info.DasSettings == nil || info.DasSettings.RestartPriority != string(types.ClusterDasVmSettingsRestartPriorityHighest) {
+									return errors.New("expected restart priority to be highest")
+								}
+								return nil
+							}),
+						),
+					},
+				},
+			},
+		},
+		{
+			"vm monitoring",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccComputeClusterMembersPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereHaVMOverrideExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereHaVMOverrideConfig(`ha_vm_restart_priority = "highest"`),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereHaVMOverrideExists(true),
+						),
+					},
+					{
+						Config: testAccResourceVSphereHaVMOverrideConfig(`
+  ha_vm_monitoring_use_cluster_defaults = false
+  ha_vm_monitoring                      = "vmMonitoringOnly"
+  ha_vm_maximum_resets                  = 5
+`),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereHaVMOverrideExists(true),
+							testAccResourceVSphereHaVMOverrideMatch(func(info *types.ClusterDasVmConfigInfo) error {
+								if info.DasSettings == nil || info.DasSettings.VmToolsMonitoringSettings == nil {
+									return errors.New("expected VM monitoring settings to be present")
+								}
+								monitoring := info.DasSettings.VmToolsMonitoringSettings
+								if monitoring.ClusterSettings == nil || *monitoring.ClusterSettings {
+									return errors.New("expected VM monitoring to not use cluster settings")
+								}
+								if monitoring.VmMonitoring != string(types.ClusterDasConfigInfoVmMonitoringStateVmMonitoringOnly) {
+									return fmt.Errorf("expected VM monitoring to be vmMonitoringOnly, got %q", monitoring.VmMonitoring)
+								}
+								if monitoring.MaxFailures != 5 {
+									return fmt.Errorf("expected maximum resets to be 5, got %d", monitoring.MaxFailures)
+								}
+								return nil
+							}),
+						),
+					},
+				},
+			},
+		},
+		{
+			"import",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccComputeClusterMembersPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereHaVMOverrideExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereHaVMOverrideConfig(`ha_vm_restart_priority = "highest"`),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereHaVMOverrideExists(true),
+						),
+					},
+					{
+						ResourceName:      "vsphere_ha_vm_override.override",
+						ImportState:       true,
+						ImportStateVerify: true,
+						Config:            testAccResourceVSphereHaVMOverrideConfig(`ha_vm_restart_priority = "highest"`),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccResourceVSphereHaVMOverrideCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+// testAccResourceVSphereHaVMOverrideGetConfig fetches the HA override for
+// the first virtual machine in the test cluster. nil is returned if there is
+// no override.
+func testAccResourceVSphereHaVMOverrideGetConfig(s *terraform.State) (*types.ClusterDasVmConfigInfo, error) {
+	cluster, err := testGetComputeCluster(s, "compute_cluster")
+	if err != nil {
+		return nil, err
+	}
+	tVars, err := testClientVariablesForResource(s, "vsphere_virtual_machine.vm.0")
+	if err != nil {
+		return nil, err
+	}
+	vm, err := virtualMachineFromUUID(tVars.client, tVars.resourceAttributes["uuid"])
+	if err != nil {
+		return nil, err
+	}
+	return clusterDasVMConfig(cluster, vm)
+}
+
+func testAccResourceVSphereHaVMOverrideExists(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		info, err := testAccResourceVSphereHaVMOverrideGetConfig(s)
+		if err != nil {
+			if isAnyNotFoundError(err) && expected == false {
+				// Expected missing
+				return nil
+			}
+			return err
+		}
+		exists := info != nil
+		if exists != expected {
+			return fmt.Errorf("expected HA override to exist: %t, got %t", expected, exists)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereHaVMOverrideMatch(f func(*types.ClusterDasVmConfigInfo) error) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		info, err := testAccResourceVSphereHaVMOverrideGetConfig(s)
+		if err != nil {
+			return err
+		}
+		if info == nil {
+			return errors.New("HA override not found")
+		}
+		return f(info)
+	}
+}
+
+func testAccResourceVSphereHaVMOverrideConfig(extra string) string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_ha_vm_override" "override" {
+  compute_cluster_id   = "${vsphere_compute_cluster.compute_cluster.id}"
+  virtual_machine_uuid = "${vsphere_virtual_machine.vm.0.uuid}"
+  %s
+}
+`,
+		testAccComputeClusterMembersConfig(),
+		extra,
+	)
+}
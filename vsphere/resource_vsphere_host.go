@@ -0,0 +1,409 @@
+package vsphere
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+const (
+	hostLockdownDisabled = "disabled"
+	hostLockdownNormal   = "normal"
+	hostLockdownStrict   = "strict"
+)
+
+// hostLockdownModes maps the values of the lockdown attribute to their
+// respective HostLockdownMode.
+var hostLockdownModes = map[string]types.HostLockdownMode{
+	hostLockdownDisabled: types.HostLockdownModeLockdownDisabled,
+	hostLockdownNormal:   types.HostLockdownModeLockdownNormal,
+	hostLockdownStrict:   types.HostLockdownModeLockdownStrict,
+}
+
+func resourceVSphereHost() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereHostCreate,
+		Read:   resourceVSphereHostRead,
+		Update: resourceVSphereHostUpdate,
+		Delete: resourceVSphereHostDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceVSphereHostImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"hostname": {
+				Type:         schema.TypeString,
+				Description:  "The FQDN or IP address of the host.",
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.NoZeroValues,
+			},
+			"username": {
+				Type:        schema.TypeString,
+				Description: "The username of the administrative account on the host.",
+				Required:    true,
+			},
+			"password": {
+				Type:        schema.TypeString,
+				Description: "The password of the administrative account on the host.",
+				Required:    true,
+				Sensitive:   true,
+			},
+			"thumbprint": {
+				Type:        schema.TypeString,
+				Description: "The SHA-1 thumbprint of the host's SSL certificate. Required if the certificate is not trusted by vCenter.",
+				Optional:    true,
+			},
+			"datacenter_id": {
+				Type:          schema.TypeString,
+				Description:   "The managed object ID of the datacenter to add the host to as a standalone host.",
+				Optional:      true,
+				ConflictsWith: []string{"cluster_id"},
+			},
+			"cluster_id": {
+				Type:          schema.TypeString,
+				Description:   "The managed object ID of the cluster to add the host to.",
+				Optional:      true,
+				ConflictsWith: []string{"datacenter_id"},
+			},
+			"force": {
+				Type:        schema.TypeBool,
+				Description: "Force the host to be added, even if it is already managed by another vCenter server.",
+				Optional:    true,
+			},
+			"connected": {
+				Type:        schema.TypeBool,
+				Description: "Whether or not the host is connected to vCenter.",
+				Optional:    true,
+				Default:     true,
+			},
+			"maintenance": {
+				Type:        schema.TypeBool,
+				Description: "Whether or not the host is in maintenance mode.",
+				Optional:    true,
+			},
+			"maintenance_timeout": {
+				Type:         schema.TypeInt,
+				Description:  "The time, in seconds, to wait for the host to enter or exit maintenance mode, including the time taken to evacuate virtual machines. 0 means no timeout.",
+				Optional:     true,
+				Default:      600,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"lockdown": {
+				Type:         schema.TypeString,
+				Description:  "The lockdown mode of the host. Can be one of disabled, normal, or strict.",
+				Optional:     true,
+				Default:      hostLockdownDisabled,
+				ValidateFunc: validation.StringInSlice([]string{hostLockdownDisabled, hostLockdownNormal, hostLockdownStrict}, false),
+			},
+			// Tagging
+			vSphereTagAttributeKey: tagsSchema(),
+		},
+	}
+}
+
+func resourceVSphereHostCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	if err := resourceVSphereHostValidate(d); err != nil {
+		return err
+	}
+	tagsClient, err := tagsClientIfDefined(d, meta)
+	if err != nil {
+		return err
+	}
+
+	spec := expandHostConnectSpec(d)
+	connected := d.Get("connected").(bool)
+	var host *object.HostSystem
+	if clusterID := d.Get("cluster_id").(string); clusterID != "" {
+		cluster, err := clusterComputeResourceFromID(client, clusterID)
+		if err != nil {
+			return fmt.Errorf("cannot locate cluster: %s", err)
+		}
+		if host, err = addHostToCluster(client, cluster, spec, connected); err != nil {
+			return fmt.Errorf("error adding host to cluster: %s", err)
+		}
+	} else {
+		dc, err := datacenterFromID(client, d.Get("datacenter_id").(string))
+		if err != nil {
+			return fmt.Errorf("cannot locate datacenter: %s", err)
+		}
+		folder, err := folderFromPath(client, "", vSphereFolderTypeHost, dc)
+		if err != nil {
+			return fmt.Errorf("cannot locate host folder: %s", err)
+		}
+		if host, err = addStandaloneHost(client, folder, spec, connected); err != nil {
+			return fmt.Errorf("error adding standalone host: %s", err)
+		}
+	}
+	d.SetId(host.Reference().Value)
+
+	if connected {
+		if d.Get("maintenance").(bool) {
+			if err := enterHostMaintenanceMode(host, d.Get("maintenance_timeout").(int)); err != nil {
+				return fmt.Errorf("error putting host into maintenance mode: %s", err)
+			}
+		}
+		if mode := d.Get("lockdown").(string); mode != hostLockdownDisabled {
+			if err := changeHostLockdownMode(client, host, hostLockdownModes[mode]); err != nil {
+				return fmt.Errorf("error changing lockdown mode: %s", err)
+			}
+		}
+	}
+
+	// Apply any pending tags now
+	if tagsClient != nil {
+		if err := processTagDiff(tagsClient, d, host); err != nil {
+			return fmt.Errorf("error updating tags: %s", err)
+		}
+	}
+
+	return resourceVSphereHostRead(d, meta)
+}
+
+func resourceVSphereHostRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	host, err := hostSystemFromID(client, d.Id())
+	if err != nil {
+		return err
+	}
+	props, err := hostSystemProperties(host)
+	if err != nil {
+		return fmt.Errorf("error fetching host properties: %s", err)
+	}
+	d.Set("hostname", props.Name)
+
+	// Set either the cluster or the datacenter, depending on whether or not
+	// this is a standalone host.
+	if props.Parent != nil && props.Parent.Type == "ClusterComputeResource" {
+		d.Set("cluster_id", props.Parent.Value)
+		d.Set("datacenter_id", "")
+	} else {
+		dcp, err := rootPathParticleHost.SplitDatacenter(host.InventoryPath)
+		if err != nil {
+			return fmt.Errorf("error parsing datacenter from inventory path: %s", err)
+		}
+		dc, err := getDatacenter(client, dcp)
+		if err != nil {
+			return fmt.Errorf("error locating datacenter: %s", err)
+		}
+		d.Set("datacenter_id", dc.Reference().Value)
+		d.Set("cluster_id", "")
+	}
+
+	d.Set("connected", props.Runtime.ConnectionState == types.HostSystemConnectionStateConnected)
+	d.Set("maintenance", props.Runtime.InMaintenanceMode)
+
+	// The host configuration is not available while the host is disconnected,
+	// so lockdown mode can only be read when it's connected.
+	if props.Config != nil {
+		for k, v := range hostLockdownModes {
+			if v == props.Config.LockdownMode {
+				d.Set("lockdown", k)
+			}
+		}
+	}
+
+	// Read tags if we have the ability to do so
+	if tagsClient, _ := meta.(*VSphereClient).TagsClient(); tagsClient != nil {
+		if err := readTagsForResource(tagsClient, host, d); err != nil {
+			return fmt.Errorf("error reading tags: %s", err)
+		}
+	}
+
+	return nil
+}
+
+func resourceVSphereHostUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	if err := resourceVSphereHostValidate(d); err != nil {
+		return err
+	}
+	tagsClient, err := tagsClientIfDefined(d, meta)
+	if err != nil {
+		return err
+	}
+	host, err := hostSystemFromID(client, d.Id())
+	if err != nil {
+		return err
+	}
+	timeout := d.Get("maintenance_timeout").(int)
+	connected := d.Get("connected").(bool)
+	maintenance := d.Get("maintenance").(bool)
+
+	// Reconnect the host first if necessary, as nothing else can be done with
+	// it while it's disconnected.
+	if d.HasChange("connected") && connected {
+		if err := reconnectHost(host, expandHostConnectSpec(d)); err != nil {
+			return fmt.Errorf("error reconnecting host: %s", err)
+		}
+	}
+
+	// Move the host if its cluster or datacenter has changed. The host needs to
+	// be in maintenance mode for this.
+	if d.HasChange("cluster_id") || d.HasChange("datacenter_id") {
+		o, _ := d.GetChange("maintenance")
+		if !o.(bool) {
+			if err := enterHostMaintenanceMode(host, timeout); err != nil {
+				return fmt.Errorf("error putting host into maintenance mode: %s", err)
+			}
+		}
+		if err := resourceVSphereHostMove(client, d, host); err != nil {
+			return err
+		}
+		if !maintenance {
+			if err := exitHostMaintenanceMode(host, timeout); err != nil {
+				return fmt.Errorf("error taking host out of maintenance mode: %s", err)
+			}
+		}
+	} else if d.HasChange("maintenance") {
+		if maintenance {
+			if err := enterHostMaintenanceMode(host, timeout); err != nil {
+				return fmt.Errorf("error putting host into maintenance mode: %s", err)
+			}
+		} else {
+			if err := exitHostMaintenanceMode(host, timeout); err != nil {
+				return fmt.Errorf("error taking host out of maintenance mode: %s", err)
+			}
+		}
+	}
+
+	if d.HasChange("lockdown") && connected {
+		if err := changeHostLockdownMode(client, host, hostLockdownModes[d.Get("lockdown").(string)]); err != nil {
+			return fmt.Errorf("error changing lockdown mode: %s", err)
+		}
+	}
+
+	// Apply any pending tags now
+	if tagsClient != nil {
+		if err := processTagDiff(tagsClient, d, host); err != nil {
+			return fmt.Errorf("error updating tags: %s", err)
+		}
+	}
+
+	// Disconnect last so that any other changes are applied first.
+	if d.HasChange("connected") && !connected {
+		if err := disconnectHost(host); err != nil {
+			return fmt.Errorf("error disconnecting host: %s", err)
+		}
+	}
+
+	return resourceVSphereHostRead(d, meta)
+}
+
+func resourceVSphereHostDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	host, err := hostSystemFromID(client, d.Id())
+	if err != nil {
+		return err
+	}
+	props, err := hostSystemProperties(host)
+	if err != nil {
+		return fmt.Errorf("error fetching host properties: %s", err)
+	}
+
+	// Disconnect the host first. This allows hosts in a cluster to be removed
+	// without having to put them into maintenance mode.
+	if props.Runtime.ConnectionState == types.HostSystemConnectionStateConnected {
+		if err := disconnectHost(host); err != nil {
+			return fmt.Errorf("error disconnecting host: %s", err)
+		}
+	}
+	if err := removeHost(client, host); err != nil {
+		return fmt.Errorf("error removing host: %s", err)
+	}
+	return nil
+}
+
+func resourceVSphereHostImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	// We import hosts by inventory path. A full path is required unless the
+	// default datacenter can be utilized.
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return nil, err
+	}
+	host, err := hostSystemFromPath(client, d.Id(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error locating host: %s", err)
+	}
+	d.SetId(host.Reference().Value)
+	// Set the default for maintenance_timeout, which is not read back from the
+	// host.
+	d.Set("maintenance_timeout", 600)
+	return []*schema.ResourceData{d}, nil
+}
+
+// resourceVSphereHostValidate checks combinations of attributes that cannot
+// be checked by validation on individual schema attributes.
+func resourceVSphereHostValidate(d *schema.ResourceData) error {
+	if d.Get("cluster_id").(string) == "" && d.Get("datacenter_id").(string) == "" {
+		return errors.New("one of cluster_id or datacenter_id must be set")
+	}
+	if !d.Get("connected").(bool) {
+		if d.Get("maintenance").(bool) {
+			return errors.New("maintenance cannot be true when connected is false")
+		}
+		if d.Get("lockdown").(string) != hostLockdownDisabled {
+			return errors.New("lockdown must be disabled when connected is false")
+		}
+	}
+	return nil
+}
+
+// resourceVSphereHostMove moves a host to the cluster in cluster_id, or to the
+// host folder of the datacenter in datacenter_id if it's to be a standalone
+// host.
+func resourceVSphereHostMove(client *govmomi.Client, d *schema.ResourceData, host *object.HostSystem) error {
+	if clusterID := d.Get("cluster_id").(string); clusterID != "" {
+		cluster, err := clusterComputeResourceFromID(client, clusterID)
+		if err != nil {
+			return fmt.Errorf("cannot locate cluster: %s", err)
+		}
+		if err := moveHostToCluster(client, host, cluster); err != nil {
+			return fmt.Errorf("error moving host to cluster: %s", err)
+		}
+		return nil
+	}
+	dc, err := datacenterFromID(client, d.Get("datacenter_id").(string))
+	if err != nil {
+		return fmt.Errorf("cannot locate datacenter: %s", err)
+	}
+	folder, err := folderFromPath(client, "", vSphereFolderTypeHost, dc)
+	if err != nil {
+		return fmt.Errorf("cannot locate host folder: %s", err)
+	}
+	if err := moveObjectToFolder(host.Reference(), folder); err != nil {
+		return fmt.Errorf("error moving host out of cluster: %s", err)
+	}
+	return nil
+}
+
+// expandHostConnectSpec reads certain ResourceData keys and returns a
+// HostConnectSpec.
+func expandHostConnectSpec(d *schema.ResourceData) types.HostConnectSpec {
+	obj := types.HostConnectSpec{
+		HostName:      d.Get("hostname").(string),
+		UserName:      d.Get("username").(string),
+		Password:      d.Get("password").(string),
+		SslThumbprint: d.Get("thumbprint").(string),
+		Force:         d.Get("force").(bool),
+	}
+	return obj
+}
@@ -0,0 +1,317 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func resourceVSphereHostAdvancedSettings() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereHostAdvancedSettingsCreate,
+		Read:   resourceVSphereHostAdvancedSettingsRead,
+		Update: resourceVSphereHostAdvancedSettingsUpdate,
+		Delete: resourceVSphereHostAdvancedSettingsDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceVSphereHostAdvancedSettingsImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"host_system_id": {
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the host to manage advanced settings on.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"settings": {
+				Type:        schema.TypeMap,
+				Description: "A map of advanced setting keys to values. Only the keys in this map are managed by this resource.",
+				Required:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func resourceVSphereHostAdvancedSettingsCreate(d *schema.ResourceData, meta interface{}) error {
+	if err := resourceVSphereHostAdvancedSettingsApply(d, meta, nil, d.Get("settings").(map[string]interface{})); err != nil {
+		return err
+	}
+	d.SetId(d.Get("host_system_id").(string))
+	return resourceVSphereHostAdvancedSettingsRead(d, meta)
+}
+
+func resourceVSphereHostAdvancedSettingsRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	om, err := hostOptionManagerFromHostSystemID(client, d.Id())
+	if err != nil {
+		return fmt.Errorf("error loading host option manager: %s", err)
+	}
+	props, err := hostOptionManagerProperties(om)
+	if err != nil {
+		return fmt.Errorf("error fetching host advanced settings: %s", err)
+	}
+	current := flattenHostOptionValues(props.Setting)
+	defs := hostOptionDefsByKey(props)
+
+	// Only read back the keys that we manage.
+	settings := make(map[string]interface{})
+	for k, old := range d.Get("settings").(map[string]interface{}) {
+		v, ok := current[k]
+		if !ok {
+			log.Printf("[DEBUG] Advanced setting %q no longer exists on host %q", k, d.Id())
+			continue
+		}
+		// Keep the value as it was written if it means the same thing as the
+		// value on the host, so that values like "TRUE" or "010" do not show a
+		// diff against "true" or "10".
+		if def, ok := defs[k]; ok && hostOptionValueEqual(def, old.(string), v) {
+			v = old.(string)
+		}
+		settings[k] = v
+	}
+	d.Set("host_system_id", d.Id())
+	if err := d.Set("settings", settings); err != nil {
+		return fmt.Errorf("error setting advanced settings: %s", err)
+	}
+	return nil
+}
+
+func resourceVSphereHostAdvancedSettingsUpdate(d *schema.ResourceData, meta interface{}) error {
+	o, n := d.GetChange("settings")
+	if err := resourceVSphereHostAdvancedSettingsApply(d, meta, o.(map[string]interface{}), n.(map[string]interface{})); err != nil {
+		return err
+	}
+	return resourceVSphereHostAdvancedSettingsRead(d, meta)
+}
+
+func resourceVSphereHostAdvancedSettingsDelete(d *schema.ResourceData, meta interface{}) error {
+	// Return all of the settings that we manage to their defaults.
+	return resourceVSphereHostAdvancedSettingsApply(d, meta, d.Get("settings").(map[string]interface{}), nil)
+}
+
+func resourceVSphereHostAdvancedSettingsImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	// We import either with the host system ID, which imports all settings
+	// that differ from their defaults, or with the host system ID and a
+	// comma-separated list of keys, separated by a colon.
+	client := meta.(*VSphereClient).vimClient
+	parts := strings.SplitN(d.Id(), ":", 2)
+	hsID := parts[0]
+	if hsID == "" {
+		return nil, fmt.Errorf("invalid ID %q, expected HOST_SYSTEM_ID or HOST_SYSTEM_ID:KEY1,KEY2", d.Id())
+	}
+
+	settings := make(map[string]interface{})
+	if len(parts) == 2 {
+		for _, k := range strings.Split(parts[1], ",") {
+			if k != "" {
+				settings[k] = ""
+			}
+		}
+	} else {
+		om, err := hostOptionManagerFromHostSystemID(client, hsID)
+		if err != nil {
+			return nil, fmt.Errorf("error loading host option manager: %s", err)
+		}
+		props, err := hostOptionManagerProperties(om)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching host advanced settings: %s", err)
+		}
+		defs := hostOptionDefsByKey(props)
+		for k, v := range flattenHostOptionValues(props.Setting) {
+			def, ok := defs[k]
+			if !ok || hostOptionIsReadOnly(def) {
+				continue
+			}
+			if dv, ok := hostOptionDefaultValue(def); ok && fmt.Sprint(dv) == v {
+				continue
+			}
+			settings[k] = v
+		}
+	}
+	if len(settings) < 1 {
+		return nil, fmt.Errorf("no advanced settings to import from host %q", hsID)
+	}
+
+	d.SetId(hsID)
+	d.Set("host_system_id", hsID)
+	if err := d.Set("settings", settings); err != nil {
+		return nil, fmt.Errorf("error setting advanced settings: %s", err)
+	}
+	return []*schema.ResourceData{d}, nil
+}
+
+// resourceVSphereHostAdvancedSettingsApply applies the changes between the old
+// and new settings maps to the host. Keys that are only in the old map are
+// returned to their default values.
+func resourceVSphereHostAdvancedSettingsApply(d *schema.ResourceData, meta interface{}, o, n map[string]interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	om, err := hostOptionManagerFromHostSystemID(client, d.Get("host_system_id").(string))
+	if err != nil {
+		return fmt.Errorf("error loading host option manager: %s", err)
+	}
+	props, err := hostOptionManagerProperties(om)
+	if err != nil {
+		return fmt.Errorf("error fetching host advanced settings: %s", err)
+	}
+	defs := hostOptionDefsByKey(props)
+
+	var values []types.BaseOptionValue
+	for k, v := range n {
+		if ov, ok := o[k]; ok && ov == v {
+			continue
+		}
+		def, ok := defs[k]
+		if !ok {
+			return fmt.Errorf("advanced setting %q is not supported on host %q", k, d.Get("host_system_id").(string))
+		}
+		if hostOptionIsReadOnly(def) {
+			return fmt.Errorf("advanced setting %q is read-only", k)
+		}
+		value, err := expandHostOptionValue(def, v.(string))
+		if err != nil {
+			return fmt.Errorf("invalid value for advanced setting %q: %s", k, err)
+		}
+		values = append(values, &types.OptionValue{Key: k, Value: value})
+	}
+	for k := range o {
+		if _, ok := n[k]; ok {
+			continue
+		}
+		def, ok := defs[k]
+		if !ok {
+			// The setting no longer exists, nothing to reset.
+			continue
+		}
+		value, ok := hostOptionDefaultValue(def)
+		if !ok {
+			log.Printf("[WARN] Advanced setting %q has no default value, leaving as is", k)
+			continue
+		}
+		values = append(values, &types.OptionValue{Key: k, Value: value})
+	}
+	if len(values) < 1 {
+		return nil
+	}
+
+	if err := updateHostOptions(om, values); err != nil {
+		return fmt.Errorf("error updating host advanced settings: %s", err)
+	}
+	return nil
+}
+
+// hostOptionDefsByKey returns the supported options of an OptionManager,
+// keyed by option key.
+func hostOptionDefsByKey(props *mo.OptionManager) map[string]types.OptionDef {
+	defs := make(map[string]types.OptionDef)
+	for _, def := range props.SupportedOption {
+		defs[def.Key] = def
+	}
+	return defs
+}
+
+// hostOptionIsReadOnly returns true if the supplied option cannot be changed.
+func hostOptionIsReadOnly(def types.OptionDef) bool {
+	if def.OptionType == nil {
+		return false
+	}
+	ro := def.OptionType.GetOptionType().ValueIsReadonly
+	return ro != nil && *ro
+}
+
+// expandHostOptionValue converts the string value of an advanced setting to
+// the type required by its option definition, checking it against any range
+// or choices in the definition.
+func expandHostOptionValue(def types.OptionDef, value string) (interface{}, error) {
+	switch t := def.OptionType.(type) {
+	case *types.BoolOption:
+		return strconv.ParseBool(value)
+	case *types.IntOption:
+		v, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		if t.Min < t.Max && (int32(v) < t.Min || int32(v) > t.Max) {
+			return nil, fmt.Errorf("%d is out of range (%d-%d)", v, t.Min, t.Max)
+		}
+		return int32(v), nil
+	case *types.LongOption:
+		v, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		if t.Min < t.Max && (v < t.Min || v > t.Max) {
+			return nil, fmt.Errorf("%d is out of range (%d-%d)", v, t.Min, t.Max)
+		}
+		return v, nil
+	case *types.FloatOption:
+		v, err := strconv.ParseFloat(value, 32)
+		if err != nil {
+			return nil, err
+		}
+		if t.Min < t.Max && (float32(v) < t.Min || float32(v) > t.Max) {
+			return nil, fmt.Errorf("%g is out of range (%g-%g)", v, t.Min, t.Max)
+		}
+		return float32(v), nil
+	case *types.ChoiceOption:
+		var choices []string
+		for _, c := range t.ChoiceInfo {
+			key := c.GetElementDescription().Key
+			if key == value {
+				return value, nil
+			}
+			choices = append(choices, key)
+		}
+		return nil, fmt.Errorf("%q is not one of %s", value, strings.Join(choices, ", "))
+	}
+	return value, nil
+}
+
+// hostOptionValueEqual returns true if the supplied string value of an advanced
+// setting converts to the current value, as returned by
+// flattenHostOptionValues.
+func hostOptionValueEqual(def types.OptionDef, value, current string) bool {
+	v, err := expandHostOptionValue(def, value)
+	if err != nil {
+		return false
+	}
+	return fmt.Sprint(v) == current
+}
+
+// hostOptionDefaultValue returns the default value of an advanced setting.
+// false is returned if the option type has no default.
+func hostOptionDefaultValue(def types.OptionDef) (interface{}, bool) {
+	switch t := def.OptionType.(type) {
+	case *types.BoolOption:
+		return t.DefaultValue, true
+	case *types.IntOption:
+		return t.DefaultValue, true
+	case *types.LongOption:
+		return t.DefaultValue, true
+	case *types.FloatOption:
+		return t.DefaultValue, true
+	case *types.StringOption:
+		return t.DefaultValue, true
+	case *types.ChoiceOption:
+		if int(t.DefaultIndex) < len(t.ChoiceInfo) {
+			return t.ChoiceInfo[t.DefaultIndex].GetElementDescription().Key, true
+		}
+	}
+	return nil, false
+}
+
+// flattenHostOptionValues converts a list of advanced settings to a map of
+// keys to string values.
+func flattenHostOptionValues(values []types.BaseOptionValue) map[string]string {
+	m := make(map[string]string)
+	for _, v := range values {
+		ov := v.GetOptionValue()
+		m[ov.Key] = fmt.Sprint(ov.Value)
+	}
+	return m
+}
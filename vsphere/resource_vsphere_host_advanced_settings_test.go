@@ -0,0 +1,224 @@
+package vsphere
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestAccResourceVSphereHostAdvancedSettings(t *testing.T) {
+	var tp *testing.T
+	testAccResourceVSphereHostAdvancedSettingsCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"basic",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccHostConfigPreCheck(tp)
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereHostAdvancedSettingsConfig(`"UserVars.SuppressShellWarning" = "1"`),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereHostAdvancedSettingsHasValue("UserVars.SuppressShellWarning", "1"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"equivalent value",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccHostConfigPreCheck(tp)
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereHostAdvancedSettingsConfig(`"UserVars.SuppressShellWarning" = "01"`),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereHostAdvancedSettingsHasValue("UserVars.SuppressShellWarning", "1"),
+							resource.TestCheckResourceAttr("vsphere_host_advanced_settings.settings", "settings.UserVars.SuppressShellWarning", "01"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"update and remove setting",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccHostConfigPreCheck(tp)
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereHostAdvancedSettingsConfig(`
+    "UserVars.SuppressShellWarning" = "1"
+    "Syslog.global.logHost"         = "udp://192.0.2.10:514"
+`),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereHostAdvancedSettingsHasValue("UserVars.SuppressShellWarning", "1"),
+							testAccResourceVSphereHostAdvancedSettingsHasValue("Syslog.global.logHost", "udp://192.0.2.10:514"),
+						),
+					},
+					{
+						Config: testAccResourceVSphereHostAdvancedSettingsConfig(`"UserVars.SuppressShellWarning" = "0"`),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereHostAdvancedSettingsHasValue("UserVars.SuppressShellWarning", "0"),
+							testAccResourceVSphereHostAdvancedSettingsHasValue("Syslog.global.logHost", ""),
+						),
+					},
+				},
+			},
+		},
+		{
+			"import",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccHostConfigPreCheck(tp)
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereHostAdvancedSettingsConfig(`"UserVars.SuppressShellWarning" = "1"`),
+					},
+					{
+						ResourceName:      "vsphere_host_advanced_settings.settings",
+						ImportState:       true,
+						ImportStateVerify: true,
+						ImportStateIdFunc: func(s *terraform.State) (string, error) {
+							rs, ok := s.RootModule().Resources["vsphere_host_advanced_settings.settings"]
+							if !ok {
+								return "", errors.New("no resource at address vsphere_host_advanced_settings.settings")
+							}
+							return fmt.Sprintf("%s:UserVars.SuppressShellWarning", rs.Primary.ID), nil
+						},
+						Config: testAccResourceVSphereHostAdvancedSettingsConfig(`"UserVars.SuppressShellWarning" = "1"`),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccResourceVSphereHostAdvancedSettingsCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+func testAccResourceVSphereHostAdvancedSettingsHasValue(key, expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		vars, err := testClientVariablesForResource(s, "vsphere_host_advanced_settings.settings")
+		if err != nil {
+			return err
+		}
+		om, err := hostOptionManagerFromHostSystemID(vars.client, vars.resourceID)
+		if err != nil {
+			return err
+		}
+		props, err := hostOptionManagerProperties(om)
+		if err != nil {
+			return err
+		}
+		actual, ok := flattenHostOptionValues(props.Setting)[key]
+		if !ok {
+			return fmt.Errorf("advanced setting %q not found", key)
+		}
+		if expected != actual {
+			return fmt.Errorf("expected advanced setting %q to be %q, got %q", key, expected, actual)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereHostAdvancedSettingsConfig(settings string) string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_host_advanced_settings" "settings" {
+  host_system_id = "${data.vsphere_host.esxi_host.id}"
+
+  settings = {
+    %s
+  }
+}
+`,
+		testAccHostConfigDataSources(),
+		settings,
+	)
+}
+
+func TestHostOptionValueEqual(t *testing.T) {
+	cases := []struct {
+		name     string
+		def      types.OptionDef
+		value    string
+		current  string
+		expected bool
+	}{
+		{
+			name:     "bool in upper case",
+			def:      types.OptionDef{OptionType: &types.BoolOption{}},
+			value:    "TRUE",
+			current:  "true",
+			expected: true,
+		},
+		{
+			name:     "bool as number",
+			def:      types.OptionDef{OptionType: &types.BoolOption{}},
+			value:    "1",
+			current:  "true",
+			expected: true,
+		},
+		{
+			name:     "int with leading zero",
+			def:      types.OptionDef{OptionType: &types.IntOption{}},
+			value:    "010",
+			current:  "10",
+			expected: true,
+		},
+		{
+			name:     "different int",
+			def:      types.OptionDef{OptionType: &types.LongOption{}},
+			value:    "11",
+			current:  "10",
+			expected: false,
+		},
+		{
+			name:     "string is compared as is",
+			def:      types.OptionDef{OptionType: &types.StringOption{}},
+			value:    "TRUE",
+			current:  "true",
+			expected: false,
+		},
+		{
+			name:     "invalid value",
+			def:      types.OptionDef{OptionType: &types.IntOption{}},
+			value:    "ten",
+			current:  "10",
+			expected: false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := hostOptionValueEqual(tc.def, tc.value, tc.current); actual != tc.expected {
+				t.Fatalf("expected %t, got %t", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package vsphere
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func resourceVSphereHostDNS() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereHostDNSCreate,
+		Read:   resourceVSphereHostDNSRead,
+		Update: resourceVSphereHostDNSUpdate,
+		Delete: resourceVSphereHostDNSDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceVSphereHostDNSImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"host_system_id": {
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the host to configure DNS on.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"dhcp": {
+				Type:        schema.TypeBool,
+				Description: "Obtain the DNS configuration through DHCP on the VMkernel adapter in virtual_nic_device.",
+				Optional:    true,
+				Default:     false,
+			},
+			"virtual_nic_device": {
+				Type:        schema.TypeString,
+				Description: "The VMkernel adapter to obtain the DNS configuration from, when dhcp is true.",
+				Optional:    true,
+			},
+			"host_name": {
+				Type:        schema.TypeString,
+				Description: "The host name of the host.",
+				Required:    true,
+			},
+			"domain_name": {
+				Type:        schema.TypeString,
+				Description: "The domain name of the host.",
+				Optional:    true,
+			},
+			"servers": {
+				Type:        schema.TypeList,
+				Description: "The list of DNS servers to use. Ignored when dhcp is true.",
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"search_domains": {
+				Type:        schema.TypeList,
+				Description: "The list of domains to search when resolving unqualified names. Ignored when dhcp is true.",
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func resourceVSphereHostDNSCreate(d *schema.ResourceData, meta interface{}) error {
+	if err := resourceVSphereHostDNSApply(d, meta); err != nil {
+		return err
+	}
+	d.SetId(d.Get("host_system_id").(string))
+	return resourceVSphereHostDNSRead(d, meta)
+}
+
+func resourceVSphereHostDNSRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	ns, err := hostNetworkSystemFromHostSystemID(client, d.Id())
+	if err != nil {
+		return fmt.Errorf("error loading host network system: %s", err)
+	}
+	config, err := hostDNSConfigFromHostNetworkSystem(client, ns)
+	if err != nil {
+		return err
+	}
+	d.Set("host_system_id", d.Id())
+	return flattenHostDNSConfig(d, config)
+}
+
+func resourceVSphereHostDNSUpdate(d *schema.ResourceData, meta interface{}) error {
+	if err := resourceVSphereHostDNSApply(d, meta); err != nil {
+		return err
+	}
+	return resourceVSphereHostDNSRead(d, meta)
+}
+
+func resourceVSphereHostDNSDelete(d *schema.ResourceData, meta interface{}) error {
+	// The DNS configuration of a host cannot be removed, and clearing it could
+	// break name resolution on the host, so we leave it as is and just remove
+	// the resource from state.
+	return nil
+}
+
+func resourceVSphereHostDNSImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	// We import with the host system ID, which is also the ID of the resource.
+	d.Set("host_system_id", d.Id())
+	return []*schema.ResourceData{d}, nil
+}
+
+// resourceVSphereHostDNSApply applies the DNS configuration in the resource
+// to the host.
+func resourceVSphereHostDNSApply(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if d.Get("dhcp").(bool) && d.Get("virtual_nic_device").(string) == "" {
+		return errors.New("virtual_nic_device must be set when dhcp is true")
+	}
+	ns, err := hostNetworkSystemFromHostSystemID(client, d.Get("host_system_id").(string))
+	if err != nil {
+		return fmt.Errorf("error loading host network system: %s", err)
+	}
+	if err := updateHostDNSConfig(client, ns, expandHostDNSConfig(d)); err != nil {
+		return fmt.Errorf("error updating DNS configuration: %s", err)
+	}
+	return nil
+}
+
+// expandHostDNSConfig reads certain ResourceData keys and returns a
+// HostDnsConfig.
+func expandHostDNSConfig(d *schema.ResourceData) *types.HostDnsConfig {
+	obj := &types.HostDnsConfig{
+		Dhcp:       d.Get("dhcp").(bool),
+		HostName:   d.Get("host_name").(string),
+		DomainName: d.Get("domain_name").(string),
+	}
+	if obj.Dhcp {
+		obj.VirtualNicDevice = d.Get("virtual_nic_device").(string)
+	} else {
+		obj.Address = sliceInterfacesToStrings(d.Get("servers").([]interface{}))
+		obj.SearchDomain = sliceInterfacesToStrings(d.Get("search_domains").([]interface{}))
+	}
+	return obj
+}
+
+// flattenHostDNSConfig reads various fields from a HostDnsConfig into the
+// passed in ResourceData.
+//
+// The servers and search domains are only read when DHCP is disabled, as they
+// are otherwise supplied by the DHCP server.
+func flattenHostDNSConfig(d *schema.ResourceData, obj *types.HostDnsConfig) error {
+	d.Set("dhcp", obj.Dhcp)
+	d.Set("virtual_nic_device", obj.VirtualNicDevice)
+	d.Set("host_name", obj.HostName)
+	d.Set("domain_name", obj.DomainName)
+	if obj.Dhcp {
+		return nil
+	}
+	if err := d.Set("servers", obj.Address); err != nil {
+		return fmt.Errorf("error setting DNS servers: %s", err)
+	}
+	if err := d.Set("search_domains", obj.SearchDomain); err != nil {
+		return fmt.Errorf("error setting DNS search domains: %s", err)
+	}
+	return nil
+}
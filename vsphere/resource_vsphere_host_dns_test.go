@@ -0,0 +1,140 @@
+package vsphere
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestAccResourceVSphereHostDNS(t *testing.T) {
+	var tp *testing.T
+	testAccResourceVSphereHostDNSCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"basic",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccHostConfigPreCheck(tp)
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereHostDNSConfig("terraform-test.local", `"8.8.8.8"`),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereHostDNSMatch(func(config *types.HostDnsConfig) error {
+								if config.DomainName != "terraform-test.local" {
+									return fmt.Errorf("expected domain name to be terraform-test.local, got %q", config.DomainName)
+								}
+								if !reflect.DeepEqual(config.Address, []string{"8.8.8.8"}) {
+									return fmt.Errorf("expected DNS servers to be [8.8.8.8], got %v", config.Address)
+								}
+								return nil
+							}),
+						),
+					},
+				},
+			},
+		},
+		{
+			"update",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccHostConfigPreCheck(tp)
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereHostDNSConfig("terraform-test.local", `"8.8.8.8"`),
+					},
+					{
+						Config: testAccResourceVSphereHostDNSConfig("terraform-test-updated.local", `"8.8.4.4", "8.8.8.8"`),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereHostDNSMatch(func(config *types.HostDnsConfig) error {
+								if config.DomainName != "terraform-test-updated.local" {
+									return fmt.Errorf("expected domain name to be terraform-test-updated.local, got %q", config.DomainName)
+								}
+								if !reflect.DeepEqual(config.Address, []string{"8.8.4.4", "8.8.8.8"}) {
+									return fmt.Errorf("expected DNS servers to be [8.8.4.4 8.8.8.8], got %v", config.Address)
+								}
+								return nil
+							}),
+						),
+					},
+				},
+			},
+		},
+		{
+			"import",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccHostConfigPreCheck(tp)
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereHostDNSConfig("terraform-test.local", `"8.8.8.8"`),
+					},
+					{
+						ResourceName:      "vsphere_host_dns.host_dns",
+						ImportState:       true,
+						ImportStateVerify: true,
+						Config:            testAccResourceVSphereHostDNSConfig("terraform-test.local", `"8.8.8.8"`),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccResourceVSphereHostDNSCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+func testAccResourceVSphereHostDNSMatch(f func(*types.HostDnsConfig) error) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		vars, err := testClientVariablesForResource(s, "vsphere_host_dns.host_dns")
+		if err != nil {
+			return err
+		}
+		ns, err := hostNetworkSystemFromHostSystemID(vars.client, vars.resourceID)
+		if err != nil {
+			return err
+		}
+		config, err := hostDNSConfigFromHostNetworkSystem(vars.client, ns)
+		if err != nil {
+			return err
+		}
+		return f(config)
+	}
+}
+
+func testAccResourceVSphereHostDNSConfig(domainName, servers string) string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_host_dns" "host_dns" {
+  host_system_id = "${data.vsphere_host.esxi_host.id}"
+  host_name      = "terraform-test-esxi"
+  domain_name    = "%s"
+  servers        = [%s]
+  search_domains = ["%s"]
+}
+`,
+		testAccHostConfigDataSources(),
+		domainName,
+		servers,
+		domainName,
+	)
+}
@@ -0,0 +1,224 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func resourceVSphereHostFirewallRuleset() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereHostFirewallRulesetCreate,
+		Read:   resourceVSphereHostFirewallRulesetRead,
+		Update: resourceVSphereHostFirewallRulesetUpdate,
+		Delete: resourceVSphereHostFirewallRulesetDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceVSphereHostFirewallRulesetImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"host_system_id": {
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the host the firewall ruleset is on.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"key": {
+				Type:        schema.TypeString,
+				Description: "The key of the firewall ruleset, such as sshServer or ntpClient.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"enabled": {
+				Type:        schema.TypeBool,
+				Description: "Whether or not the firewall ruleset is enabled.",
+				Required:    true,
+			},
+			"allowed_all_ip": {
+				Type:        schema.TypeBool,
+				Description: "Allow connections from all IP addresses. When false, only the addresses in allowed_ip_addresses and allowed_networks are allowed.",
+				Optional:    true,
+				Default:     true,
+			},
+			"allowed_ip_addresses": {
+				Type:        schema.TypeList,
+				Description: "The IP addresses that are allowed to connect, when allowed_all_ip is false.",
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"allowed_networks": {
+				Type:        schema.TypeList,
+				Description: "The networks, in CIDR notation, that are allowed to connect, when allowed_all_ip is false.",
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"label": {
+				Type:        schema.TypeString,
+				Description: "The display name of the firewall ruleset.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func resourceVSphereHostFirewallRulesetCreate(d *schema.ResourceData, meta interface{}) error {
+	fs, ruleset, err := resourceVSphereHostFirewallRulesetObjects(d, meta)
+	if err != nil {
+		return err
+	}
+	if ruleset == nil {
+		return fmt.Errorf("firewall ruleset %q not found on host %q", d.Get("key").(string), d.Get("host_system_id").(string))
+	}
+	if err := resourceVSphereHostFirewallRulesetApply(d, meta, fs, ruleset); err != nil {
+		return err
+	}
+
+	d.SetId(fmt.Sprintf("%s:%s", d.Get("host_system_id").(string), d.Get("key").(string)))
+	return resourceVSphereHostFirewallRulesetRead(d, meta)
+}
+
+func resourceVSphereHostFirewallRulesetRead(d *schema.ResourceData, meta interface{}) error {
+	_, ruleset, err := resourceVSphereHostFirewallRulesetObjects(d, meta)
+	if err != nil {
+		return err
+	}
+	if ruleset == nil {
+		log.Printf("[DEBUG] Firewall ruleset %q not found on host %q, marking as gone", d.Get("key").(string), d.Get("host_system_id").(string))
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("enabled", ruleset.Enabled)
+	d.Set("label", ruleset.Label)
+	return flattenHostFirewallRulesetIPList(d, ruleset.AllowedHosts)
+}
+
+func resourceVSphereHostFirewallRulesetUpdate(d *schema.ResourceData, meta interface{}) error {
+	fs, ruleset, err := resourceVSphereHostFirewallRulesetObjects(d, meta)
+	if err != nil {
+		return err
+	}
+	if ruleset == nil {
+		return fmt.Errorf("firewall ruleset %q not found on host %q", d.Get("key").(string), d.Get("host_system_id").(string))
+	}
+	if err := resourceVSphereHostFirewallRulesetApply(d, meta, fs, ruleset); err != nil {
+		return err
+	}
+	return resourceVSphereHostFirewallRulesetRead(d, meta)
+}
+
+func resourceVSphereHostFirewallRulesetDelete(d *schema.ResourceData, meta interface{}) error {
+	// Firewall rulesets can't be removed from a host, so we leave the ruleset
+	// as is and just remove the resource from state.
+	return nil
+}
+
+func resourceVSphereHostFirewallRulesetImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	// We import with the same ID that the resource is saved with, which is the
+	// host system ID and the ruleset key, separated by a colon.
+	parts := strings.SplitN(d.Id(), ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("invalid ID %q, expected HOST_SYSTEM_ID:KEY", d.Id())
+	}
+	d.Set("host_system_id", parts[0])
+	d.Set("key", parts[1])
+	return []*schema.ResourceData{d}, nil
+}
+
+// resourceVSphereHostFirewallRulesetObjects fetches the firewall system and
+// the ruleset for the resource. The ruleset is nil if it does not exist.
+func resourceVSphereHostFirewallRulesetObjects(d *schema.ResourceData, meta interface{}) (*object.HostFirewallSystem, *types.HostFirewallRuleset, error) {
+	client := meta.(*VSphereClient).vimClient
+	fs, err := hostFirewallSystemFromHostSystemID(client, d.Get("host_system_id").(string))
+	if err != nil {
+		return nil, nil, fmt.Errorf("error loading host firewall system: %s", err)
+	}
+	ruleset, err := hostFirewallRulesetFromKey(fs, d.Get("key").(string))
+	if err != nil {
+		return nil, nil, fmt.Errorf("error fetching host firewall rulesets: %s", err)
+	}
+	return fs, ruleset, nil
+}
+
+// resourceVSphereHostFirewallRulesetApply applies the enabled state and
+// allowed hosts in the resource to the supplied ruleset. The allowed hosts are
+// always sent when the resource is created, as the existing settings of the
+// ruleset on the host are not reflected in the diff.
+func resourceVSphereHostFirewallRulesetApply(d *schema.ResourceData, meta interface{}, fs *object.HostFirewallSystem, ruleset *types.HostFirewallRuleset) error {
+	client := meta.(*VSphereClient).vimClient
+	if d.IsNewResource() || d.HasChange("allowed_all_ip") || d.HasChange("allowed_ip_addresses") || d.HasChange("allowed_networks") {
+		ipList, err := expandHostFirewallRulesetIPList(d)
+		if err != nil {
+			return err
+		}
+		spec := types.HostFirewallRulesetRulesetSpec{
+			AllowedHosts: *ipList,
+		}
+		if err := updateHostFirewallRuleset(client, fs, ruleset.Key, spec); err != nil {
+			return fmt.Errorf("error updating allowed hosts for firewall ruleset %q: %s", ruleset.Key, err)
+		}
+	}
+
+	enabled := d.Get("enabled").(bool)
+	switch {
+	case enabled && !ruleset.Enabled:
+		if err := enableHostFirewallRuleset(fs, ruleset.Key); err != nil {
+			return fmt.Errorf("error enabling firewall ruleset %q: %s", ruleset.Key, err)
+		}
+	case !enabled && ruleset.Enabled:
+		if err := disableHostFirewallRuleset(fs, ruleset.Key); err != nil {
+			return fmt.Errorf("error disabling firewall ruleset %q: %s", ruleset.Key, err)
+		}
+	}
+	return nil
+}
+
+// expandHostFirewallRulesetIPList reads certain ResourceData keys and returns
+// a HostFirewallRulesetIpList.
+func expandHostFirewallRulesetIPList(d *schema.ResourceData) (*types.HostFirewallRulesetIpList, error) {
+	obj := &types.HostFirewallRulesetIpList{
+		AllIp:     d.Get("allowed_all_ip").(bool),
+		IpAddress: sliceInterfacesToStrings(d.Get("allowed_ip_addresses").([]interface{})),
+	}
+	for _, v := range d.Get("allowed_networks").([]interface{}) {
+		_, ipNet, err := net.ParseCIDR(v.(string))
+		if err != nil {
+			return nil, fmt.Errorf("invalid network %q in allowed_networks: %s", v.(string), err)
+		}
+		prefix, _ := ipNet.Mask.Size()
+		if ipNet.String() != v.(string) {
+			return nil, fmt.Errorf("network %q in allowed_networks is not a network address, did you mean %q?", v.(string), ipNet.String())
+		}
+		obj.IpNetwork = append(obj.IpNetwork, types.HostFirewallRulesetIpNetwork{
+			Network:      ipNet.IP.String(),
+			PrefixLength: int32(prefix),
+		})
+	}
+	return obj, nil
+}
+
+// flattenHostFirewallRulesetIPList reads various fields from a
+// HostFirewallRulesetIpList into the passed in ResourceData.
+func flattenHostFirewallRulesetIPList(d *schema.ResourceData, obj *types.HostFirewallRulesetIpList) error {
+	if obj == nil {
+		obj = &types.HostFirewallRulesetIpList{AllIp: true}
+	}
+	d.Set("allowed_all_ip", obj.AllIp)
+	if err := d.Set("allowed_ip_addresses", obj.IpAddress); err != nil {
+		return fmt.Errorf("error setting allowed IP addresses: %s", err)
+	}
+	var networks []string
+	for _, n := range obj.IpNetwork {
+		networks = append(networks, n.Network+"/"+strconv.Itoa(int(n.PrefixLength)))
+	}
+	if err := d.Set("allowed_networks", networks); err != nil {
+		return fmt.Errorf("error setting allowed networks: %s", err)
+	}
+	return nil
+}
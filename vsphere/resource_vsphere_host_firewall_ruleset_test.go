@@ -0,0 +1,222 @@
+package vsphere
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestAccResourceVSphereHostFirewallRuleset(t *testing.T) {
+	var tp *testing.T
+	testAccResourceVSphereHostFirewallRulesetCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"basic",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccHostConfigPreCheck(tp)
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereHostFirewallRulesetConfigAllIP(true),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereHostFirewallRulesetMatch(func(ruleset *types.HostFirewallRuleset) error {
+								if !ruleset.Enabled {
+									return errors.New("expected ruleset to be enabled")
+								}
+								if ruleset.AllowedHosts == nil || !ruleset.AllowedHosts.AllIp {
+									return errors.New("expected ruleset to allow all IP addresses")
+								}
+								return nil
+							}),
+						),
+					},
+				},
+			},
+		},
+		{
+			"restrict allowed hosts",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccHostConfigPreCheck(tp)
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereHostFirewallRulesetConfigAllIP(true),
+					},
+					{
+						Config: testAccResourceVSphereHostFirewallRulesetConfigRestricted(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereHostFirewallRulesetMatch(func(ruleset *types.HostFirewallRuleset) error {
+								if ruleset.AllowedHosts == nil || ruleset.AllowedHosts.AllIp {
+									return errors.New("expected ruleset to not allow all IP addresses")
+								}
+								if len(ruleset.AllowedHosts.IpNetwork) != 1 || ruleset.AllowedHosts.IpNetwork[0].Network != "10.0.0.0" || ruleset.AllowedHosts.IpNetwork[0].PrefixLength != 8 {
+									return fmt.Errorf("expected allowed networks to be 10.0.0.0/8, got %#v", ruleset.AllowedHosts.IpNetwork)
+								}
+								return nil
+							}),
+						),
+					},
+				},
+			},
+		},
+		{
+			"create with no allowed hosts",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccHostConfigPreCheck(tp)
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereHostFirewallRulesetConfigNoHosts(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereHostFirewallRulesetMatch(func(ruleset *types.HostFirewallRuleset) error {
+								if ruleset.AllowedHosts == nil || ruleset.AllowedHosts.AllIp {
+									return errors.New("expected ruleset to not allow all IP addresses")
+								}
+								if len(ruleset.AllowedHosts.IpAddress) > 0 || len(ruleset.AllowedHosts.IpNetwork) > 0 {
+									return fmt.Errorf("expected no allowed hosts, got %#v", ruleset.AllowedHosts)
+								}
+								return nil
+							}),
+						),
+					},
+				},
+			},
+		},
+		{
+			"disable",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccHostConfigPreCheck(tp)
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereHostFirewallRulesetConfigAllIP(false),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereHostFirewallRulesetMatch(func(ruleset *types.HostFirewallRuleset) error {
+								if ruleset.Enabled {
+									return errors.New("expected ruleset to be disabled")
+								}
+								return nil
+							}),
+						),
+					},
+				},
+			},
+		},
+		{
+			"import",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccHostConfigPreCheck(tp)
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereHostFirewallRulesetConfigRestricted(),
+					},
+					{
+						ResourceName:      "vsphere_host_firewall_ruleset.ruleset",
+						ImportState:       true,
+						ImportStateVerify: true,
+						Config:            testAccResourceVSphereHostFirewallRulesetConfigRestricted(),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccResourceVSphereHostFirewallRulesetCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+func testAccResourceVSphereHostFirewallRulesetMatch(f func(*types.HostFirewallRuleset) error) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		vars, err := testClientVariablesForResource(s, "vsphere_host_firewall_ruleset.ruleset")
+		if err != nil {
+			return err
+		}
+		fs, err := hostFirewallSystemFromHostSystemID(vars.client, vars.resourceAttributes["host_system_id"])
+		if err != nil {
+			return err
+		}
+		ruleset, err := hostFirewallRulesetFromKey(fs, vars.resourceAttributes["key"])
+		if err != nil {
+			return err
+		}
+		if ruleset == nil {
+			return errors.New("firewall ruleset not found")
+		}
+		return f(ruleset)
+	}
+}
+
+// The tests use the syslog ruleset, as it is disabled by default and changing
+// it does not affect access to the host.
+func testAccResourceVSphereHostFirewallRulesetConfigAllIP(enabled bool) string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_host_firewall_ruleset" "ruleset" {
+  host_system_id = "${data.vsphere_host.esxi_host.id}"
+  key            = "syslog"
+  enabled        = %t
+}
+`,
+		testAccHostConfigDataSources(),
+		enabled,
+	)
+}
+
+func testAccResourceVSphereHostFirewallRulesetConfigRestricted() string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_host_firewall_ruleset" "ruleset" {
+  host_system_id       = "${data.vsphere_host.esxi_host.id}"
+  key                  = "syslog"
+  enabled              = true
+  allowed_all_ip       = false
+  allowed_ip_addresses = ["192.168.0.10"]
+  allowed_networks     = ["10.0.0.0/8"]
+}
+`,
+		testAccHostConfigDataSources(),
+	)
+}
+
+func testAccResourceVSphereHostFirewallRulesetConfigNoHosts() string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_host_firewall_ruleset" "ruleset" {
+  host_system_id = "${data.vsphere_host.esxi_host.id}"
+  key            = "syslog"
+  enabled        = true
+  allowed_all_ip = false
+}
+`,
+		testAccHostConfigDataSources(),
+	)
+}
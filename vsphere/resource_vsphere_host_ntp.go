@@ -0,0 +1,104 @@
+package vsphere
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func resourceVSphereHostNTP() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereHostNTPCreate,
+		Read:   resourceVSphereHostNTPRead,
+		Update: resourceVSphereHostNTPUpdate,
+		Delete: resourceVSphereHostNTPDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceVSphereHostNTPImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"host_system_id": {
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the host to configure NTP on.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"servers": {
+				Type:        schema.TypeList,
+				Description: "The list of NTP servers that the host should synchronize with.",
+				Required:    true,
+				MinItems:    1,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func resourceVSphereHostNTPCreate(d *schema.ResourceData, meta interface{}) error {
+	if err := resourceVSphereHostNTPApply(d, meta); err != nil {
+		return err
+	}
+	d.SetId(d.Get("host_system_id").(string))
+	return resourceVSphereHostNTPRead(d, meta)
+}
+
+func resourceVSphereHostNTPRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	dts, err := hostDateTimeSystemFromHostSystemID(client, d.Id())
+	if err != nil {
+		return fmt.Errorf("error loading host date and time system: %s", err)
+	}
+	props, err := hostDateTimeSystemProperties(dts)
+	if err != nil {
+		return fmt.Errorf("error fetching host date and time properties: %s", err)
+	}
+	d.Set("host_system_id", d.Id())
+	var servers []string
+	if props.DateTimeInfo.NtpConfig != nil {
+		servers = props.DateTimeInfo.NtpConfig.Server
+	}
+	if err := d.Set("servers", servers); err != nil {
+		return fmt.Errorf("error setting NTP servers: %s", err)
+	}
+	return nil
+}
+
+func resourceVSphereHostNTPUpdate(d *schema.ResourceData, meta interface{}) error {
+	if err := resourceVSphereHostNTPApply(d, meta); err != nil {
+		return err
+	}
+	return resourceVSphereHostNTPRead(d, meta)
+}
+
+func resourceVSphereHostNTPDelete(d *schema.ResourceData, meta interface{}) error {
+	// The NTP configuration of a host cannot be removed, and clearing it could
+	// leave the host without a time source, so we leave it as is and just
+	// remove the resource from state.
+	return nil
+}
+
+func resourceVSphereHostNTPImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	// We import with the host system ID, which is also the ID of the resource.
+	d.Set("host_system_id", d.Id())
+	return []*schema.ResourceData{d}, nil
+}
+
+// resourceVSphereHostNTPApply applies the NTP servers in the resource to the
+// host.
+func resourceVSphereHostNTPApply(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	dts, err := hostDateTimeSystemFromHostSystemID(client, d.Get("host_system_id").(string))
+	if err != nil {
+		return fmt.Errorf("error loading host date and time system: %s", err)
+	}
+	config := types.HostDateTimeConfig{
+		NtpConfig: &types.HostNtpConfig{
+			Server: sliceInterfacesToStrings(d.Get("servers").([]interface{})),
+		},
+	}
+	if err := updateHostDateTimeConfig(dts, config); err != nil {
+		return fmt.Errorf("error updating NTP configuration: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package vsphere
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccResourceVSphereHostNTP(t *testing.T) {
+	var tp *testing.T
+	testAccResourceVSphereHostNTPCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"basic",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccHostConfigPreCheck(tp)
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereHostNTPConfig(`"0.pool.ntp.org"`),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereHostNTPHasServers([]string{"0.pool.ntp.org"}),
+						),
+					},
+				},
+			},
+		},
+		{
+			"update servers",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccHostConfigPreCheck(tp)
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereHostNTPConfig(`"0.pool.ntp.org"`),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereHostNTPHasServers([]string{"0.pool.ntp.org"}),
+						),
+					},
+					{
+						Config: testAccResourceVSphereHostNTPConfig(`"1.pool.ntp.org", "2.pool.ntp.org"`),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereHostNTPHasServers([]string{"1.pool.ntp.org", "2.pool.ntp.org"}),
+						),
+					},
+				},
+			},
+		},
+		{
+			"import",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccHostConfigPreCheck(tp)
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereHostNTPConfig(`"0.pool.ntp.org"`),
+					},
+					{
+						ResourceName:      "vsphere_host_ntp.host_ntp",
+						ImportState:       true,
+						ImportStateVerify: true,
+						Config:            testAccResourceVSphereHostNTPConfig(`"0.pool.ntp.org"`),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccResourceVSphereHostNTPCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+func testAccResourceVSphereHostNTPHasServers(expected []string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		vars, err := testClientVariablesForResource(s, "vsphere_host_ntp.host_ntp")
+		if err != nil {
+			return err
+		}
+		dts, err := hostDateTimeSystemFromHostSystemID(vars.client, vars.resourceID)
+		if err != nil {
+			return err
+		}
+		props, err := hostDateTimeSystemProperties(dts)
+		if err != nil {
+			return err
+		}
+		var actual []string
+		if props.DateTimeInfo.NtpConfig != nil {
+			actual = props.DateTimeInfo.NtpConfig.Server
+		}
+		if !reflect.DeepEqual(expected, actual) {
+			return fmt.Errorf("expected NTP servers to be %v, got %v", expected, actual)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereHostNTPConfig(servers string) string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_host_ntp" "host_ntp" {
+  host_system_id = "${data.vsphere_host.esxi_host.id}"
+  servers        = [%s]
+}
+`,
+		testAccHostConfigDataSources(),
+		servers,
+	)
+}
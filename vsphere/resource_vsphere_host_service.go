@@ -0,0 +1,165 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+var hostServicePolicyAllowedValues = []string{
+	string(types.HostServicePolicyOn),
+	string(types.HostServicePolicyOff),
+	string(types.HostServicePolicyAutomatic),
+}
+
+func resourceVSphereHostService() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereHostServiceCreate,
+		Read:   resourceVSphereHostServiceRead,
+		Update: resourceVSphereHostServiceUpdate,
+		Delete: resourceVSphereHostServiceDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceVSphereHostServiceImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"host_system_id": {
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the host the service runs on.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"key": {
+				Type:        schema.TypeString,
+				Description: "The key of the service, such as TSM-SSH, TSM, or ntpd.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"policy": {
+				Type:         schema.TypeString,
+				Description:  "The startup policy of the service. Can be one of on, off, or automatic.",
+				Required:     true,
+				ValidateFunc: validation.StringInSlice(hostServicePolicyAllowedValues, false),
+			},
+			"running": {
+				Type:        schema.TypeBool,
+				Description: "Whether or not the service should be running.",
+				Required:    true,
+			},
+			"label": {
+				Type:        schema.TypeString,
+				Description: "The display name of the service.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func resourceVSphereHostServiceCreate(d *schema.ResourceData, meta interface{}) error {
+	ss, service, err := resourceVSphereHostServiceObjects(d, meta)
+	if err != nil {
+		return err
+	}
+	if service == nil {
+		return fmt.Errorf("service %q not found on host %q", d.Get("key").(string), d.Get("host_system_id").(string))
+	}
+	if err := resourceVSphereHostServiceApply(d, ss, service); err != nil {
+		return err
+	}
+
+	d.SetId(fmt.Sprintf("%s:%s", d.Get("host_system_id").(string), d.Get("key").(string)))
+	return resourceVSphereHostServiceRead(d, meta)
+}
+
+func resourceVSphereHostServiceRead(d *schema.ResourceData, meta interface{}) error {
+	_, service, err := resourceVSphereHostServiceObjects(d, meta)
+	if err != nil {
+		return err
+	}
+	if service == nil {
+		log.Printf("[DEBUG] Service %q not found on host %q, marking as gone", d.Get("key").(string), d.Get("host_system_id").(string))
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("policy", service.Policy)
+	d.Set("running", service.Running)
+	d.Set("label", service.Label)
+	return nil
+}
+
+func resourceVSphereHostServiceUpdate(d *schema.ResourceData, meta interface{}) error {
+	ss, service, err := resourceVSphereHostServiceObjects(d, meta)
+	if err != nil {
+		return err
+	}
+	if service == nil {
+		return fmt.Errorf("service %q not found on host %q", d.Get("key").(string), d.Get("host_system_id").(string))
+	}
+	if err := resourceVSphereHostServiceApply(d, ss, service); err != nil {
+		return err
+	}
+	return resourceVSphereHostServiceRead(d, meta)
+}
+
+func resourceVSphereHostServiceDelete(d *schema.ResourceData, meta interface{}) error {
+	// Services can't be removed from a host, and there is no default state to
+	// return them to, so we leave the service as is and just remove the
+	// resource from state.
+	return nil
+}
+
+func resourceVSphereHostServiceImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	// We import with the same ID that the resource is saved with, which is the
+	// host system ID and the service key, separated by a colon.
+	parts := strings.SplitN(d.Id(), ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("invalid ID %q, expected HOST_SYSTEM_ID:KEY", d.Id())
+	}
+	d.Set("host_system_id", parts[0])
+	d.Set("key", parts[1])
+	return []*schema.ResourceData{d}, nil
+}
+
+// resourceVSphereHostServiceObjects fetches the service system and the
+// service for the resource. The service is nil if it does not exist.
+func resourceVSphereHostServiceObjects(d *schema.ResourceData, meta interface{}) (*object.HostServiceSystem, *types.HostService, error) {
+	client := meta.(*VSphereClient).vimClient
+	ss, err := hostServiceSystemFromHostSystemID(client, d.Get("host_system_id").(string))
+	if err != nil {
+		return nil, nil, fmt.Errorf("error loading host service system: %s", err)
+	}
+	service, err := hostServiceFromKey(ss, d.Get("key").(string))
+	if err != nil {
+		return nil, nil, fmt.Errorf("error fetching host services: %s", err)
+	}
+	return ss, service, nil
+}
+
+// resourceVSphereHostServiceApply applies the policy and running state in the
+// resource to the supplied service.
+func resourceVSphereHostServiceApply(d *schema.ResourceData, ss *object.HostServiceSystem, service *types.HostService) error {
+	policy := d.Get("policy").(string)
+	if service.Policy != policy {
+		if err := updateHostServicePolicy(ss, service.Key, policy); err != nil {
+			return fmt.Errorf("error updating policy for service %q: %s", service.Key, err)
+		}
+	}
+	running := d.Get("running").(bool)
+	switch {
+	case running && !service.Running:
+		if err := startHostService(ss, service.Key); err != nil {
+			return fmt.Errorf("error starting service %q: %s", service.Key, err)
+		}
+	case !running && service.Running:
+		if err := stopHostService(ss, service.Key); err != nil {
+			return fmt.Errorf("error stopping service %q: %s", service.Key, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package vsphere
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestAccResourceVSphereHostService(t *testing.T) {
+	var tp *testing.T
+	testAccResourceVSphereHostServiceCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"basic",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccHostConfigPreCheck(tp)
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereHostServiceConfig("on", true),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereHostServiceMatch("on", true),
+							resource.TestCheckResourceAttrSet("vsphere_host_service.ssh", "label"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"stop and disable",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccHostConfigPreCheck(tp)
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereHostServiceConfig("on", true),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereHostServiceMatch("on", true),
+						),
+					},
+					{
+						Config: testAccResourceVSphereHostServiceConfig("off", false),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereHostServiceMatch("off", false),
+						),
+					},
+				},
+			},
+		},
+		{
+			"import",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccHostConfigPreCheck(tp)
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereHostServiceConfig("off", false),
+					},
+					{
+						ResourceName:      "vsphere_host_service.ssh",
+						ImportState:       true,
+						ImportStateVerify: true,
+						Config:            testAccResourceVSphereHostServiceConfig("off", false),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccResourceVSphereHostServiceCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+func testAccResourceVSphereHostServiceMatch(policy string, running bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		service, err := testAccResourceVSphereHostServiceGet(s)
+		if err != nil {
+			return err
+		}
+		if service.Policy != policy {
+			return fmt.Errorf("expected service policy to be %q, got %q", policy, service.Policy)
+		}
+		if service.Running != running {
+			return fmt.Errorf("expected service running to be %t, got %t", running, service.Running)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereHostServiceGet fetches the SSH service on the test
+// host.
+func testAccResourceVSphereHostServiceGet(s *terraform.State) (*types.HostService, error) {
+	vars, err := testClientVariablesForResource(s, "vsphere_host_service.ssh")
+	if err != nil {
+		return nil, err
+	}
+	ss, err := hostServiceSystemFromHostSystemID(vars.client, vars.resourceAttributes["host_system_id"])
+	if err != nil {
+		return nil, err
+	}
+	service, err := hostServiceFromKey(ss, "TSM-SSH")
+	if err != nil {
+		return nil, err
+	}
+	if service == nil {
+		return nil, errors.New("SSH service not found")
+	}
+	return service, nil
+}
+
+func testAccResourceVSphereHostServiceConfig(policy string, running bool) string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_host_service" "ssh" {
+  host_system_id = "${data.vsphere_host.esxi_host.id}"
+  key            = "TSM-SSH"
+  policy         = "%s"
+  running        = %t
+}
+`,
+		testAccHostConfigDataSources(),
+		policy,
+		running,
+	)
+}
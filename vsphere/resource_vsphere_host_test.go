@@ -0,0 +1,264 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestAccResourceVSphereHost(t *testing.T) {
+	var tp *testing.T
+	testAccResourceVSphereHostCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"standalone",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereHostPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereHostExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereHostConfigStandalone(false),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereHostExists(true),
+							testAccResourceVSphereHostHasParentType("ComputeResource"),
+							testAccResourceVSphereHostInMaintenanceMode(false),
+						),
+					},
+				},
+			},
+		},
+		{
+			"maintenance mode",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereHostPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereHostExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereHostConfigStandalone(false),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereHostExists(true),
+							testAccResourceVSphereHostInMaintenanceMode(false),
+						),
+					},
+					{
+						Config: testAccResourceVSphereHostConfigStandalone(true),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereHostExists(true),
+							testAccResourceVSphereHostInMaintenanceMode(true),
+						),
+					},
+				},
+			},
+		},
+		{
+			"move into cluster",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereHostPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereHostExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereHostConfigStandalone(false),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereHostExists(true),
+							testAccResourceVSphereHostHasParentType("ComputeResource"),
+						),
+					},
+					{
+						Config: testAccResourceVSphereHostConfigCluster(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereHostExists(true),
+							testAccResourceVSphereHostHasParentType("ClusterComputeResource"),
+							testAccResourceVSphereHostInMaintenanceMode(false),
+						),
+					},
+				},
+			},
+		},
+		{
+			"import",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereHostPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereHostExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereHostConfigStandalone(false),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereHostExists(true),
+						),
+					},
+					{
+						ResourceName:            "vsphere_host.host",
+						ImportState:             true,
+						ImportStateVerify:       true,
+						ImportStateVerifyIgnore: []string{"username", "password", "thumbprint", "force"},
+						ImportStateIdFunc: func(s *terraform.State) (string, error) {
+							host, err := testGetHost(s, "host")
+							if err != nil {
+								return "", err
+							}
+							return host.InventoryPath, nil
+						},
+						Config: testAccResourceVSphereHostConfigStandalone(false),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereHostExists(true),
+						),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccResourceVSphereHostCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+func testAccResourceVSphereHostPreCheck(t *testing.T) {
+	if os.Getenv("VSPHERE_ESXI_ADD_HOST") == "" {
+		t.Skip("set VSPHERE_ESXI_ADD_HOST to run vsphere_host acceptance tests")
+	}
+	if os.Getenv("VSPHERE_ESXI_ADD_HOST_USER") == "" {
+		t.Skip("set VSPHERE_ESXI_ADD_HOST_USER to run vsphere_host acceptance tests")
+	}
+	if os.Getenv("VSPHERE_ESXI_ADD_HOST_PASSWORD") == "" {
+		t.Skip("set VSPHERE_ESXI_ADD_HOST_PASSWORD to run vsphere_host acceptance tests")
+	}
+	if os.Getenv("VSPHERE_ESXI_ADD_HOST_THUMBPRINT") == "" {
+		t.Skip("set VSPHERE_ESXI_ADD_HOST_THUMBPRINT to run vsphere_host acceptance tests")
+	}
+}
+
+func testAccResourceVSphereHostExists(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		host, err := testGetHost(s, "host")
+		if err != nil {
+			if isAnyNotFoundError(err) && expected == false {
+				// Expected missing
+				return nil
+			}
+			return err
+		}
+		if !expected {
+			return fmt.Errorf("expected host %s to be missing", host.Reference().Value)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereHostHasParentType(expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetHostProperties(s, "host")
+		if err != nil {
+			return err
+		}
+		if props.Parent == nil {
+			return fmt.Errorf("host has no parent")
+		}
+		actual := props.Parent.Type
+		if expected != actual {
+			return fmt.Errorf("expected host parent type to be %s, got %s", expected, actual)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereHostInMaintenanceMode(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetHostProperties(s, "host")
+		if err != nil {
+			return err
+		}
+		if props.Runtime.ConnectionState != types.HostSystemConnectionStateConnected {
+			return fmt.Errorf("expected host to be connected, got %s", props.Runtime.ConnectionState)
+		}
+		actual := props.Runtime.InMaintenanceMode
+		if expected != actual {
+			return fmt.Errorf("expected host maintenance mode to be %t, got %t", expected, actual)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereHostConfigStandalone(maintenance bool) string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+resource "vsphere_host" "host" {
+  hostname      = "%s"
+  username      = "%s"
+  password      = "%s"
+  thumbprint    = "%s"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+  maintenance   = %t
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_ESXI_ADD_HOST"),
+		os.Getenv("VSPHERE_ESXI_ADD_HOST_USER"),
+		os.Getenv("VSPHERE_ESXI_ADD_HOST_PASSWORD"),
+		os.Getenv("VSPHERE_ESXI_ADD_HOST_THUMBPRINT"),
+		maintenance,
+	)
+}
+
+func testAccResourceVSphereHostConfigCluster() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+resource "vsphere_compute_cluster" "compute_cluster" {
+  name          = "terraform-compute-cluster-test"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_host" "host" {
+  hostname   = "%s"
+  username   = "%s"
+  password   = "%s"
+  thumbprint = "%s"
+  cluster_id = "${vsphere_compute_cluster.compute_cluster.id}"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_ESXI_ADD_HOST"),
+		os.Getenv("VSPHERE_ESXI_ADD_HOST_USER"),
+		os.Getenv("VSPHERE_ESXI_ADD_HOST_PASSWORD"),
+		os.Getenv("VSPHERE_ESXI_ADD_HOST_THUMBPRINT"),
+	)
+}
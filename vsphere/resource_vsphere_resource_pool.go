@@ -0,0 +1,198 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func resourceVSphereResourcePool() *schema.Resource {
+	s := map[string]*schema.Schema{
+		"name": {
+			Type:         schema.TypeString,
+			Description:  "The name of the resource pool.",
+			Required:     true,
+			ValidateFunc: validation.NoZeroValues,
+		},
+		"parent_resource_pool_id": {
+			Type:        schema.TypeString,
+			Description: "The managed object ID of the parent resource pool. This can be the root resource pool of a cluster or standalone host, or another resource pool.",
+			Required:    true,
+		},
+		"force_destroy": {
+			Type:        schema.TypeBool,
+			Description: "Destroy the resource pool even if it still contains virtual machines. Any virtual machines in the resource pool are moved to its parent.",
+			Optional:    true,
+		},
+		// Tagging
+		vSphereTagAttributeKey: tagsSchema(),
+	}
+	mergeSchema(s, schemaResourceConfigSpec())
+
+	return &schema.Resource{
+		Create: resourceVSphereResourcePoolCreate,
+		Read:   resourceVSphereResourcePoolRead,
+		Update: resourceVSphereResourcePoolUpdate,
+		Delete: resourceVSphereResourcePoolDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceVSphereResourcePoolImport,
+		},
+		Schema: s,
+	}
+}
+
+func resourceVSphereResourcePoolCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	tagsClient, err := tagsClientIfDefined(d, meta)
+	if err != nil {
+		return err
+	}
+
+	parent, err := resourcePoolFromID(client, d.Get("parent_resource_pool_id").(string))
+	if err != nil {
+		return fmt.Errorf("cannot locate parent resource pool: %s", err)
+	}
+	pool, err := createResourcePool(parent, d.Get("name").(string), *expandResourceConfigSpec(d))
+	if err != nil {
+		return fmt.Errorf("error creating resource pool: %s", err)
+	}
+	d.SetId(pool.Reference().Value)
+
+	// Apply any pending tags now
+	if tagsClient != nil {
+		if err := processTagDiff(tagsClient, d, pool); err != nil {
+			return fmt.Errorf("error updating tags: %s", err)
+		}
+	}
+
+	return resourceVSphereResourcePoolRead(d, meta)
+}
+
+func resourceVSphereResourcePoolRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	pool, err := resourcePoolFromID(client, d.Id())
+	if err != nil {
+		return fmt.Errorf("cannot locate resource pool: %s", err)
+	}
+	props, err := resourcePoolProperties(pool)
+	if err != nil {
+		return fmt.Errorf("error fetching resource pool properties: %s", err)
+	}
+	d.Set("name", props.Name)
+	if props.Parent != nil {
+		d.Set("parent_resource_pool_id", props.Parent.Value)
+	}
+	if err := flattenResourceConfigSpec(d, props.Config); err != nil {
+		return err
+	}
+
+	// Read tags if we have the ability to do so
+	if tagsClient, _ := meta.(*VSphereClient).TagsClient(); tagsClient != nil {
+		if err := readTagsForResource(tagsClient, pool, d); err != nil {
+			return fmt.Errorf("error reading tags: %s", err)
+		}
+	}
+
+	return nil
+}
+
+func resourceVSphereResourcePoolUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	tagsClient, err := tagsClientIfDefined(d, meta)
+	if err != nil {
+		return err
+	}
+	pool, err := resourcePoolFromID(client, d.Id())
+	if err != nil {
+		return fmt.Errorf("cannot locate resource pool: %s", err)
+	}
+
+	// Move the resource pool if its parent has changed.
+	if d.HasChange("parent_resource_pool_id") {
+		parent, err := resourcePoolFromID(client, d.Get("parent_resource_pool_id").(string))
+		if err != nil {
+			return fmt.Errorf("cannot locate parent resource pool: %s", err)
+		}
+		if err := moveIntoResourcePool(client, parent, []types.ManagedObjectReference{pool.Reference()}); err != nil {
+			return fmt.Errorf("could not move resource pool to parent %q: %s", parent.Reference().Value, err)
+		}
+	}
+
+	// Update the name and resource allocation if any of it has changed.
+	var name string
+	if d.HasChange("name") {
+		name = d.Get("name").(string)
+	}
+	var spec *types.ResourceConfigSpec
+	for k := range schemaResourceConfigSpec() {
+		if d.HasChange(k) {
+			spec = expandResourceConfigSpec(d)
+			break
+		}
+	}
+	if name != "" || spec != nil {
+		if err := updateResourcePool(pool, name, spec); err != nil {
+			return fmt.Errorf("error updating resource pool: %s", err)
+		}
+	}
+
+	// Apply any pending tags now
+	if tagsClient != nil {
+		if err := processTagDiff(tagsClient, d, pool); err != nil {
+			return fmt.Errorf("error updating tags: %s", err)
+		}
+	}
+
+	return resourceVSphereResourcePoolRead(d, meta)
+}
+
+func resourceVSphereResourcePoolDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	pool, err := resourcePoolFromID(client, d.Id())
+	if err != nil {
+		return fmt.Errorf("cannot locate resource pool: %s", err)
+	}
+
+	// vSphere moves any virtual machines in a resource pool, and in its child
+	// resource pools and vApps, to its parent when the resource pool is
+	// destroyed. We don't do this unless forced, as it can change the resource
+	// allocation of those virtual machines.
+	if !d.Get("force_destroy").(bool) {
+		vms, err := resourcePoolVirtualMachines(pool)
+		if err != nil {
+			return fmt.Errorf("error fetching virtual machines in resource pool: %s", err)
+		}
+		if len(vms) > 0 {
+			return fmt.Errorf("resource pool %q still contains %d virtual machine(s), including in child resource pools and vApps, please remove them or set force_destroy before deleting", d.Get("name").(string), len(vms))
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	task, err := pool.Destroy(ctx)
+	if err != nil {
+		return fmt.Errorf("error deleting resource pool: %s", err)
+	}
+	tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer tcancel()
+	if err := task.Wait(tctx); err != nil {
+		return fmt.Errorf("error waiting for resource pool deletion to complete: %s", err)
+	}
+
+	return nil
+}
+
+func resourceVSphereResourcePoolImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	// We import resource pools by inventory path. A full path is required
+	// unless the default datacenter can be utilized.
+	client := meta.(*VSphereClient).vimClient
+	pool, err := resourcePoolFromPath(client, d.Id(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error locating resource pool: %s", err)
+	}
+	d.SetId(pool.Reference().Value)
+	return []*schema.ResourceData{d}, nil
+}
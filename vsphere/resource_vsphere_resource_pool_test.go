@@ -0,0 +1,358 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestAccResourceVSphereResourcePool(t *testing.T) {
+	var tp *testing.T
+	testAccResourceVSphereResourcePoolCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"basic",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereResourcePoolExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereResourcePoolConfigBasic(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereResourcePoolExists(true),
+							testAccResourceVSphereResourcePoolMatchCPUAllocation(func(info *types.ResourceAllocationInfo) error {
+								if info.Shares == nil || info.Shares.Level != types.SharesLevelNormal {
+									return fmt.Errorf("expected CPU share level to be normal")
+								}
+								if info.Limit == nil || *info.Limit != -1 {
+									return fmt.Errorf("expected CPU limit to be unlimited")
+								}
+								return nil
+							}),
+						),
+					},
+				},
+			},
+		},
+		{
+			"resource allocation",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereResourcePoolExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereResourcePoolConfigBasic(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereResourcePoolExists(true),
+						),
+					},
+					{
+						Config: testAccResourceVSphereResourcePoolConfigAllocation(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereResourcePoolExists(true),
+							testAccResourceVSphereResourcePoolMatchCPUAllocation(func(info *types.ResourceAllocationInfo) error {
+								if info.Shares == nil || info.Shares.Level != types.SharesLevelCustom || info.Shares.Shares != 10 {
+									return fmt.Errorf("expected custom CPU shares of 10")
+								}
+								if info.Reservation == nil || *info.Reservation != 10 {
+									return fmt.Errorf("expected CPU reservation to be 10")
+								}
+								if info.ExpandableReservation == nil || *info.ExpandableReservation {
+									return fmt.Errorf("expected CPU reservation to not be expandable")
+								}
+								if info.Limit == nil || *info.Limit != 20 {
+									return fmt.Errorf("expected CPU limit to be 20")
+								}
+								return nil
+							}),
+							resource.TestCheckResourceAttr("vsphere_resource_pool.resource_pool", "memory_share_level", "high"),
+							resource.TestCheckResourceAttr("vsphere_resource_pool.resource_pool", "memory_reservation", "16"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"rename and move to parent",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereResourcePoolExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereResourcePoolConfigBasic(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereResourcePoolExists(true),
+						),
+					},
+					{
+						Config: testAccResourceVSphereResourcePoolConfigRenamedWithParent(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereResourcePoolExists(true),
+							testAccResourceVSphereResourcePoolHasName("terraform-resource-pool-test-renamed"),
+							testAccResourceVSphereResourcePoolHasParent("parent"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"tags",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereResourcePoolExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereResourcePoolConfigTags(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereResourcePoolExists(true),
+							testAccResourceVSphereResourcePoolCheckTags("terraform-test-tag"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"import",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereResourcePoolExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereResourcePoolConfigAllocation(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereResourcePoolExists(true),
+						),
+					},
+					{
+						ResourceName:            "vsphere_resource_pool.resource_pool",
+						ImportState:             true,
+						ImportStateVerify:       true,
+						ImportStateVerifyIgnore: []string{"force_destroy"},
+						ImportStateIdFunc: func(s *terraform.State) (string, error) {
+							pool, err := testGetResourcePool(s, "resource_pool")
+							if err != nil {
+								return "", err
+							}
+							return pool.InventoryPath, nil
+						},
+						Config: testAccResourceVSphereResourcePoolConfigAllocation(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereResourcePoolExists(true),
+						),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccResourceVSphereResourcePoolCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+func testAccResourceVSphereResourcePoolExists(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		pool, err := testGetResourcePool(s, "resource_pool")
+		if err != nil {
+			if isAnyNotFoundError(err) && expected == false {
+				// Expected missing
+				return nil
+			}
+			return err
+		}
+		if !expected {
+			return fmt.Errorf("expected resource pool %s to be missing", pool.Reference().Value)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereResourcePoolHasName(expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetResourcePoolProperties(s, "resource_pool")
+		if err != nil {
+			return err
+		}
+		actual := props.Name
+		if expected != actual {
+			return fmt.Errorf("expected resource pool name to be %s, got %s", expected, actual)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereResourcePoolHasParent(parentResName string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetResourcePoolProperties(s, "resource_pool")
+		if err != nil {
+			return err
+		}
+		parent, err := testGetResourcePool(s, parentResName)
+		if err != nil {
+			return err
+		}
+		expected := parent.Reference().Value
+		if props.Parent == nil || props.Parent.Value != expected {
+			return fmt.Errorf("expected resource pool parent to be %s, got %v", expected, props.Parent)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereResourcePoolMatchCPUAllocation(f func(*types.ResourceAllocationInfo) error) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetResourcePoolProperties(s, "resource_pool")
+		if err != nil {
+			return err
+		}
+		if props.Config.CpuAllocation == nil {
+			return fmt.Errorf("resource pool has no CPU allocation")
+		}
+		return f(props.Config.CpuAllocation.GetResourceAllocationInfo())
+	}
+}
+
+// testAccResourceVSphereResourcePoolCheckTags is a check to ensure that any
+// tags that have been created with the supplied resource name have been
+// attached to the resource pool.
+func testAccResourceVSphereResourcePoolCheckTags(tagResName string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		pool, err := testGetResourcePool(s, "resource_pool")
+		if err != nil {
+			return err
+		}
+		tagsClient, err := testAccProvider.Meta().(*VSphereClient).TagsClient()
+		if err != nil {
+			return err
+		}
+		return testObjectHasTags(s, tagsClient, pool, tagResName)
+	}
+}
+
+// testAccResourceVSphereResourcePoolConfigCluster returns the configuration
+// for the DRS-enabled cluster that the resource pools in these tests are
+// created in.
+func testAccResourceVSphereResourcePoolConfigCluster() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+resource "vsphere_compute_cluster" "compute_cluster" {
+  name          = "terraform-compute-cluster-test"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+  drs_enabled   = true
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+	)
+}
+
+func testAccResourceVSphereResourcePoolConfigBasic() string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_resource_pool" "resource_pool" {
+  name                    = "terraform-resource-pool-test"
+  parent_resource_pool_id = "${vsphere_compute_cluster.compute_cluster.resource_pool_id}"
+}
+`,
+		testAccResourceVSphereResourcePoolConfigCluster(),
+	)
+}
+
+func testAccResourceVSphereResourcePoolConfigAllocation() string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_resource_pool" "resource_pool" {
+  name                    = "terraform-resource-pool-test"
+  parent_resource_pool_id = "${vsphere_compute_cluster.compute_cluster.resource_pool_id}"
+
+  cpu_share_level = "custom"
+  cpu_shares      = 10
+  cpu_reservation = 10
+  cpu_expandable  = false
+  cpu_limit       = 20
+
+  memory_share_level = "high"
+  memory_reservation = 16
+}
+`,
+		testAccResourceVSphereResourcePoolConfigCluster(),
+	)
+}
+
+func testAccResourceVSphereResourcePoolConfigRenamedWithParent() string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_resource_pool" "parent" {
+  name                    = "terraform-resource-pool-test-parent"
+  parent_resource_pool_id = "${vsphere_compute_cluster.compute_cluster.resource_pool_id}"
+}
+
+resource "vsphere_resource_pool" "resource_pool" {
+  name                    = "terraform-resource-pool-test-renamed"
+  parent_resource_pool_id = "${vsphere_resource_pool.parent.id}"
+}
+`,
+		testAccResourceVSphereResourcePoolConfigCluster(),
+	)
+}
+
+func testAccResourceVSphereResourcePoolConfigTags() string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_tag_category" "terraform-test-category" {
+  name        = "terraform-test-tag-category"
+  cardinality = "MULTIPLE"
+
+  associable_types = [
+    "ResourcePool",
+  ]
+}
+
+resource "vsphere_tag" "terraform-test-tag" {
+  name        = "terraform-test-tag"
+  category_id = "${vsphere_tag_category.terraform-test-category.id}"
+}
+
+resource "vsphere_resource_pool" "resource_pool" {
+  name                    = "terraform-resource-pool-test"
+  parent_resource_pool_id = "${vsphere_compute_cluster.compute_cluster.resource_pool_id}"
+  tags                    = ["${vsphere_tag.terraform-test-tag.id}"]
+}
+`,
+		testAccResourceVSphereResourcePoolConfigCluster(),
+	)
+}
@@ -0,0 +1,351 @@
+package vsphere
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// storageDrsIntraVMAntiAffinityRuleName is the name given to the intra-VM
+// anti-affinity rule created by the vsphere_storage_drs_vm_override resource.
+const storageDrsIntraVMAntiAffinityRuleName = "terraform-intra-vm-anti-affinity"
+
+func resourceVSphereStorageDrsVMOverride() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereStorageDrsVMOverrideCreate,
+		Read:   resourceVSphereStorageDrsVMOverrideRead,
+		Update: resourceVSphereStorageDrsVMOverrideUpdate,
+		Delete: resourceVSphereStorageDrsVMOverrideDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceVSphereStorageDrsVMOverrideImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"datastore_cluster_id": {
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the datastore cluster.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"virtual_machine_uuid": {
+				Type:        schema.TypeString,
+				Description: "The UUID of the virtual machine to override settings for.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"sdrs_enabled": {
+				Type:         schema.TypeString,
+				Description:  "Overrides whether storage DRS is enabled for this virtual machine. Can be one of true or false. When not set, the datastore cluster setting is used.",
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice([]string{"true", "false"}, false),
+			},
+			"sdrs_automation_level": {
+				Type:        schema.TypeString,
+				Description: "Overrides the storage DRS automation level for this virtual machine. Can be one of manual or automated. When not set, the datastore cluster setting is used.",
+				Optional:    true,
+				ValidateFunc: validation.StringInSlice(
+					[]string{
+						string(types.StorageDrsPodConfigInfoBehaviorManual),
+						string(types.StorageDrsPodConfigInfoBehaviorAutomated),
+					},
+					false,
+				),
+			},
+			"sdrs_intra_vm_affinity": {
+				Type:         schema.TypeString,
+				Description:  "Overrides whether the disks of this virtual machine are kept together on the same datastore. Can be one of true or false. When not set, the datastore cluster setting is used.",
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice([]string{"true", "false"}, false),
+			},
+			"sdrs_intra_vm_anti_affinity_disk_keys": {
+				Type:        schema.TypeSet,
+				Description: "The device keys of virtual disks of this virtual machine that storage DRS should keep on separate datastores. Requires sdrs_intra_vm_affinity to be false.",
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeInt},
+			},
+		},
+	}
+}
+
+func resourceVSphereStorageDrsVMOverrideCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	pod, vm, err := resourceVSphereStorageDrsVMOverrideObjects(d, meta)
+	if err != nil {
+		return err
+	}
+
+	// The datastore cluster may already have a configuration entry for the
+	// virtual machine, in which case we edit it instead of adding a new one.
+	existing, err := storageDrsVMConfig(pod, vm)
+	if err != nil {
+		return fmt.Errorf("error fetching storage DRS configuration: %s", err)
+	}
+	op := types.ArrayUpdateOperationAdd
+	if existing != nil {
+		op = types.ArrayUpdateOperationEdit
+	}
+	if err := resourceVSphereStorageDrsVMOverrideApply(d, meta, pod, vm, op); err != nil {
+		return err
+	}
+
+	d.SetId(fmt.Sprintf("%s:%s", pod.Reference().Value, d.Get("virtual_machine_uuid").(string)))
+	return resourceVSphereStorageDrsVMOverrideRead(d, meta)
+}
+
+func resourceVSphereStorageDrsVMOverrideRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	pod, vm, err := resourceVSphereStorageDrsVMOverrideObjects(d, meta)
+	if err != nil {
+		return err
+	}
+
+	info, err := storageDrsVMConfig(pod, vm)
+	if err != nil {
+		return fmt.Errorf("error fetching storage DRS configuration: %s", err)
+	}
+	if info == nil {
+		log.Printf("[DEBUG] Storage DRS override for virtual machine %q not found in datastore cluster %q, marking as gone", d.Get("virtual_machine_uuid").(string), pod.Reference().Value)
+		d.SetId("")
+		return nil
+	}
+
+	return flattenStorageDrsVMConfigInfo(d, info)
+}
+
+func resourceVSphereStorageDrsVMOverrideUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	pod, vm, err := resourceVSphereStorageDrsVMOverrideObjects(d, meta)
+	if err != nil {
+		return err
+	}
+
+	// An edit leaves any field that is not set in the spec as it is, so if an
+	// override has been removed, the entry is removed and added again instead.
+	op := types.ArrayUpdateOperationEdit
+	if storageDrsVMOverrideCleared(d) {
+		if err := removeStorageDrsVMConfig(client, pod, vm); err != nil {
+			return err
+		}
+		op = types.ArrayUpdateOperationAdd
+	}
+	if err := resourceVSphereStorageDrsVMOverrideApply(d, meta, pod, vm, op); err != nil {
+		return err
+	}
+	return resourceVSphereStorageDrsVMOverrideRead(d, meta)
+}
+
+func resourceVSphereStorageDrsVMOverrideDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	pod, vm, err := resourceVSphereStorageDrsVMOverrideObjects(d, meta)
+	if err != nil {
+		return err
+	}
+
+	return removeStorageDrsVMConfig(client, pod, vm)
+}
+
+func resourceVSphereStorageDrsVMOverrideImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	// We import with the same ID that the resource is saved with, which is the
+	// datastore cluster ID and the virtual machine UUID, separated by a colon.
+	parts := strings.SplitN(d.Id(), ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("invalid ID %q, expected DATASTORE_CLUSTER_ID:VIRTUAL_MACHINE_UUID", d.Id())
+	}
+	d.Set("datastore_cluster_id", parts[0])
+	d.Set("virtual_machine_uuid", parts[1])
+	return []*schema.ResourceData{d}, nil
+}
+
+// resourceVSphereStorageDrsVMOverrideObjects fetches the datastore cluster and
+// virtual machine for the resource.
+func resourceVSphereStorageDrsVMOverrideObjects(d *schema.ResourceData, meta interface{}) (*object.StoragePod, *object.VirtualMachine, error) {
+	client := meta.(*VSphereClient).vimClient
+	pod, err := storagePodFromID(client, d.Get("datastore_cluster_id").(string))
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot locate datastore cluster: %s", err)
+	}
+	vm, err := virtualMachineFromUUID(client, d.Get("virtual_machine_uuid").(string))
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot locate virtual machine: %s", err)
+	}
+	return pod, vm, nil
+}
+
+// resourceVSphereStorageDrsVMOverrideApply applies the override in the
+// resource to the datastore cluster, using the supplied operation.
+func resourceVSphereStorageDrsVMOverrideApply(d *schema.ResourceData, meta interface{}, pod *object.StoragePod, vm *object.VirtualMachine, op types.ArrayUpdateOperation) error {
+	client := meta.(*VSphereClient).vimClient
+	info, err := expandStorageDrsVMConfigInfo(d, vm)
+	if err != nil {
+		return err
+	}
+	if info.IntraVmAntiAffinity != nil {
+		if err := validateStorageDrsVMAntiAffinityDisks(vm, info.IntraVmAntiAffinity.DiskId); err != nil {
+			return err
+		}
+		if op == types.ArrayUpdateOperationEdit {
+			// The existing rule is identified by its key, so it needs to be
+			// carried over for the edit to change it instead of adding a new one.
+			existing, err := storageDrsVMConfig(pod, vm)
+			if err != nil {
+				return fmt.Errorf("error fetching storage DRS configuration: %s", err)
+			}
+			if existing != nil && existing.IntraVmAntiAffinity != nil {
+				info.IntraVmAntiAffinity.Key = existing.IntraVmAntiAffinity.Key
+			}
+		}
+	}
+	spec := types.StorageDrsConfigSpec{
+		VmConfigSpec: []types.StorageDrsVmConfigSpec{
+			{
+				ArrayUpdateSpec: types.ArrayUpdateSpec{
+					Operation: op,
+				},
+				Info: info,
+			},
+		},
+	}
+	if err := applyStorageDrsConfig(client, pod, spec); err != nil {
+		return fmt.Errorf("error applying storage DRS override: %s", err)
+	}
+	return nil
+}
+
+// removeStorageDrsVMConfig removes the configuration entry for a virtual
+// machine from a datastore cluster.
+func removeStorageDrsVMConfig(client *govmomi.Client, pod *object.StoragePod, vm *object.VirtualMachine) error {
+	spec := types.StorageDrsConfigSpec{
+		VmConfigSpec: []types.StorageDrsVmConfigSpec{
+			{
+				ArrayUpdateSpec: types.ArrayUpdateSpec{
+					Operation: types.ArrayUpdateOperationRemove,
+					RemoveKey: vm.Reference(),
+				},
+			},
+		},
+	}
+	if err := applyStorageDrsConfig(client, pod, spec); err != nil {
+		return fmt.Errorf("error removing storage DRS override: %s", err)
+	}
+	return nil
+}
+
+// storageDrsVMOverrideCleared returns true if any override that was set in the
+// resource has been removed.
+func storageDrsVMOverrideCleared(d *schema.ResourceData) bool {
+	for _, k := range []string{"sdrs_enabled", "sdrs_automation_level", "sdrs_intra_vm_affinity"} {
+		o, n := d.GetChange(k)
+		if o.(string) != "" && n.(string) == "" {
+			return true
+		}
+	}
+	o, n := d.GetChange("sdrs_intra_vm_anti_affinity_disk_keys")
+	return o.(*schema.Set).Len() > 0 && n.(*schema.Set).Len() == 0
+}
+
+// expandStorageDrsVMConfigInfo reads the resource and returns a
+// StorageDrsVmConfigInfo for the virtual machine.
+func expandStorageDrsVMConfigInfo(d *schema.ResourceData, vm *object.VirtualMachine) (*types.StorageDrsVmConfigInfo, error) {
+	enabled, err := getBoolStringPtr(d, "sdrs_enabled")
+	if err != nil {
+		return nil, err
+	}
+	affinity, err := getBoolStringPtr(d, "sdrs_intra_vm_affinity")
+	if err != nil {
+		return nil, err
+	}
+	vmRef := vm.Reference()
+	obj := &types.StorageDrsVmConfigInfo{
+		Vm:              &vmRef,
+		Enabled:         enabled,
+		Behavior:        d.Get("sdrs_automation_level").(string),
+		IntraVmAffinity: affinity,
+	}
+
+	keys := d.Get("sdrs_intra_vm_anti_affinity_disk_keys").(*schema.Set).List()
+	if len(keys) > 0 {
+		if affinity == nil || *affinity {
+			return nil, errors.New("sdrs_intra_vm_affinity must be false when sdrs_intra_vm_anti_affinity_disk_keys is set")
+		}
+		if len(keys) < 2 {
+			return nil, errors.New("sdrs_intra_vm_anti_affinity_disk_keys must contain at least 2 disks")
+		}
+		rule := &types.VirtualDiskAntiAffinityRuleSpec{
+			ClusterRuleInfo: types.ClusterRuleInfo{
+				Name:    storageDrsIntraVMAntiAffinityRuleName,
+				Enabled: boolPtr(true),
+			},
+		}
+		for _, key := range keys {
+			rule.DiskId = append(rule.DiskId, int32(key.(int)))
+		}
+		obj.IntraVmAntiAffinity = rule
+	}
+
+	return obj, nil
+}
+
+// flattenStorageDrsVMConfigInfo reads various fields from a
+// StorageDrsVmConfigInfo into the passed in ResourceData.
+func flattenStorageDrsVMConfigInfo(d *schema.ResourceData, obj *types.StorageDrsVmConfigInfo) error {
+	if err := setBoolStringPtr(d, "sdrs_enabled", obj.Enabled); err != nil {
+		return err
+	}
+	if err := setBoolStringPtr(d, "sdrs_intra_vm_affinity", obj.IntraVmAffinity); err != nil {
+		return err
+	}
+	d.Set("sdrs_automation_level", obj.Behavior)
+
+	var keys []int
+	if obj.IntraVmAntiAffinity != nil {
+		for _, key := range obj.IntraVmAntiAffinity.DiskId {
+			keys = append(keys, int(key))
+		}
+	}
+	sort.Ints(keys)
+	if err := d.Set("sdrs_intra_vm_anti_affinity_disk_keys", keys); err != nil {
+		return fmt.Errorf("error setting sdrs_intra_vm_anti_affinity_disk_keys: %s", err)
+	}
+	return nil
+}
+
+// validateStorageDrsVMAntiAffinityDisks checks that all of the supplied device
+// keys belong to virtual disks on the virtual machine.
+func validateStorageDrsVMAntiAffinityDisks(vm *object.VirtualMachine, keys []int32) error {
+	props, err := virtualMachineProperties(vm)
+	if err != nil {
+		return fmt.Errorf("error fetching virtual machine properties: %s", err)
+	}
+	disks := make(map[int32]struct{})
+	for _, dev := range props.Config.Hardware.Device {
+		if disk, ok := dev.(*types.VirtualDisk); ok {
+			disks[disk.Key] = struct{}{}
+		}
+	}
+	for _, key := range keys {
+		if _, ok := disks[key]; !ok {
+			return fmt.Errorf("device key %d is not a virtual disk on virtual machine %q", key, props.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,294 @@
+package vsphere
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestAccResourceVSphereStorageDrsVMOverride(t *testing.T) {
+	var tp *testing.T
+	testAccResourceVSphereStorageDrsVMOverrideCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"automation level",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereStorageDrsVMOverridePreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereStorageDrsVMOverrideExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereStorageDrsVMOverrideConfig(`sdrs_automation_level = "automated"`),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereStorageDrsVMOverrideExists(true),
+							testAccResourceVSphereStorageDrsVMOverrideMatch(func(info *types.StorageDrsVmConfigInfo) error {
+								if info.Behavior != "automated" {
+									return fmt.Errorf("expected automation level to be automated, got %q", info.Behavior)
+								}
+								return nil
+							}),
+						),
+					},
+					{
+						Config: testAccResourceVSphereStorageDrsVMOverrideConfig(`sdrs_enabled = "false"`),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereStorageDrsVMOverrideExists(true),
+							testAccResourceVSphereStorageDrsVMOverrideMatch(func(info *types.StorageDrsVmConfigInfo) error {
+								if info.Enabled == nil || *info.Enabled {
+									return errors.New("expected storage DRS to be disabled for virtual machine")
+								}
+								if info.Behavior != "" {
+									return fmt.Errorf("expected automation level override to be removed, got %q", info.Behavior)
+								}
+								return nil
+							}),
+						),
+					},
+				},
+			},
+		},
+		{
+			"intra-VM anti-affinity",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereStorageDrsVMOverridePreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereStorageDrsVMOverrideExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereStorageDrsVMOverrideConfig(`
+  sdrs_intra_vm_affinity                = "false"
+  sdrs_intra_vm_anti_affinity_disk_keys = [2000, 2001]
+`),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereStorageDrsVMOverrideExists(true),
+							testAccResourceVSphereStorageDrsVMOverrideMatch(func(info *types.StorageDrsVmConfigInfo) error {
+								if info.IntraVmAntiAffinity == nil {
+									return errors.New("expected intra-VM anti-affinity rule to be present")
+								}
+								actual := info.IntraVmAntiAffinity.DiskId
+								sort.Slice(actual, func(i, j int) bool { return actual[i] < actual[j] })
+								expected := []int32{2000, 2001}
+								if !reflect.DeepEqual(expected, actual) {
+									return fmt.Errorf("expected anti-affinity disks to be %v, got %v", expected, actual)
+								}
+								return nil
+							}),
+						),
+					},
+					{
+						Config: testAccResourceVSphereStorageDrsVMOverrideConfig(`sdrs_intra_vm_affinity = "false"`),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereStorageDrsVMOverrideExists(true),
+							testAccResourceVSphereStorageDrsVMOverrideMatch(func(info *types.StorageDrsVmConfigInfo) error {
+								if info.IntraVmAntiAffinity != nil {
+									return errors.New("expected intra-VM anti-affinity rule to be removed")
+								}
+								return nil
+							}),
+						),
+					},
+				},
+			},
+		},
+		{
+			"import",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereStorageDrsVMOverridePreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereStorageDrsVMOverrideExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereStorageDrsVMOverrideConfig(`sdrs_automation_level = "automated"`),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereStorageDrsVMOverrideExists(true),
+						),
+					},
+					{
+						ResourceName:      "vsphere_storage_drs_vm_override.override",
+						ImportState:       true,
+						ImportStateVerify: true,
+						Config:            testAccResourceVSphereStorageDrsVMOverrideConfig(`sdrs_automation_level = "automated"`),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccResourceVSphereStorageDrsVMOverrideCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+func testAccResourceVSphereStorageDrsVMOverridePreCheck(t *testing.T) {
+	if os.Getenv("VSPHERE_CLUSTER") == "" {
+		t.Skip("set VSPHERE_CLUSTER to run vsphere_storage_drs_vm_override acceptance tests")
+	}
+	if os.Getenv("VSPHERE_RESOURCE_POOL") == "" {
+		t.Skip("set VSPHERE_RESOURCE_POOL to run vsphere_storage_drs_vm_override acceptance tests")
+	}
+	if os.Getenv("VSPHERE_NETWORK_LABEL") == "" {
+		t.Skip("set VSPHERE_NETWORK_LABEL to run vsphere_storage_drs_vm_override acceptance tests")
+	}
+	if os.Getenv("VSPHERE_TEMPLATE") == "" {
+		t.Skip("set VSPHERE_TEMPLATE to run vsphere_storage_drs_vm_override acceptance tests")
+	}
+	if os.Getenv("VSPHERE_DATASTORE_CLUSTER_MEMBER") == "" {
+		t.Skip("set VSPHERE_DATASTORE_CLUSTER_MEMBER to run vsphere_storage_drs_vm_override acceptance tests")
+	}
+}
+
+// testAccResourceVSphereStorageDrsVMOverrideGetConfig fetches the storage DRS
+// configuration for the virtual machine in the override resource. nil is
+// returned if there is no configuration.
+func testAccResourceVSphereStorageDrsVMOverrideGetConfig(s *terraform.State) (*types.StorageDrsVmConfigInfo, error) {
+	pod, err := testGetDatastoreCluster(s, "datastore_cluster")
+	if err != nil {
+		return nil, err
+	}
+	tVars, err := testClientVariablesForResource(s, "vsphere_virtual_machine.vm")
+	if err != nil {
+		return nil, err
+	}
+	vm, err := virtualMachineFromUUID(tVars.client, tVars.resourceAttributes["uuid"])
+	if err != nil {
+		return nil, err
+	}
+	return storageDrsVMConfig(pod, vm)
+}
+
+func testAccResourceVSphereStorageDrsVMOverrideExists(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		info, err := testAccResourceVSphereStorageDrsVMOverrideGetConfig(s)
+		if err != nil {
+			if isAnyNotFoundError(err) && expected == false {
+				// Expected missing
+				return nil
+			}
+			return err
+		}
+		// Storage DRS keeps a default configuration entry for virtual machines
+		// in the datastore cluster, so check that there is no override instead
+		// of no entry.
+		exists := info != nil && (info.Enabled != nil && !*info.Enabled || info.Behavior == "automated" || info.IntraVmAntiAffinity != nil)
+		if exists != expected {
+			return fmt.Errorf("expected storage DRS override to exist: %t, got %t", expected, exists)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereStorageDrsVMOverrideMatch(f func(*types.StorageDrsVmConfigInfo) error) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		info, err := testAccResourceVSphereStorageDrsVMOverrideGetConfig(s)
+		if err != nil {
+			return err
+		}
+		if info == nil {
+			return errors.New("storage DRS override not found")
+		}
+		return f(info)
+	}
+}
+
+func testAccResourceVSphereStorageDrsVMOverrideConfig(extra string) string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "cluster" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+variable "template" {
+  default = "%s"
+}
+
+variable "datastore_cluster_member" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_datastore" "member" {
+  name          = "${var.datastore_cluster_member}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_datastore_cluster" "datastore_cluster" {
+  name          = "terraform-datastore-cluster-test"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+  datastore_ids = ["${data.vsphere_datastore.member.id}"]
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  name          = "terraform-test"
+  datacenter    = "${var.datacenter}"
+  cluster       = "${var.cluster}"
+  resource_pool = "${var.resource_pool}"
+
+  vcpu   = 2
+  memory = 1024
+
+  network_interface {
+    label = "${var.network_label}"
+  }
+
+  disk {
+    datastore = "${vsphere_datastore_cluster.datastore_cluster.name}"
+    template  = "${var.template}"
+  }
+
+  disk {
+    datastore = "${vsphere_datastore_cluster.datastore_cluster.name}"
+    size      = 1
+    name      = "terraform-test-data.vmdk"
+  }
+}
+
+resource "vsphere_storage_drs_vm_override" "override" {
+  datastore_cluster_id = "${vsphere_datastore_cluster.datastore_cluster.id}"
+  virtual_machine_uuid = "${vsphere_virtual_machine.vm.uuid}"
+  %s
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_CLUSTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL"),
+		os.Getenv("VSPHERE_TEMPLATE"),
+		os.Getenv("VSPHERE_DATASTORE_CLUSTER_MEMBER"),
+		extra,
+	)
+}
@@ -0,0 +1,294 @@
+package vsphere
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func resourceVSphereVAppContainer() *schema.Resource {
+	s := map[string]*schema.Schema{
+		"name": {
+			Type:         schema.TypeString,
+			Description:  "The name of the vApp container.",
+			Required:     true,
+			ValidateFunc: validation.NoZeroValues,
+		},
+		"parent_resource_pool_id": {
+			Type:        schema.TypeString,
+			Description: "The managed object ID of the parent resource pool. This can be the root resource pool of a cluster or standalone host, a resource pool, or another vApp container.",
+			Required:    true,
+		},
+		"parent_folder_id": {
+			Type:        schema.TypeString,
+			Description: "The managed object ID of the VM folder to create the vApp container in. Defaults to the root VM folder of the datacenter. Cannot be set when the parent is another vApp container.",
+			Optional:    true,
+			Computed:    true,
+		},
+		// Tagging
+		vSphereTagAttributeKey: tagsSchema(),
+	}
+	mergeSchema(s, schemaResourceConfigSpec())
+	mergeSchema(s, schemaVAppProductInfo())
+
+	return &schema.Resource{
+		Create: resourceVSphereVAppContainerCreate,
+		Read:   resourceVSphereVAppContainerRead,
+		Update: resourceVSphereVAppContainerUpdate,
+		Delete: resourceVSphereVAppContainerDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceVSphereVAppContainerImport,
+		},
+		Schema: s,
+	}
+}
+
+func resourceVSphereVAppContainerCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	tagsClient, err := tagsClientIfDefined(d, meta)
+	if err != nil {
+		return err
+	}
+
+	parent, err := resourcePoolOrVirtualAppFromID(client, d.Get("parent_resource_pool_id").(string))
+	if err != nil {
+		return fmt.Errorf("cannot locate parent resource pool: %s", err)
+	}
+	folder, err := resourceVSphereVAppContainerFolder(d, meta, parent)
+	if err != nil {
+		return err
+	}
+	configSpec := types.VAppConfigSpec{
+		VmConfigSpec: types.VmConfigSpec{
+			Product: expandVAppProductSpec(d, nil),
+		},
+	}
+	vapp, err := createVirtualApp(parent, d.Get("name").(string), *expandResourceConfigSpec(d), configSpec, folder)
+	if err != nil {
+		return fmt.Errorf("error creating vApp container: %s", err)
+	}
+	d.SetId(vapp.Reference().Value)
+
+	// Apply any pending tags now
+	if tagsClient != nil {
+		if err := processTagDiff(tagsClient, d, vapp); err != nil {
+			return fmt.Errorf("error updating tags: %s", err)
+		}
+	}
+
+	return resourceVSphereVAppContainerRead(d, meta)
+}
+
+func resourceVSphereVAppContainerRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	vapp, err := virtualAppFromID(client, d.Id())
+	if err != nil {
+		return fmt.Errorf("cannot locate vApp container: %s", err)
+	}
+	props, err := virtualAppProperties(vapp)
+	if err != nil {
+		return fmt.Errorf("error fetching vApp container properties: %s", err)
+	}
+	d.Set("name", props.Name)
+	if props.Parent != nil {
+		d.Set("parent_resource_pool_id", props.Parent.Value)
+	}
+	if props.ParentFolder != nil {
+		d.Set("parent_folder_id", props.ParentFolder.Value)
+	} else {
+		d.Set("parent_folder_id", "")
+	}
+	if err := flattenResourceConfigSpec(d, props.Config); err != nil {
+		return err
+	}
+	var products []types.VAppProductInfo
+	if props.VAppConfig != nil {
+		products = props.VAppConfig.Product
+	}
+	if err := flattenVAppProductInfo(d, products); err != nil {
+		return err
+	}
+
+	// Read tags if we have the ability to do so
+	if tagsClient, _ := meta.(*VSphereClient).TagsClient(); tagsClient != nil {
+		if err := readTagsForResource(tagsClient, vapp, d); err != nil {
+			return fmt.Errorf("error reading tags: %s", err)
+		}
+	}
+
+	return nil
+}
+
+func resourceVSphereVAppContainerUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	tagsClient, err := tagsClientIfDefined(d, meta)
+	if err != nil {
+		return err
+	}
+	vapp, err := virtualAppFromID(client, d.Id())
+	if err != nil {
+		return fmt.Errorf("cannot locate vApp container: %s", err)
+	}
+
+	// Move the vApp container if its parent has changed.
+	if d.HasChange("parent_resource_pool_id") {
+		parent, err := resourcePoolOrVirtualAppFromID(client, d.Get("parent_resource_pool_id").(string))
+		if err != nil {
+			return fmt.Errorf("cannot locate parent resource pool: %s", err)
+		}
+		if err := moveIntoResourcePool(client, parent, []types.ManagedObjectReference{vapp.Reference()}); err != nil {
+			return fmt.Errorf("could not move vApp container to parent %q: %s", parent.Reference().Value, err)
+		}
+	}
+
+	// Move the vApp container to its new folder, if that has changed.
+	if d.HasChange("parent_folder_id") && d.Get("parent_folder_id").(string) != "" {
+		folder, err := folderFromID(client, d.Get("parent_folder_id").(string))
+		if err != nil {
+			return fmt.Errorf("cannot locate folder: %s", err)
+		}
+		if err := moveObjectToFolder(vapp.Reference(), folder); err != nil {
+			return fmt.Errorf("could not move vApp container to folder %q: %s", folder.InventoryPath, err)
+		}
+	}
+
+	// Update the name and resource allocation if any of it has changed.
+	var name string
+	if d.HasChange("name") {
+		name = d.Get("name").(string)
+	}
+	var spec *types.ResourceConfigSpec
+	for k := range schemaResourceConfigSpec() {
+		if d.HasChange(k) {
+			spec = expandResourceConfigSpec(d)
+			break
+		}
+	}
+	if name != "" || spec != nil {
+		if err := updateResourcePool(vapp.ResourcePool, name, spec); err != nil {
+			return fmt.Errorf("error updating vApp container: %s", err)
+		}
+	}
+
+	// Update the product information if it has changed.
+	for k := range schemaVAppProductInfo() {
+		if d.HasChange(k) {
+			props, err := virtualAppProperties(vapp)
+			if err != nil {
+				return fmt.Errorf("error fetching vApp container properties: %s", err)
+			}
+			var products []types.VAppProductInfo
+			if props.VAppConfig != nil {
+				products = props.VAppConfig.Product
+			}
+			configSpec := types.VAppConfigSpec{
+				VmConfigSpec: types.VmConfigSpec{
+					Product: expandVAppProductSpec(d, products),
+				},
+			}
+			if err := updateVirtualAppConfig(vapp, configSpec); err != nil {
+				return fmt.Errorf("error updating vApp container product information: %s", err)
+			}
+			break
+		}
+	}
+
+	// Apply any pending tags now
+	if tagsClient != nil {
+		if err := processTagDiff(tagsClient, d, vapp); err != nil {
+			return fmt.Errorf("error updating tags: %s", err)
+		}
+	}
+
+	return resourceVSphereVAppContainerRead(d, meta)
+}
+
+func resourceVSphereVAppContainerDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	vapp, err := virtualAppFromID(client, d.Id())
+	if err != nil {
+		return fmt.Errorf("cannot locate vApp container: %s", err)
+	}
+
+	// Destroying a vApp destroys everything in it, including virtual machines
+	// and child vApps, so we refuse to do it unless it is empty.
+	props, err := virtualAppProperties(vapp)
+	if err != nil {
+		return fmt.Errorf("error fetching vApp container properties: %s", err)
+	}
+	if len(props.Vm) > 0 || len(props.ResourcePool.ResourcePool) > 0 {
+		return fmt.Errorf("vApp container %q still contains %d virtual machine(s) and %d child vApp(s), please remove them before deleting", props.Name, len(props.Vm), len(props.ResourcePool.ResourcePool))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	task, err := vapp.Destroy(ctx)
+	if err != nil {
+		return fmt.Errorf("error deleting vApp container: %s", err)
+	}
+	tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer tcancel()
+	if err := task.Wait(tctx); err != nil {
+		return fmt.Errorf("error waiting for vApp container deletion to complete: %s", err)
+	}
+
+	return nil
+}
+
+func resourceVSphereVAppContainerImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	// We import vApp containers by inventory path. A full path is required
+	// unless the default datacenter can be utilized.
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return nil, err
+	}
+	vapp, err := virtualAppFromPath(client, d.Id(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error locating vApp container: %s", err)
+	}
+	d.SetId(vapp.Reference().Value)
+	return []*schema.ResourceData{d}, nil
+}
+
+// resourceVSphereVAppContainerFolder returns the folder that a new vApp
+// container should be created in. This is nil when the parent is another vApp,
+// as child vApps are not placed in folders.
+func resourceVSphereVAppContainerFolder(d *schema.ResourceData, meta interface{}, parent *object.ResourcePool) (*object.Folder, error) {
+	client := meta.(*VSphereClient).vimClient
+	id := d.Get("parent_folder_id").(string)
+	if isVirtualAppReference(parent.Reference()) {
+		if id != "" {
+			return nil, errors.New("parent_folder_id cannot be set when the parent is a vApp container")
+		}
+		return nil, nil
+	}
+	if id != "" {
+		folder, err := folderFromID(client, id)
+		if err != nil {
+			return nil, fmt.Errorf("cannot locate folder: %s", err)
+		}
+		return folder, nil
+	}
+	folder, err := folderFromObject(client, parent, rootPathParticleVM, "")
+	if err != nil {
+		return nil, fmt.Errorf("cannot locate root VM folder: %s", err)
+	}
+	return folder, nil
+}
@@ -0,0 +1,288 @@
+package vsphere
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/vmware/govmomi/vim25/mo"
+)
+
+func TestAccResourceVSphereVAppContainer(t *testing.T) {
+	var tp *testing.T
+	testAccResourceVSphereVAppContainerCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"basic",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVAppContainerExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVAppContainerConfigBasic(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVAppContainerExists(true),
+							testAccResourceVSphereVAppContainerMatch(func(props *mo.VirtualApp) error {
+								if props.ParentFolder == nil {
+									return errors.New("expected vApp container to be in a folder")
+								}
+								return nil
+							}),
+						),
+					},
+				},
+			},
+		},
+		{
+			"resource allocation and product information",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVAppContainerExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVAppContainerConfigBasic(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVAppContainerExists(true),
+						),
+					},
+					{
+						Config: testAccResourceVSphereVAppContainerConfigProduct(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVAppContainerExists(true),
+							testAccResourceVSphereVAppContainerMatch(func(props *mo.VirtualApp) error {
+								if props.Config.CpuAllocation == nil {
+									return errors.New("vApp container has no CPU allocation")
+								}
+								info := props.Config.CpuAllocation.GetResourceAllocationInfo()
+								if info.Reservation == nil || *info.Reservation != 10 {
+									return errors.New("expected CPU reservation to be 10")
+								}
+								if props.VAppConfig == nil || len(props.VAppConfig.Product) < 1 {
+									return errors.New("vApp container has no product information")
+								}
+								product := props.VAppConfig.Product[0]
+								if product.Name != "terraform-test-product" || product.Version != "1.0" {
+									return fmt.Errorf("unexpected product information: %#v", product)
+								}
+								return nil
+							}),
+						),
+					},
+				},
+			},
+		},
+		{
+			"nested",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVAppContainerExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVAppContainerConfigNested(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVAppContainerExists(true),
+							testAccResourceVSphereVAppContainerMatch(func(props *mo.VirtualApp) error {
+								if props.Parent == nil || props.Parent.Type != "VirtualApp" {
+									return fmt.Errorf("expected vApp container parent to be a vApp, got %v", props.Parent)
+								}
+								return nil
+							}),
+						),
+					},
+				},
+			},
+		},
+		{
+			"tags",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVAppContainerExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVAppContainerConfigTags(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVAppContainerExists(true),
+							testAccResourceVSphereVAppContainerCheckTags("terraform-test-tag"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"import",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVAppContainerExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVAppContainerConfigProduct(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVAppContainerExists(true),
+						),
+					},
+					{
+						ResourceName:      "vsphere_vapp_container.vapp_container",
+						ImportState:       true,
+						ImportStateVerify: true,
+						ImportStateIdFunc: func(s *terraform.State) (string, error) {
+							vapp, err := testGetVAppContainer(s, "vapp_container")
+							if err != nil {
+								return "", err
+							}
+							return vapp.InventoryPath, nil
+						},
+						Config: testAccResourceVSphereVAppContainerConfigProduct(),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccResourceVSphereVAppContainerCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+func testAccResourceVSphereVAppContainerExists(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		vapp, err := testGetVAppContainer(s, "vapp_container")
+		if err != nil {
+			if isAnyNotFoundError(err) && expected == false {
+				// Expected missing
+				return nil
+			}
+			return err
+		}
+		if !expected {
+			return fmt.Errorf("expected vApp container %s to be missing", vapp.Reference().Value)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereVAppContainerMatch(f func(*mo.VirtualApp) error) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVAppContainerProperties(s, "vapp_container")
+		if err != nil {
+			return err
+		}
+		return f(props)
+	}
+}
+
+// testAccResourceVSphereVAppContainerCheckTags is a check to ensure that any
+// tags that have been created with the supplied resource name have been
+// attached to the vApp container.
+func testAccResourceVSphereVAppContainerCheckTags(tagResName string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		vapp, err := testGetVAppContainer(s, "vapp_container")
+		if err != nil {
+			return err
+		}
+		tagsClient, err := testAccProvider.Meta().(*VSphereClient).TagsClient()
+		if err != nil {
+			return err
+		}
+		return testObjectHasTags(s, tagsClient, vapp, tagResName)
+	}
+}
+
+func testAccResourceVSphereVAppContainerConfigBasic() string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_vapp_container" "vapp_container" {
+  name                    = "terraform-vapp-container-test"
+  parent_resource_pool_id = "${vsphere_compute_cluster.compute_cluster.resource_pool_id}"
+}
+`,
+		testAccResourceVSphereResourcePoolConfigCluster(),
+	)
+}
+
+func testAccResourceVSphereVAppContainerConfigProduct() string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_vapp_container" "vapp_container" {
+  name                    = "terraform-vapp-container-test"
+  parent_resource_pool_id = "${vsphere_compute_cluster.compute_cluster.resource_pool_id}"
+
+  cpu_reservation = 10
+
+  product_name    = "terraform-test-product"
+  product_vendor  = "terraform-test-vendor"
+  product_version = "1.0"
+}
+`,
+		testAccResourceVSphereResourcePoolConfigCluster(),
+	)
+}
+
+func testAccResourceVSphereVAppContainerConfigNested() string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_vapp_container" "parent" {
+  name                    = "terraform-vapp-container-test-parent"
+  parent_resource_pool_id = "${vsphere_compute_cluster.compute_cluster.resource_pool_id}"
+}
+
+resource "vsphere_vapp_container" "vapp_container" {
+  name                    = "terraform-vapp-container-test"
+  parent_resource_pool_id = "${vsphere_vapp_container.parent.id}"
+}
+`,
+		testAccResourceVSphereResourcePoolConfigCluster(),
+	)
+}
+
+func testAccResourceVSphereVAppContainerConfigTags() string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_tag_category" "terraform-test-category" {
+  name        = "terraform-test-tag-category"
+  cardinality = "MULTIPLE"
+
+  associable_types = [
+    "VirtualApp",
+  ]
+}
+
+resource "vsphere_tag" "terraform-test-tag" {
+  name        = "terraform-test-tag"
+  category_id = "${vsphere_tag_category.terraform-test-category.id}"
+}
+
+resource "vsphere_vapp_container" "vapp_container" {
+  name                    = "terraform-vapp-container-test"
+  parent_resource_pool_id = "${vsphere_compute_cluster.compute_cluster.resource_pool_id}"
+  tags                    = ["${vsphere_tag.terraform-test-tag.id}"]
+}
+`,
+		testAccResourceVSphereResourcePoolConfigCluster(),
+	)
+}
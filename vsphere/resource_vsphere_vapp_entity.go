@@ -0,0 +1,294 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+const (
+	vAppEntityStartActionNone    = "none"
+	vAppEntityStartActionPowerOn = "powerOn"
+
+	vAppEntityStopActionNone          = "none"
+	vAppEntityStopActionPowerOff      = "powerOff"
+	vAppEntityStopActionGuestShutdown = "guestShutdown"
+	vAppEntityStopActionSuspend       = "suspend"
+)
+
+var vAppEntityStartActionAllowedValues = []string{
+	vAppEntityStartActionNone,
+	vAppEntityStartActionPowerOn,
+}
+
+var vAppEntityStopActionAllowedValues = []string{
+	vAppEntityStopActionNone,
+	vAppEntityStopActionPowerOff,
+	vAppEntityStopActionGuestShutdown,
+	vAppEntityStopActionSuspend,
+}
+
+func resourceVSphereVAppEntity() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereVAppEntityCreate,
+		Read:   resourceVSphereVAppEntityRead,
+		Update: resourceVSphereVAppEntityUpdate,
+		Delete: resourceVSphereVAppEntityDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceVSphereVAppEntityImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"container_id": {
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the vApp container the entity is a member of.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"target_id": {
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the entity to manage. This can be a virtual machine or a child vApp container.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"start_order": {
+				Type:         schema.TypeInt,
+				Description:  "The start order group of the entity. Entities in the same group are started at the same time, and groups are started in ascending order.",
+				Optional:     true,
+				Default:      1,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"start_delay": {
+				Type:         schema.TypeInt,
+				Description:  "The delay, in seconds, before the next start order group is started after this entity is started.",
+				Optional:     true,
+				Default:      120,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"start_action": {
+				Type:         schema.TypeString,
+				Description:  "The action to take on the entity when the vApp is started. Can be one of none or powerOn.",
+				Optional:     true,
+				Default:      vAppEntityStartActionPowerOn,
+				ValidateFunc: validation.StringInSlice(vAppEntityStartActionAllowedValues, false),
+			},
+			"stop_delay": {
+				Type:         schema.TypeInt,
+				Description:  "The delay, in seconds, before the next entity is stopped after this entity is stopped.",
+				Optional:     true,
+				Default:      120,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"stop_action": {
+				Type:         schema.TypeString,
+				Description:  "The action to take on the entity when the vApp is stopped. Can be one of none, powerOff, guestShutdown, or suspend.",
+				Optional:     true,
+				Default:      vAppEntityStopActionPowerOff,
+				ValidateFunc: validation.StringInSlice(vAppEntityStopActionAllowedValues, false),
+			},
+			"wait_for_guest": {
+				Type:        schema.TypeBool,
+				Description: "Wait for VMware Tools to report that the guest is running before starting the next entity, instead of waiting for start_delay. Only valid for virtual machines.",
+				Optional:    true,
+			},
+		},
+	}
+}
+
+func resourceVSphereVAppEntityCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	vapp, err := virtualAppFromID(client, d.Get("container_id").(string))
+	if err != nil {
+		return fmt.Errorf("cannot locate vApp container: %s", err)
+	}
+	// vSphere creates an entity configuration for every member of a vApp, so
+	// we only ever edit an existing entry here.
+	info, err := resourceVSphereVAppEntityFind(vapp, d.Get("target_id").(string))
+	if err != nil {
+		return fmt.Errorf("error fetching vApp entity configuration: %s", err)
+	}
+	if info == nil {
+		return fmt.Errorf("%q is not a member of vApp container %q", d.Get("target_id").(string), vapp.Reference().Value)
+	}
+	if err := resourceVSphereVAppEntityApply(d, vapp, info); err != nil {
+		return err
+	}
+
+	d.SetId(fmt.Sprintf("%s:%s", vapp.Reference().Value, d.Get("target_id").(string)))
+	return resourceVSphereVAppEntityRead(d, meta)
+}
+
+func resourceVSphereVAppEntityRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	vapp, err := virtualAppFromID(client, d.Get("container_id").(string))
+	if err != nil {
+		return fmt.Errorf("cannot locate vApp container: %s", err)
+	}
+	info, err := resourceVSphereVAppEntityFind(vapp, d.Get("target_id").(string))
+	if err != nil {
+		return fmt.Errorf("error fetching vApp entity configuration: %s", err)
+	}
+	if info == nil {
+		log.Printf("[DEBUG] %q is no longer a member of vApp container %q, marking as gone", d.Get("target_id").(string), vapp.Reference().Value)
+		d.SetId("")
+		return nil
+	}
+
+	return flattenVAppEntityConfigInfo(d, info)
+}
+
+func resourceVSphereVAppEntityUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	vapp, err := virtualAppFromID(client, d.Get("container_id").(string))
+	if err != nil {
+		return fmt.Errorf("cannot locate vApp container: %s", err)
+	}
+	info, err := resourceVSphereVAppEntityFind(vapp, d.Get("target_id").(string))
+	if err != nil {
+		return fmt.Errorf("error fetching vApp entity configuration: %s", err)
+	}
+	if info == nil {
+		return fmt.Errorf("%q is not a member of vApp container %q", d.Get("target_id").(string), vapp.Reference().Value)
+	}
+	if err := resourceVSphereVAppEntityApply(d, vapp, info); err != nil {
+		return err
+	}
+	return resourceVSphereVAppEntityRead(d, meta)
+}
+
+func resourceVSphereVAppEntityDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	vapp, err := virtualAppFromID(client, d.Get("container_id").(string))
+	if err != nil {
+		if isManagedObjectNotFoundError(err) {
+			// The vApp is gone, and the entity configuration with it.
+			return nil
+		}
+		return fmt.Errorf("cannot locate vApp container: %s", err)
+	}
+	info, err := resourceVSphereVAppEntityFind(vapp, d.Get("target_id").(string))
+	if err != nil {
+		return fmt.Errorf("error fetching vApp entity configuration: %s", err)
+	}
+	if info == nil {
+		return nil
+	}
+
+	// Entity configuration can't be removed from a vApp, so we reset it to the
+	// vSphere defaults instead.
+	spec := types.VAppConfigSpec{
+		EntityConfig: []types.VAppEntityConfigInfo{
+			{
+				Key:         info.Key,
+				StartOrder:  1,
+				StartDelay:  120,
+				StartAction: vAppEntityStartActionPowerOn,
+				StopDelay:   120,
+				StopAction:  vAppEntityStopActionPowerOff,
+			},
+		},
+	}
+	if info.Key.Type == "VirtualMachine" {
+		spec.EntityConfig[0].WaitingForGuest = boolPtr(false)
+	}
+	if err := updateVirtualAppConfig(vapp, spec); err != nil {
+		return fmt.Errorf("error resetting vApp entity configuration: %s", err)
+	}
+	return nil
+}
+
+func resourceVSphereVAppEntityImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	// We import with the same ID that the resource is saved with, which is the
+	// vApp container ID and the target ID, separated by a colon.
+	parts := strings.SplitN(d.Id(), ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("invalid ID %q, expected CONTAINER_ID:TARGET_ID", d.Id())
+	}
+	d.Set("container_id", parts[0])
+	d.Set("target_id", parts[1])
+	return []*schema.ResourceData{d}, nil
+}
+
+// resourceVSphereVAppEntityFind returns the entity configuration for the
+// member of the vApp with the supplied managed object ID. nil is returned if
+// there is no such member.
+func resourceVSphereVAppEntityFind(vapp *object.VirtualApp, id string) (*types.VAppEntityConfigInfo, error) {
+	props, err := virtualAppProperties(vapp)
+	if err != nil {
+		return nil, err
+	}
+	if props.VAppConfig == nil {
+		return nil, nil
+	}
+	for _, info := range props.VAppConfig.EntityConfig {
+		if info.Key != nil && info.Key.Value == id {
+			return &info, nil
+		}
+	}
+	return nil, nil
+}
+
+// resourceVSphereVAppEntityApply applies the entity configuration in the
+// resource to the existing entity configuration in the vApp.
+func resourceVSphereVAppEntityApply(d *schema.ResourceData, vapp *object.VirtualApp, existing *types.VAppEntityConfigInfo) error {
+	spec := types.VAppConfigSpec{
+		EntityConfig: []types.VAppEntityConfigInfo{
+			*expandVAppEntityConfigInfo(d, *existing.Key),
+		},
+	}
+	if err := updateVirtualAppConfig(vapp, spec); err != nil {
+		return fmt.Errorf("error updating vApp entity configuration: %s", err)
+	}
+	return nil
+}
+
+// expandVAppEntityConfigInfo reads the resource and returns a
+// VAppEntityConfigInfo for the entity referenced by key.
+func expandVAppEntityConfigInfo(d *schema.ResourceData, key types.ManagedObjectReference) *types.VAppEntityConfigInfo {
+	obj := &types.VAppEntityConfigInfo{
+		Key:         &key,
+		StartOrder:  int32(d.Get("start_order").(int)),
+		StartDelay:  int32(d.Get("start_delay").(int)),
+		StartAction: d.Get("start_action").(string),
+		StopDelay:   int32(d.Get("stop_delay").(int)),
+		StopAction:  d.Get("stop_action").(string),
+	}
+	// waitingForGuest is only valid for virtual machines.
+	if key.Type == "VirtualMachine" {
+		obj.WaitingForGuest = boolPtr(d.Get("wait_for_guest").(bool))
+	}
+	return obj
+}
+
+// flattenVAppEntityConfigInfo reads various fields from a
+// VAppEntityConfigInfo into the passed in ResourceData.
+func flattenVAppEntityConfigInfo(d *schema.ResourceData, obj *types.VAppEntityConfigInfo) error {
+	d.Set("start_order", obj.StartOrder)
+	d.Set("start_delay", obj.StartDelay)
+	d.Set("start_action", obj.StartAction)
+	d.Set("stop_delay", obj.StopDelay)
+	d.Set("stop_action", obj.StopAction)
+	if obj.WaitingForGuest != nil {
+		d.Set("wait_for_guest", *obj.WaitingForGuest)
+	} else {
+		d.Set("wait_for_guest", false)
+	}
+	return nil
+}
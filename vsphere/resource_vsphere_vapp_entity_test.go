@@ -0,0 +1,170 @@
+package vsphere
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestAccResourceVSphereVAppEntity(t *testing.T) {
+	var tp *testing.T
+	testAccResourceVSphereVAppEntityCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"basic",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccComputeClusterMembersPreCheck(tp)
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVAppEntityConfig(2, "guestShutdown"),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVAppEntityMatch(func(info *types.VAppEntityConfigInfo) error {
+								if info.StartOrder != 2 {
+									return fmt.Errorf("expected start order to be 2, got %d", info.StartOrder)
+								}
+								if info.StopAction != "guestShutdown" {
+									return fmt.Errorf("expected stop action to be guestShutdown, got %q", info.StopAction)
+								}
+								if info.WaitingForGuest == nil || !*info.WaitingForGuest {
+									return errors.New("expected entity to wait for guest")
+								}
+								return nil
+							}),
+						),
+					},
+				},
+			},
+		},
+		{
+			"update",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccComputeClusterMembersPreCheck(tp)
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVAppEntityConfig(2, "guestShutdown"),
+					},
+					{
+						Config: testAccResourceVSphereVAppEntityConfig(3, "suspend"),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVAppEntityMatch(func(info *types.VAppEntityConfigInfo) error {
+								if info.StartOrder != 3 {
+									return fmt.Errorf("expected start order to be 3, got %d", info.StartOrder)
+								}
+								if info.StopAction != "suspend" {
+									return fmt.Errorf("expected stop action to be suspend, got %q", info.StopAction)
+								}
+								return nil
+							}),
+						),
+					},
+				},
+			},
+		},
+		{
+			"import",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccComputeClusterMembersPreCheck(tp)
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVAppEntityConfig(2, "guestShutdown"),
+					},
+					{
+						ResourceName:      "vsphere_vapp_entity.vapp_entity",
+						ImportState:       true,
+						ImportStateVerify: true,
+						Config:            testAccResourceVSphereVAppEntityConfig(2, "guestShutdown"),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccResourceVSphereVAppEntityCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+func testAccResourceVSphereVAppEntityMatch(f func(*types.VAppEntityConfigInfo) error) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		vapp, err := testGetVAppContainer(s, "vapp_container")
+		if err != nil {
+			return err
+		}
+		vm, err := testGetVirtualMachine(s, "vapp_vm")
+		if err != nil {
+			return err
+		}
+		info, err := resourceVSphereVAppEntityFind(vapp, vm.Reference().Value)
+		if err != nil {
+			return err
+		}
+		if info == nil {
+			return errors.New("vApp entity not found")
+		}
+		return f(info)
+	}
+}
+
+func testAccResourceVSphereVAppEntityConfig(startOrder int, stopAction string) string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_vapp_container" "vapp_container" {
+  name                    = "terraform-vapp-container-test"
+  parent_resource_pool_id = "${vsphere_compute_cluster.compute_cluster.resource_pool_id}"
+}
+
+resource "vsphere_virtual_machine" "vapp_vm" {
+  name          = "terraform-test-vapp"
+  datacenter    = "${var.datacenter}"
+  resource_pool = "/${var.datacenter}/vm/${vsphere_vapp_container.vapp_container.name}"
+
+  vcpu   = 1
+  memory = 1024
+
+  network_interface {
+    label = "${var.network_label}"
+  }
+
+  disk {
+    datastore = "${var.datastore}"
+    template  = "${var.template}"
+  }
+
+  depends_on = ["vsphere_host.host"]
+}
+
+resource "vsphere_vapp_entity" "vapp_entity" {
+  container_id   = "${vsphere_vapp_container.vapp_container.id}"
+  target_id      = "${vsphere_virtual_machine.vapp_vm.moid}"
+  start_order    = %d
+  stop_action    = "%s"
+  wait_for_guest = true
+}
+`,
+		testAccComputeClusterMembersConfig(),
+		startOrder,
+		stopAction,
+	)
+}
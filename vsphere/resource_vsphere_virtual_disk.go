@@ -3,9 +3,7 @@ package vsphere
 import (
 	"fmt"
 	"log"
-
-	"errors"
-	"path"
+	"strings"
 
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/vmware/govmomi"
@@ -16,12 +14,14 @@ import (
 )
 
 type virtualDisk struct {
-	size        int
-	vmdkPath    string
-	initType    string
-	adapterType string
-	datacenter  string
-	datastore   string
+	size            int
+	vmdkPath        string
+	initType        string
+	adapterType     string
+	datacenter      string
+	datastore       string
+	storagePolicyID string
+	sourceVmdkPath  string
 }
 
 // Define VirtualDisk args
@@ -29,14 +29,18 @@ func resourceVSphereVirtualDisk() *schema.Resource {
 	return &schema.Resource{
 		Create: resourceVSphereVirtualDiskCreate,
 		Read:   resourceVSphereVirtualDiskRead,
+		Update: resourceVSphereVirtualDiskUpdate,
 		Delete: resourceVSphereVirtualDiskDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceVSphereVirtualDiskImport,
+		},
 
 		Schema: map[string]*schema.Schema{
 			// Size in GB
+			// Disks can only grow, shrinking is an error at apply time.
 			"size": &schema.Schema{
 				Type:     schema.TypeInt,
 				Required: true,
-				ForceNew: true, //TODO Can this be optional (resize)?
 			},
 
 			"vmdk_path": &schema.Schema{
@@ -45,10 +49,11 @@ func resourceVSphereVirtualDisk() *schema.Resource {
 				ForceNew: true, //TODO Can this be optional (move)?
 			},
 
+			// Only thin to eagerZeroedThick conversion is supported in place, other
+			// changes are an error at apply time.
 			"type": &schema.Schema{
 				Type:     schema.TypeString,
 				Optional: true,
-				ForceNew: true,
 				Default:  "eagerZeroedThick",
 				ValidateFunc: func(v interface{}, k string) (ws []string, errors []error) {
 					value := v.(string)
@@ -86,6 +91,18 @@ func resourceVSphereVirtualDisk() *schema.Resource {
 				Optional: true,
 				ForceNew: true,
 			},
+
+			"storage_policy_id": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			"source_vmdk_path": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
 		},
 	}
 }
@@ -118,6 +135,10 @@ func resourceVSphereVirtualDiskCreate(d *schema.ResourceData, meta interface{})
 		vDisk.datastore = v.(string)
 	}
 
+	if v, ok := d.GetOk("storage_policy_id"); ok {
+		vDisk.storagePolicyID = v.(string)
+	}
+
 	finder := find.NewFinder(client.Client, true)
 
 	dc, err := getDatacenter(client, d.Get("datacenter").(string))
@@ -131,7 +152,15 @@ func resourceVSphereVirtualDiskCreate(d *schema.ResourceData, meta interface{})
 		return fmt.Errorf("Error finding Datastore: %s: %s", vDisk.datastore, err)
 	}
 
-	err = createHardDisk(client, vDisk.size, ds.Path(vDisk.vmdkPath), vDisk.initType, vDisk.adapterType, vDisk.datacenter)
+	if v, ok := d.GetOk("source_vmdk_path"); ok {
+		vDisk.sourceVmdkPath = v.(string)
+	}
+
+	if vDisk.sourceVmdkPath != "" {
+		err = copyVirtualDiskFromSource(client, finder, dc, ds, vDisk)
+	} else {
+		err = createHardDisk(client, vDisk.size, ds.Path(vDisk.vmdkPath), vDisk.initType, vDisk.adapterType, vDisk.datacenter, vDisk.storagePolicyID)
+	}
 	if err != nil {
 		return err
 	}
@@ -146,30 +175,12 @@ func resourceVSphereVirtualDiskRead(d *schema.ResourceData, meta interface{}) er
 	log.Printf("[DEBUG] Reading virtual disk.")
 	client := meta.(*VSphereClient).vimClient
 
-	vDisk := virtualDisk{
-		size: d.Get("size").(int),
-	}
+	vDisk := virtualDisk{}
 
 	if v, ok := d.GetOk("vmdk_path"); ok {
 		vDisk.vmdkPath = v.(string)
 	}
 
-	if v, ok := d.GetOk("type"); ok {
-		vDisk.initType = v.(string)
-	}
-
-	if v, ok := d.GetOk("adapter_type"); ok {
-		vDisk.adapterType = v.(string)
-	}
-
-	if v, ok := d.GetOk("datacenter"); ok {
-		vDisk.datacenter = v.(string)
-	}
-
-	if v, ok := d.GetOk("datastore"); ok {
-		vDisk.datastore = v.(string)
-	}
-
 	dc, err := getDatacenter(client, d.Get("datacenter").(string))
 	if err != nil {
 		return err
@@ -183,77 +194,80 @@ func resourceVSphereVirtualDiskRead(d *schema.ResourceData, meta interface{}) er
 		return err
 	}
 
-	ctx := context.TODO()
-	b, err := ds.Browser(ctx)
+	fileInfo, err := virtualDiskFileInfo(ds, vDisk.vmdkPath)
 	if err != nil {
+		log.Printf("[DEBUG] resourceVSphereVirtualDiskRead - could not search datastore for: %v", vDisk.vmdkPath)
 		return err
 	}
+	if fileInfo == nil {
+		d.SetId("")
+		log.Printf("[DEBUG] resourceVSphereVirtualDiskRead - could not find: %v", vDisk.vmdkPath)
+		return nil
+	}
+	log.Printf("[DEBUG] resourceVSphereVirtualDiskRead - fileinfo: %#v", fileInfo)
 
-	// `Datastore.Stat` does not allow to query `VmDiskFileQuery`. Instead, we
-	// search the datastore manually.
-	spec := types.HostDatastoreBrowserSearchSpec{
-		Query: []types.BaseFileQuery{&types.VmDiskFileQuery{Details: &types.VmDiskFileQueryFlags{
-			CapacityKb: true,
-			DiskType:   true,
-		}}},
-		Details: &types.FileQueryFlags{
-			FileSize:     true,
-			FileType:     true,
-			Modification: true,
-			FileOwner:    types.NewBool(true),
-		},
-		MatchPattern: []string{path.Base(vDisk.vmdkPath)},
+	diskPath := ds.Path(vDisk.vmdkPath)
+	d.SetId(diskPath)
+	d.Set("size", fileInfo.CapacityKb/1024/1024)
+	d.Set("vmdk_path", vDisk.vmdkPath)
+
+	diskType, err := virtualDiskType(client, diskPath, dc)
+	if err != nil {
+		// The disk info query is not available everywhere, so fall back to the
+		// thin flag from the datastore search.
+		log.Printf("[DEBUG] resourceVSphereVirtualDiskRead - could not query type of %q: %s", diskPath, err)
+		if fileInfo.Thin != nil && *fileInfo.Thin {
+			diskType = "thin"
+		}
 	}
+	if diskType != "" {
+		d.Set("type", diskType)
+	}
+
+	return nil
+}
 
-	dsPath := ds.Path(path.Dir(vDisk.vmdkPath))
-	task, err := b.SearchDatastore(context.TODO(), dsPath, &spec)
+func resourceVSphereVirtualDiskUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
 
+	dc, err := getDatacenter(client, d.Get("datacenter").(string))
 	if err != nil {
-		log.Printf("[DEBUG] resourceVSphereVirtualDiskRead - could not search datastore for: %v", vDisk.vmdkPath)
 		return err
 	}
 
-	info, err := task.WaitForResult(context.TODO(), nil)
-	if err != nil {
-		if info == nil || info.Error != nil {
-			_, ok := info.Error.Fault.(*types.FileNotFound)
-			if ok {
-				log.Printf("[DEBUG] resourceVSphereVirtualDiskRead - could not find: %v", vDisk.vmdkPath)
-				d.SetId("")
-				return nil
-			}
-		}
+	finder := find.NewFinder(client.Client, true)
+	finder = finder.SetDatacenter(dc)
 
-		log.Printf("[DEBUG] resourceVSphereVirtualDiskRead - could not search datastore for: %v", vDisk.vmdkPath)
+	ds, err := getDatastore(finder, d.Get("datastore").(string))
+	if err != nil {
 		return err
 	}
+	diskPath := ds.Path(d.Get("vmdk_path").(string))
 
-	res := info.Result.(types.HostDatastoreBrowserSearchResults)
-	log.Printf("[DEBUG] num results: %d", len(res.File))
-	if len(res.File) == 0 {
-		d.SetId("")
-		log.Printf("[DEBUG] resourceVSphereVirtualDiskRead - could not find: %v", vDisk.vmdkPath)
-		return nil
+	if d.HasChange("type") {
+		o, n := d.GetChange("type")
+		if o.(string) != "thin" || n.(string) != "eagerZeroedThick" {
+			return fmt.Errorf("cannot change type of disk %q from %q to %q, only thin to eagerZeroedThick is supported", diskPath, o, n)
+		}
+		log.Printf("[DEBUG] Inflating disk %q", diskPath)
+		if err := inflateVirtualDisk(client, diskPath, dc); err != nil {
+			return fmt.Errorf("error inflating disk %q: %s", diskPath, err)
+		}
 	}
 
-	if len(res.File) != 1 {
-		return errors.New("Datastore search did not return exactly one result")
+	if d.HasChange("size") {
+		o, n := d.GetChange("size")
+		if n.(int) < o.(int) {
+			return fmt.Errorf("cannot shrink disk %q from %dGB to %dGB", diskPath, o, n)
+		}
+		log.Printf("[DEBUG] Extending disk %q to %dGB", diskPath, n)
+		eagerZero := d.Get("type").(string) == "eagerZeroedThick"
+		if err := extendVirtualDisk(client, diskPath, dc, int64(1024*1024*n.(int)), eagerZero); err != nil {
+			return fmt.Errorf("error extending disk %q: %s", diskPath, err)
+		}
 	}
 
-	fileInfo := res.File[0]
-	log.Printf("[DEBUG] resourceVSphereVirtualDiskRead - fileinfo: %#v", fileInfo)
-	size := fileInfo.(*types.VmDiskFileInfo).CapacityKb / 1024 / 1024
-
-	d.SetId(vDisk.vmdkPath)
-
-	d.Set("size", size)
-	d.Set("vmdk_path", vDisk.vmdkPath)
-	d.Set("datacenter", d.Get("datacenter"))
-	d.Set("datastore", d.Get("datastore"))
-	// Todo collect and write type info
-
-	return nil
-
+	return resourceVSphereVirtualDiskRead(d, meta)
 }
 
 func resourceVSphereVirtualDiskDelete(d *schema.ResourceData, meta interface{}) error {
@@ -301,17 +315,9 @@ func resourceVSphereVirtualDiskDelete(d *schema.ResourceData, meta interface{})
 	return nil
 }
 
-// createHardDisk creates a new Hard Disk.
-func createHardDisk(client *govmomi.Client, size int, diskPath string, diskType string, adapterType string, dc string) error {
-	var vDiskType string
-	switch diskType {
-	case "thin":
-		vDiskType = "thin"
-	case "eagerZeroedThick":
-		vDiskType = "eagerZeroedThick"
-	case "lazy":
-		vDiskType = "preallocated"
-	}
+// createHardDisk creates a new Hard Disk, with an optional storage policy.
+func createHardDisk(client *govmomi.Client, size int, diskPath string, diskType string, adapterType string, dc string, storagePolicyID string) error {
+	vDiskType := virtualDiskSpecType(diskType)
 
 	virtualDiskManager := object.NewVirtualDiskManager(client.Client)
 	spec := &types.FileBackedVirtualDiskSpec{
@@ -321,6 +327,9 @@ func createHardDisk(client *govmomi.Client, size int, diskPath string, diskType
 		},
 		CapacityKb: int64(1024 * 1024 * size),
 	}
+	if storagePolicyID != "" {
+		spec.Profile = expandVirtualMachineProfileSpec(storagePolicyID)
+	}
 	datacenter, err := getDatacenter(client, dc)
 	if err != nil {
 		return err
@@ -341,3 +350,124 @@ func createHardDisk(client *govmomi.Client, size int, diskPath string, diskType
 
 	return nil
 }
+
+// copyVirtualDiskFromSource creates a disk as a copy of the disk at
+// sourceVmdkPath, which is either a full datastore path or a path relative to
+// the destination datastore. The copy is extended if its size is larger than
+// the source disk.
+func copyVirtualDiskFromSource(client *govmomi.Client, finder *find.Finder, dc *object.Datacenter, ds *object.Datastore, vDisk virtualDisk) error {
+	if vDisk.storagePolicyID != "" {
+		return fmt.Errorf("storage_policy_id cannot be used with source_vmdk_path")
+	}
+
+	var source object.DatastorePath
+	if !source.FromString(vDisk.sourceVmdkPath) {
+		source = object.DatastorePath{
+			Datastore: ds.Name(),
+			Path:      vDisk.sourceVmdkPath,
+		}
+	}
+	sourceDs, err := getDatastore(finder, source.Datastore)
+	if err != nil {
+		return fmt.Errorf("error finding source datastore %q: %s", source.Datastore, err)
+	}
+	sourceInfo, err := virtualDiskFileInfo(sourceDs, source.Path)
+	if err != nil {
+		return fmt.Errorf("error finding source disk %q: %s", source.String(), err)
+	}
+	if sourceInfo == nil {
+		return fmt.Errorf("source disk %q not found", source.String())
+	}
+	capacityKb := int64(1024 * 1024 * vDisk.size)
+	if capacityKb < sourceInfo.CapacityKb {
+		return fmt.Errorf("size (%dGB) is smaller than the size of source disk %q (%dKB)", vDisk.size, source.String(), sourceInfo.CapacityKb)
+	}
+
+	diskPath := ds.Path(vDisk.vmdkPath)
+	spec := &types.VirtualDiskSpec{
+		AdapterType: vDisk.adapterType,
+		DiskType:    virtualDiskSpecType(vDisk.initType),
+	}
+	log.Printf("[DEBUG] Copying disk %q to %q", source.String(), diskPath)
+	if err := copyVirtualDisk(client, source.String(), diskPath, dc, spec); err != nil {
+		return fmt.Errorf("error copying disk %q: %s", source.String(), err)
+	}
+	if capacityKb > sourceInfo.CapacityKb {
+		log.Printf("[DEBUG] Extending disk %q to %dGB", diskPath, vDisk.size)
+		if err := extendVirtualDisk(client, diskPath, dc, capacityKb, vDisk.initType == "eagerZeroedThick"); err != nil {
+			return fmt.Errorf("error extending disk %q: %s", diskPath, err)
+		}
+	}
+	return nil
+}
+
+// virtualDiskSpecType returns the VirtualDiskType for a value of the type
+// argument.
+func virtualDiskSpecType(diskType string) string {
+	switch diskType {
+	case "thin":
+		return string(types.VirtualDiskTypeThin)
+	case "eagerZeroedThick":
+		return string(types.VirtualDiskTypeEagerZeroedThick)
+	case "lazy":
+		return string(types.VirtualDiskTypePreallocated)
+	}
+	return ""
+}
+
+func resourceVSphereVirtualDiskImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	// Our subject is the datastore path of the disk, optionally prefixed with
+	// the name or inventory path of the datacenter, ie:
+	// "dc1:[datastore1] path/disk.vmdk". The default datacenter is used if none
+	// is given.
+	dcName, p, ok := parseVirtualDiskImportID(d.Id())
+	if !ok {
+		return nil, fmt.Errorf("invalid import ID %q, must be in the format [datacenter:][datastore] path/disk.vmdk", d.Id())
+	}
+	client := meta.(*VSphereClient).vimClient
+	dc, err := getDatacenter(client, dcName)
+	if err != nil {
+		return nil, fmt.Errorf("error finding datacenter %q: %s", dcName, err)
+	}
+	finder := find.NewFinder(client.Client, true)
+	finder = finder.SetDatacenter(dc)
+	ds, err := getDatastore(finder, p.Datastore)
+	if err != nil {
+		return nil, fmt.Errorf("error finding datastore %q: %s", p.Datastore, err)
+	}
+	fileInfo, err := virtualDiskFileInfo(ds, p.Path)
+	if err != nil {
+		return nil, err
+	}
+	if fileInfo == nil {
+		return nil, fmt.Errorf("disk %q not found", d.Id())
+	}
+	d.SetId(p.String())
+	if dcName != "" {
+		d.Set("datacenter", dcName)
+	}
+	d.Set("vmdk_path", p.Path)
+	d.Set("datastore", p.Datastore)
+	// The adapter type can't be changed, so it is only read on import.
+	if adapterType := virtualDiskAdapterType(fileInfo.ControllerType); adapterType != "" {
+		d.Set("adapter_type", adapterType)
+	}
+	return []*schema.ResourceData{d}, nil
+}
+
+// parseVirtualDiskImportID splits an import ID for vsphere_virtual_disk into
+// the datacenter, if one was given, and the datastore path of the disk.
+func parseVirtualDiskImportID(id string) (string, object.DatastorePath, bool) {
+	var dc string
+	var p object.DatastorePath
+	if i := strings.Index(id, ":["); i >= 0 {
+		dc, id = id[:i], id[i+1:]
+		if dc == "" {
+			return "", p, false
+		}
+	}
+	if !p.FromString(id) || p.Datastore == "" || !strings.HasSuffix(p.Path, ".vmdk") {
+		return "", p, false
+	}
+	return dc, p, true
+}
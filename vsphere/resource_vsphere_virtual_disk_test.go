@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"regexp"
 	"testing"
 
 	"github.com/hashicorp/terraform/helper/acctest"
@@ -51,6 +52,69 @@ func TestAccVSphereVirtualDisk_basic(t *testing.T) {
 	})
 }
 
+func TestAccVSphereVirtualDisk_growAndInflate(t *testing.T) {
+	rString := acctest.RandString(5)
+	datacenter := os.Getenv("VSPHERE_DATACENTER")
+	datastore := os.Getenv("VSPHERE_DATASTORE")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckVSphereVirtualDiskDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckVSphereVirtualDiskConfig_sizeAndType(rString, 1, "thin", datacenter, datastore),
+				Check: resource.ComposeTestCheckFunc(
+					testAccVSphereVirtualDiskExists("vsphere_virtual_disk.foo"),
+					resource.TestCheckResourceAttr("vsphere_virtual_disk.foo", "type", "thin"),
+				),
+			},
+			{
+				Config: testAccCheckVSphereVirtualDiskConfig_sizeAndType(rString, 2, "eagerZeroedThick", datacenter, datastore),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vsphere_virtual_disk.foo", "size", "2"),
+					resource.TestCheckResourceAttr("vsphere_virtual_disk.foo", "type", "eagerZeroedThick"),
+				),
+			},
+			{
+				Config:      testAccCheckVSphereVirtualDiskConfig_sizeAndType(rString, 1, "eagerZeroedThick", datacenter, datastore),
+				ExpectError: regexp.MustCompile("cannot shrink disk"),
+			},
+		},
+	})
+}
+
+func TestAccVSphereVirtualDisk_copyAndImport(t *testing.T) {
+	rString := acctest.RandString(5)
+	datacenter := os.Getenv("VSPHERE_DATACENTER")
+	datastore := os.Getenv("VSPHERE_DATASTORE")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckVSphereVirtualDiskDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckVSphereVirtualDiskConfig_copy(rString, datacenter, datastore),
+				Check: resource.ComposeTestCheckFunc(
+					testAccVSphereVirtualDiskExists("vsphere_virtual_disk.copy"),
+					resource.TestCheckResourceAttr("vsphere_virtual_disk.copy", "size", "2"),
+				),
+			},
+			{
+				ResourceName:            "vsphere_virtual_disk.copy",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"source_vmdk_path"},
+				ImportStateIdFunc: func(s *terraform.State) (string, error) {
+					return fmt.Sprintf("%s:[%s] tfTestDiskCopy-%s.vmdk", datacenter, datastore, rString), nil
+				},
+				Config: testAccCheckVSphereVirtualDiskConfig_copy(rString, datacenter, datastore),
+			},
+		},
+	})
+}
+
 func testAccVSphereVirtualDiskExists(name string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		rs, ok := s.RootModule().Resources[name]
@@ -127,3 +191,61 @@ resource "vsphere_virtual_disk" "foo" {
 }
 `, rName, initTypeOpt, adapterTypeOpt, datacenterOpt, datastoreOpt)
 }
+
+func testAccCheckVSphereVirtualDiskConfig_sizeAndType(rName string, size int, diskType, datacenter, datastore string) string {
+	return fmt.Sprintf(`
+resource "vsphere_virtual_disk" "foo" {
+  size       = %d
+  vmdk_path  = "tfTestDisk-%s.vmdk"
+  type       = "%s"
+  datacenter = "%s"
+  datastore  = "%s"
+}
+`, size, rName, diskType, datacenter, datastore)
+}
+
+func testAccCheckVSphereVirtualDiskConfig_copy(rName, datacenter, datastore string) string {
+	return fmt.Sprintf(`
+resource "vsphere_virtual_disk" "foo" {
+  size       = 1
+  vmdk_path  = "tfTestDisk-%s.vmdk"
+  type       = "thin"
+  datacenter = "%s"
+  datastore  = "%s"
+}
+
+resource "vsphere_virtual_disk" "copy" {
+  size             = 2
+  vmdk_path        = "tfTestDiskCopy-%s.vmdk"
+  source_vmdk_path = "${vsphere_virtual_disk.foo.vmdk_path}"
+  type             = "thin"
+  datacenter       = "%s"
+  datastore        = "%s"
+}
+`, rName, datacenter, datastore, rName, datacenter, datastore)
+}
+
+func TestParseVirtualDiskImportID(t *testing.T) {
+	cases := []struct {
+		id   string
+		dc   string
+		path string
+		ok   bool
+	}{
+		{id: "[datastore1] disks/disk1.vmdk", path: "[datastore1] disks/disk1.vmdk", ok: true},
+		{id: "dc1:[datastore1] disks/disk1.vmdk", dc: "dc1", path: "[datastore1] disks/disk1.vmdk", ok: true},
+		{id: "/dc1/folder/dc2:[datastore1] disk1.vmdk", dc: "/dc1/folder/dc2", path: "[datastore1] disk1.vmdk", ok: true},
+		{id: ":[datastore1] disk1.vmdk"},
+		{id: "dc1:[datastore1] disk1.iso"},
+		{id: "disks/disk1.vmdk"},
+	}
+	for _, tc := range cases {
+		dc, p, ok := parseVirtualDiskImportID(tc.id)
+		if ok != tc.ok {
+			t.Fatalf("%q: expected ok to be %t, got %t", tc.id, tc.ok, ok)
+		}
+		if ok && (dc != tc.dc || p.String() != tc.path) {
+			t.Fatalf("%q: expected %q and %q, got %q and %q", tc.id, tc.dc, tc.path, dc, p.String())
+		}
+	}
+}
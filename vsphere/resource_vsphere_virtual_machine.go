@@ -55,13 +55,14 @@ type networkInterface struct {
 }
 
 type hardDisk struct {
-	name       string
-	size       int64
-	iops       int64
-	initType   string
-	vmdkPath   string
-	controller string
-	bootable   bool
+	name            string
+	size            int64
+	iops            int64
+	initType        string
+	vmdkPath        string
+	controller      string
+	bootable        bool
+	storagePolicyID string
 }
 
 //Additional options Vsphere can use clones of windows machines
@@ -110,6 +111,9 @@ type virtualMachine struct {
 	windowsOptionalConfig    windowsOptConfig
 	customConfigurations     map[string](types.AnyType)
 	customizationWaitTimeout int
+	devices                  virtualMachineDeviceSets
+	hardwareVersion          int
+	storagePolicyID          string
 }
 
 func (v virtualMachine) Path() string {
@@ -170,6 +174,19 @@ func resourceVSphereVirtualMachine() *schema.Resource {
 				ForceNew: true,
 			},
 
+			"hardware_version": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: validation.IntAtLeast(4),
+			},
+
+			"storage_policy_id": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+
 			"annotation": &schema.Schema{
 				Type:     schema.TypeString,
 				Optional: true,
@@ -253,6 +270,32 @@ func resourceVSphereVirtualMachine() *schema.Resource {
 				Default:  true,
 			},
 
+			"wait_for_guest_net_routable": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+
+			"ignored_guest_ips": &schema.Schema{
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.CIDRNetwork(0, 128),
+				},
+			},
+
+			"default_ip_address": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"guest_ip_addresses": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
 			"enable_disk_uuid": &schema.Schema{
 				Type:     schema.TypeBool,
 				Optional: true,
@@ -271,10 +314,17 @@ func resourceVSphereVirtualMachine() *schema.Resource {
 			},
 
 			"power_state": &schema.Schema{
-				Type:         schema.TypeString,
-				Optional:     true,
-				Default:      string(types.VirtualMachinePowerStatePoweredOn),
-				ValidateFunc: validation.StringInSlice([]string{string(types.VirtualMachinePowerStatePoweredOn)}, false),
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          string(types.VirtualMachinePowerStatePoweredOn),
+				ValidateFunc:     validation.StringInSlice([]string{string(types.VirtualMachinePowerStatePoweredOn)}, false),
+				DiffSuppressFunc: suppressPowerStateForTemplates,
+			},
+
+			"template": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
 			},
 
 			"custom_configuration_parameters": &schema.Schema{
@@ -410,94 +460,8 @@ func resourceVSphereVirtualMachine() *schema.Resource {
 			"disk": &schema.Schema{
 				Type:     schema.TypeSet,
 				Required: true,
-				Elem: &schema.Resource{
-					Schema: map[string]*schema.Schema{
-						"uuid": &schema.Schema{
-							Type:     schema.TypeString,
-							Computed: true,
-						},
-
-						"key": &schema.Schema{
-							Type:     schema.TypeInt,
-							Computed: true,
-						},
-
-						"template": &schema.Schema{
-							Type:     schema.TypeString,
-							Optional: true,
-						},
-
-						"type": &schema.Schema{
-							Type:     schema.TypeString,
-							Optional: true,
-							Default:  "eager_zeroed",
-							ValidateFunc: func(v interface{}, k string) (ws []string, errors []error) {
-								value := v.(string)
-								if value != "thin" && value != "eager_zeroed" && value != "lazy" {
-									errors = append(errors, fmt.Errorf(
-										"only 'thin', 'eager_zeroed', and 'lazy' are supported values for 'type'"))
-								}
-								return
-							},
-						},
-
-						"datastore": &schema.Schema{
-							Type:     schema.TypeString,
-							Optional: true,
-						},
-
-						"size": &schema.Schema{
-							Type:     schema.TypeInt,
-							Optional: true,
-						},
-
-						"name": &schema.Schema{
-							Type:     schema.TypeString,
-							Optional: true,
-						},
-
-						"iops": &schema.Schema{
-							Type:     schema.TypeInt,
-							Optional: true,
-						},
-
-						"vmdk": &schema.Schema{
-							// TODO: Add ValidateFunc to confirm path exists
-							Type:     schema.TypeString,
-							Optional: true,
-						},
-
-						"bootable": &schema.Schema{
-							Type:     schema.TypeBool,
-							Optional: true,
-						},
-
-						"keep_on_remove": &schema.Schema{
-							Type:     schema.TypeBool,
-							Optional: true,
-						},
-
-						"controller_type": &schema.Schema{
-							Type:     schema.TypeString,
-							Optional: true,
-							Default:  "scsi",
-							ValidateFunc: func(v interface{}, k string) (ws []string, errors []error) {
-								value := v.(string)
-								found := false
-								for _, t := range DiskControllerTypes {
-									if t == value {
-										found = true
-									}
-								}
-								if !found {
-									errors = append(errors, fmt.Errorf(
-										"Supported values for 'controller_type' are %v", strings.Join(DiskControllerTypes, ", ")))
-								}
-								return
-							},
-						},
-					},
-				},
+				Set:      resourceVSphereVirtualMachineDiskHash,
+				Elem:     virtualMachineDiskResource,
 			},
 
 			"detach_unknown_disks_on_delete": &schema.Schema{
@@ -527,6 +491,12 @@ func resourceVSphereVirtualMachine() *schema.Resource {
 				},
 			},
 
+			"serial_port": schemaVirtualMachineSerialPort(),
+
+			"usb_controller": schemaVirtualMachineUSBController(),
+
+			"pci_device": schemaVirtualMachinePCIDevice(),
+
 			// Tagging
 			vSphereTagAttributeKey: tagsSchema(),
 		},
@@ -580,6 +550,49 @@ func resourceVSphereVirtualMachineUpdate(d *schema.ResourceData, meta interface{
 		return err
 	}
 
+	// Templates can't be reconfigured or powered on. If the VM is being converted
+	// from a template, do that first so that the rest of the update can proceed
+	// on a regular VM. A VM being converted to a template is converted after any
+	// other changes have been made.
+	oldTemplate, newTemplate := d.GetChange("template")
+	if oldTemplate.(bool) && newTemplate.(bool) {
+		for _, k := range []string{"vcpu", "memory", "annotation", "disk", "hardware_version", "storage_policy_id", "serial_port", "usb_controller", "pci_device", "network_interface"} {
+			if d.HasChange(k) {
+				return fmt.Errorf("cannot change %s on virtual machine %s while it is a template, set template to false first", k, d.Id())
+			}
+		}
+	}
+	if oldTemplate.(bool) && !newTemplate.(bool) {
+		pool, err := virtualMachineResourcePool(finder, d.Get("cluster").(string), d.Get("resource_pool").(string))
+		if err != nil {
+			return err
+		}
+		log.Printf("[INFO] Converting template %s to a virtual machine", d.Id())
+		if err := vm.MarkAsVirtualMachine(context.TODO(), *pool, nil); err != nil {
+			return fmt.Errorf("error converting template to virtual machine: %s", err)
+		}
+	}
+
+	// Validate any virtual hardware upgrade before making any changes. The
+	// upgrade itself happens while the VM is powered off.
+	var upgradeHardwareVersion int
+	if d.HasChange("hardware_version") {
+		o, n := d.GetChange("hardware_version")
+		oldVersion, newVersion := o.(int), n.(int)
+		if newVersion < oldVersion {
+			return fmt.Errorf("cannot downgrade virtual hardware version from %d to %d", oldVersion, newVersion)
+		}
+		props, err := virtualMachineProperties(vm)
+		if err != nil {
+			return err
+		}
+		if err := validateVirtualMachineHardwareVersion(client, props.EnvironmentBrowser, newVersion, false); err != nil {
+			return err
+		}
+		upgradeHardwareVersion = newVersion
+		rebootRequired = true
+	}
+
 	// Apply any pending tags now, before proceeding with any expensive VM updates
 	if tagsClient != nil {
 		if err := processTagDiff(tagsClient, d, vm); err != nil {
@@ -587,6 +600,41 @@ func resourceVSphereVirtualMachineUpdate(d *schema.ResourceData, meta interface{
 		}
 	}
 
+	// Nothing else can be changed on a VM that stays a template, and it must not
+	// be powered on.
+	if oldTemplate.(bool) && newTemplate.(bool) {
+		return resourceVSphereVirtualMachineRead(d, meta)
+	}
+
+	// Serial ports and PCI passthrough devices can't be hot-added, so any
+	// device changes are made while the VM is powered off.
+	if d.HasChange("serial_port") || d.HasChange("usb_controller") || d.HasChange("pci_device") {
+		oldDevices, newDevices := virtualMachineDeviceSetsFromResourceData(d)
+		deviceChange, err := expandVirtualMachineDeviceChanges(client, vm, oldDevices, newDevices)
+		if err != nil {
+			return err
+		}
+		configSpec.DeviceChange = append(configSpec.DeviceChange, deviceChange...)
+		if d.HasChange("pci_device") {
+			locked := newDevices.hasPCIDevices()
+			configSpec.MemoryReservationLockedToMax = boolPtr(locked)
+			if !locked {
+				// Releasing the lock leaves the full memory size reserved, so put the
+				// configured reservation back.
+				configSpec.MemoryAllocation = &types.ResourceAllocationInfo{
+					Reservation: int64Ptr(int64(d.Get("memory_reservation").(int))),
+				}
+			}
+		}
+		hasChanges = true
+		rebootRequired = true
+	}
+
+	if d.HasChange("storage_policy_id") {
+		configSpec.VmProfile = expandVirtualMachineProfileSpec(d.Get("storage_policy_id").(string))
+		hasChanges = true
+	}
+
 	if d.HasChange("disk") {
 		hasChanges = true
 		oldDisks, newDisks := d.GetChange("disk")
@@ -596,6 +644,14 @@ func resourceVSphereVirtualMachineUpdate(d *schema.ResourceData, meta interface{
 		addedDisks := newDiskSet.Difference(oldDiskSet)
 		removedDisks := oldDiskSet.Difference(newDiskSet)
 
+		// storage_policy_id is not part of the disk hash, so disks that only had
+		// their policy changed are in both sets and are updated in place.
+		policyChanges, err := expandVirtualMachineDiskStoragePolicyChanges(vm, oldDiskSet, newDiskSet)
+		if err != nil {
+			return err
+		}
+		configSpec.DeviceChange = append(configSpec.DeviceChange, policyChanges...)
+
 		// Removed disks
 		for _, diskRaw := range removedDisks.List() {
 			if disk, ok := diskRaw.(map[string]interface{}); ok {
@@ -670,7 +726,7 @@ func resourceVSphereVirtualMachineUpdate(d *schema.ResourceData, meta interface{
 				}
 
 				log.Printf("[INFO] Attaching disk: %v", diskPath)
-				err = addHardDisk(vm, size, iops, initType, datastore, diskPath, controller_type)
+				err = addHardDisk(vm, size, iops, initType, datastore, diskPath, controller_type, disk["storage_policy_id"].(string))
 				if err != nil {
 					log.Printf("[ERROR] Add Hard Disk Failed: %v", err)
 					return err
@@ -722,6 +778,20 @@ func resourceVSphereVirtualMachineUpdate(d *schema.ResourceData, meta interface{
 		}
 	}
 
+	if upgradeHardwareVersion != 0 {
+		log.Printf("[INFO] Upgrading virtual hardware of virtual machine %s to version %d", d.Id(), upgradeHardwareVersion)
+		if err := upgradeVirtualMachineHardware(client, vm, upgradeHardwareVersion); err != nil {
+			return fmt.Errorf("error upgrading virtual hardware: %s", err)
+		}
+	}
+
+	if !oldTemplate.(bool) && newTemplate.(bool) {
+		if err := markVirtualMachineAsTemplate(vm); err != nil {
+			return err
+		}
+		return resourceVSphereVirtualMachineRead(d, meta)
+	}
+
 	if rebootRequired || powerState != types.VirtualMachinePowerStatePoweredOn {
 		task, err := vm.PowerOn(context.TODO())
 		if err != nil {
@@ -737,11 +807,9 @@ func resourceVSphereVirtualMachineUpdate(d *schema.ResourceData, meta interface{
 		// Wait for VM guest networking before returning, so that Read can get
 		// accurate networking info for the state.
 		if d.Get("wait_for_guest_net").(bool) {
-			log.Printf("[DEBUG] Waiting for routeable guest network access")
-			if err := waitForGuestVMNet(client, vm); err != nil {
+			if err := waitForGuestVMNetFromResourceData(d, client, vm); err != nil {
 				return err
 			}
-			log.Printf("[DEBUG] Guest has routeable network access.")
 		}
 	}
 
@@ -766,11 +834,20 @@ func resourceVSphereVirtualMachineCreate(d *schema.ResourceData, meta interface{
 		},
 		customizationWaitTimeout: d.Get("wait_for_customization_timeout").(int),
 	}
+	_, vm.devices = virtualMachineDeviceSetsFromResourceData(d)
 
 	if v, ok := d.GetOk("hostname"); ok {
 		vm.hostname = v.(string)
 	}
 
+	if v, ok := d.GetOk("hardware_version"); ok {
+		vm.hardwareVersion = v.(int)
+	}
+
+	if v, ok := d.GetOk("storage_policy_id"); ok {
+		vm.storagePolicyID = v.(string)
+	}
+
 	if v, ok := d.GetOk("folder"); ok {
 		vm.folder = v.(string)
 	}
@@ -965,6 +1042,10 @@ func resourceVSphereVirtualMachineCreate(d *schema.ResourceData, meta interface{
 					newDisk.controller = v
 				}
 
+				if v, ok := disk["storage_policy_id"].(string); ok && v != "" {
+					newDisk.storagePolicyID = v
+				}
+
 				if vVmdk, ok := disk["vmdk"].(string); ok && vVmdk != "" {
 					if v, ok := disk["template"].(string); ok && v != "" {
 						return fmt.Errorf("Cannot specify a vmdk for a template")
@@ -1041,11 +1122,15 @@ func resourceVSphereVirtualMachineCreate(d *schema.ResourceData, meta interface{
 	if newProps.Runtime.PowerState == types.VirtualMachinePowerStatePoweredOn && d.Get("wait_for_guest_net").(bool) {
 		// We also need to wait for the guest networking to ensure an accurate set
 		// of information can be read into state and reported to the provisioners.
-		log.Printf("[DEBUG] Waiting for routeable guest network access")
-		if err := waitForGuestVMNet(client, newVM); err != nil {
+		if err := waitForGuestVMNetFromResourceData(d, client, newVM); err != nil {
+			return err
+		}
+	}
+
+	if d.Get("template").(bool) {
+		if err := markVirtualMachineAsTemplate(newVM); err != nil {
 			return err
 		}
-		log.Printf("[DEBUG] Guest has routeable network access.")
 	}
 	return resourceVSphereVirtualMachineRead(d, meta)
 }
@@ -1091,6 +1176,9 @@ func resourceVSphereVirtualMachineRead(d *schema.ResourceData, meta interface{})
 		log.Printf("[DEBUG] Set the moid: %#v", mvm.Reference().Value)
 	}
 
+	// Storage policies can only be read on vCenter. The client is nil otherwise.
+	pbmClient, _ := meta.(*VSphereClient).PbmClient()
+
 	disks := make([]map[string]interface{}, 0)
 	templateDisk := make(map[string]interface{}, 1)
 	for _, device := range mvm.Config.Hardware.Device {
@@ -1145,6 +1233,17 @@ func resourceVSphereVirtualMachineRead(d *schema.ResourceData, meta interface{})
 							prevDisk["key"] = virtualDevice.Key
 							prevDisk["uuid"] = diskUuid
 
+							// Only track the policy of disks that have one configured, so
+							// that disks picking up a datastore default policy don't show
+							// a diff.
+							if pbmClient != nil && prevDisk["storage_policy_id"] != "" {
+								policyID, err := virtualDiskStoragePolicyID(pbmClient, vm, virtualDevice.Key)
+								if err != nil {
+									return err
+								}
+								prevDisk["storage_policy_id"] = policyID
+							}
+
 							disks = append(disks, prevDisk)
 							break
 						}
@@ -1271,14 +1370,26 @@ func resourceVSphereVirtualMachineRead(d *schema.ResourceData, meta interface{})
 		return fmt.Errorf("Invalid network interfaces to set: %#v", networkInterfaces)
 	}
 
-	if len(networkInterfaces) > 0 {
-		if _, ok := networkInterfaces[0]["ipv4_address"]; ok {
-			log.Printf("[DEBUG] ip address: %v", networkInterfaces[0]["ipv4_address"].(string))
-			d.SetConnInfo(map[string]string{
-				"type": "ssh",
-				"host": networkInterfaces[0]["ipv4_address"].(string),
-			})
-		}
+	if err := flattenVirtualMachineDeviceSets(d, mvm.Config.Hardware.Device); err != nil {
+		return err
+	}
+
+	ignoredGuestIPs, err := parseGuestIPNetworks(d.Get("ignored_guest_ips").([]interface{}))
+	if err != nil {
+		return err
+	}
+	guestIPs := selectGuestIPs(mvm.Guest.Net, mvm.Guest.IpStack, ignoredGuestIPs)
+	log.Printf("[DEBUG] guest ip addresses: %v", guestIPs.addresses)
+	if err := d.Set("guest_ip_addresses", guestIPs.addresses); err != nil {
+		return fmt.Errorf("Invalid guest IP addresses to set: %#v", guestIPs.addresses)
+	}
+	d.Set("default_ip_address", guestIPs.defaultAddress)
+	if guestIPs.defaultAddress != "" {
+		log.Printf("[DEBUG] default ip address: %v", guestIPs.defaultAddress)
+		d.SetConnInfo(map[string]string{
+			"type": "ssh",
+			"host": guestIPs.defaultAddress,
+		})
 	}
 
 	var rootDatastore string
@@ -1303,12 +1414,30 @@ func resourceVSphereVirtualMachineRead(d *schema.ResourceData, meta interface{})
 
 	d.Set("datacenter", dc)
 	d.Set("memory", mvm.Summary.Config.MemorySizeMB)
-	d.Set("memory_reservation", mvm.Summary.Config.MemoryReservation)
+	// When the memory reservation is locked to the configured memory, such as
+	// when PCI passthrough devices are present, the reservation reported by
+	// vSphere tracks the memory size and not the configured value.
+	if mvm.Config.MemoryReservationLockedToMax == nil || !*mvm.Config.MemoryReservationLockedToMax {
+		d.Set("memory_reservation", mvm.Summary.Config.MemoryReservation)
+	}
 	d.Set("cpu", mvm.Summary.Config.NumCpu)
 	d.Set("datastore", rootDatastore)
 	d.Set("uuid", mvm.Summary.Config.Uuid)
 	d.Set("annotation", mvm.Summary.Config.Annotation)
+	if hv, err := parseVirtualMachineHardwareVersion(mvm.Config.Version); err == nil {
+		d.Set("hardware_version", hv)
+	} else {
+		log.Printf("[WARN] %s", err)
+	}
 	d.Set("power_state", mvm.Runtime.PowerState)
+	d.Set("template", mvm.Config.Template)
+	if pbmClient != nil {
+		policyID, err := virtualMachineStoragePolicyID(pbmClient, vm)
+		if err != nil {
+			return err
+		}
+		d.Set("storage_policy_id", policyID)
+	}
 
 	// Read tags if we have the ability to do so
 	if tagsClient, _ := meta.(*VSphereClient).TagsClient(); tagsClient != nil {
@@ -1339,6 +1468,23 @@ func resourceVSphereVirtualMachineDelete(d *schema.ResourceData, meta interface{
 		return err
 	}
 
+	// Templates can be destroyed directly, but disks can't be detached from
+	// them. Convert the template back to a VM if we need to detach any disks.
+	props, err := virtualMachineProperties(vm)
+	if err != nil {
+		return err
+	}
+	if props.Config != nil && props.Config.Template && virtualMachineDeleteDetachesDisks(d) {
+		pool, err := virtualMachineResourcePool(finder, d.Get("cluster").(string), d.Get("resource_pool").(string))
+		if err != nil {
+			return err
+		}
+		log.Printf("[INFO] Converting template %s to a virtual machine to detach disks", d.Id())
+		if err := vm.MarkAsVirtualMachine(context.TODO(), *pool, nil); err != nil {
+			return fmt.Errorf("error converting template to virtual machine: %s", err)
+		}
+	}
+
 	log.Printf("[INFO] Deleting virtual machine: %s", d.Id())
 	state, err := vm.PowerState(context.TODO())
 	if err != nil {
@@ -1422,8 +1568,9 @@ func resourceVSphereVirtualMachineDelete(d *schema.ResourceData, meta interface{
 	return nil
 }
 
-// addHardDisk adds a new Hard Disk to the VirtualMachine.
-func addHardDisk(vm *object.VirtualMachine, size, iops int64, diskType string, datastore *object.Datastore, diskPath string, controller_type string) error {
+// addHardDisk adds a new Hard Disk to the VirtualMachine. If a storage policy
+// ID is supplied, it is applied to the disk as it is added.
+func addHardDisk(vm *object.VirtualMachine, size, iops int64, diskType string, datastore *object.Datastore, diskPath string, controller_type string, storagePolicyID string) error {
 	devices, err := vm.Device(context.TODO())
 	if err != nil {
 		return err
@@ -1559,13 +1706,32 @@ func addHardDisk(vm *object.VirtualMachine, size, iops int64, diskType string, d
 
 		log.Printf("[DEBUG] addHardDisk: %#v\n", disk)
 		log.Printf("[DEBUG] addHardDisk capacity: %#v\n", disk.CapacityInKB)
-
-		return vm.AddDevice(context.TODO(), disk)
 	} else {
 		log.Printf("[DEBUG] addHardDisk: Disk already present.\n")
 
 		return nil
 	}
+
+	if storagePolicyID == "" {
+		return vm.AddDevice(context.TODO(), disk)
+	}
+	return addHardDiskWithStoragePolicy(vm, disk, storagePolicyID)
+}
+
+// addHardDiskWithStoragePolicy adds a disk to a virtual machine with a storage
+// policy attached to the device spec. The device spec is otherwise the same as
+// the one VirtualMachine.AddDevice would use.
+func addHardDiskWithStoragePolicy(vm *object.VirtualMachine, disk *types.VirtualDisk, storagePolicyID string) error {
+	deviceChange, err := object.VirtualDeviceList{disk}.ConfigSpec(types.VirtualDeviceConfigSpecOperationAdd)
+	if err != nil {
+		return err
+	}
+	deviceChange[0].GetVirtualDeviceConfigSpec().Profile = expandVirtualMachineProfileSpec(storagePolicyID)
+	task, err := vm.Reconfigure(context.TODO(), types.VirtualMachineConfigSpec{DeviceChange: deviceChange})
+	if err != nil {
+		return err
+	}
+	return task.Wait(context.TODO())
 }
 
 func getSCSIControllers(vmDevices object.VirtualDeviceList) []*types.VirtualController {
@@ -1898,32 +2064,42 @@ func (vm *virtualMachine) setupVirtualMachine(c *govmomi.Client) error {
 		}
 		log.Printf("[DEBUG] template: %#v", template)
 
-		err = template.Properties(context.TODO(), template.Reference(), []string{"parent", "config.template", "config.guestId", "resourcePool", "snapshot", "guest.toolsVersionStatus2", "config.guestFullName"}, &template_mo)
+		err = template.Properties(context.TODO(), template.Reference(), []string{"parent", "config.template", "config.guestId", "resourcePool", "snapshot", "guest.toolsVersionStatus2", "config.guestFullName", "config.version"}, &template_mo)
 		if err != nil {
 			return err
 		}
 	}
 
-	var resourcePool *object.ResourcePool
-	if vm.resourcePool == "" {
-		if vm.cluster == "" {
-			resourcePool, err = finder.DefaultResourcePool(context.TODO())
+	resourcePool, err := virtualMachineResourcePool(finder, vm.cluster, vm.resourcePool)
+	if err != nil {
+		return err
+	}
+	log.Printf("[DEBUG] resource pool: %#v", resourcePool)
+
+	// Validate the virtual hardware version. New VMs are created with it, and
+	// clones are upgraded to it if their template has an older version.
+	var upgradeHardware bool
+	if vm.hardwareVersion != 0 {
+		if template != nil {
+			templateVersion, err := parseVirtualMachineHardwareVersion(template_mo.Config.Version)
 			if err != nil {
 				return err
 			}
-		} else {
-			resourcePool, err = finder.ResourcePool(context.TODO(), "*"+vm.cluster+"/Resources")
+			if templateVersion > vm.hardwareVersion {
+				return fmt.Errorf("cannot downgrade virtual hardware version of template %q from %d to %d", vm.template, templateVersion, vm.hardwareVersion)
+			}
+			upgradeHardware = templateVersion < vm.hardwareVersion
+		}
+		if template == nil || upgradeHardware {
+			browser, err := environmentBrowserFromResourcePool(c, resourcePool)
 			if err != nil {
 				return err
 			}
-		}
-	} else {
-		resourcePool, err = finder.ResourcePool(context.TODO(), vm.resourcePool)
-		if err != nil {
-			return err
+			if err := validateVirtualMachineHardwareVersion(c, browser, vm.hardwareVersion, template == nil); err != nil {
+				return err
+			}
 		}
 	}
-	log.Printf("[DEBUG] resource pool: %#v", resourcePool)
 
 	dcFolders, err := dc.Folders(context.TODO())
 	if err != nil {
@@ -1961,6 +2137,16 @@ func (vm *virtualMachine) setupVirtualMachine(c *govmomi.Client) error {
 	}
 	if vm.template == "" {
 		configSpec.GuestId = "otherLinux64Guest"
+		if vm.hardwareVersion != 0 {
+			configSpec.Version = virtualMachineHardwareVersionString(vm.hardwareVersion)
+		}
+	}
+	if vm.devices.hasPCIDevices() {
+		// PCI passthrough requires all guest memory to be reserved.
+		configSpec.MemoryReservationLockedToMax = boolPtr(true)
+	}
+	if vm.storagePolicyID != "" {
+		configSpec.VmProfile = expandVirtualMachineProfileSpec(vm.storagePolicyID)
 	}
 	log.Printf("[DEBUG] virtual machine config spec: %v", configSpec)
 
@@ -2106,7 +2292,13 @@ func (vm *virtualMachine) setupVirtualMachine(c *govmomi.Client) error {
 
 		configSpec.Files = &types.VirtualMachineFileInfo{VmPathName: fmt.Sprintf("[%s]", mds.Name)}
 
-		task, err = folder.CreateVM(context.TODO(), configSpec, resourcePool, nil)
+		// Virtual machines in a vApp have to be created through the vApp itself.
+		if isVirtualAppReference(resourcePool.Reference()) {
+			vapp := &object.VirtualApp{ResourcePool: resourcePool}
+			task, err = vapp.CreateChildVM(context.TODO(), configSpec, nil)
+		} else {
+			task, err = folder.CreateVM(context.TODO(), configSpec, resourcePool, nil)
+		}
 		if err != nil {
 			log.Printf("[ERROR] %s", err)
 		}
@@ -2122,6 +2314,12 @@ func (vm *virtualMachine) setupVirtualMachine(c *govmomi.Client) error {
 		if err != nil {
 			return err
 		}
+		if vm.storagePolicyID != "" {
+			relocateSpec.Profile = expandVirtualMachineProfileSpec(vm.storagePolicyID)
+		}
+		if vm.hardDisks[0].storagePolicyID != "" {
+			relocateSpec.Disk[0].Profile = expandVirtualMachineProfileSpec(vm.hardDisks[0].storagePolicyID)
+		}
 
 		log.Printf("[DEBUG] relocate spec: %v", relocateSpec)
 
@@ -2157,6 +2355,13 @@ func (vm *virtualMachine) setupVirtualMachine(c *govmomi.Client) error {
 	}
 	log.Printf("[DEBUG] new vm: %v", newVM)
 
+	if upgradeHardware {
+		log.Printf("[DEBUG] Upgrading virtual hardware to version %d", vm.hardwareVersion)
+		if err := upgradeVirtualMachineHardware(c, newVM, vm.hardwareVersion); err != nil {
+			return fmt.Errorf("error upgrading virtual hardware: %s", err)
+		}
+	}
+
 	devices, err := newVM.Device(context.TODO())
 	if err != nil {
 		log.Printf("[DEBUG] Template devices can't be found")
@@ -2186,6 +2391,22 @@ func (vm *virtualMachine) setupVirtualMachine(c *govmomi.Client) error {
 		return err
 	}
 
+	// Add serial ports, USB controllers, and PCI passthrough devices.
+	deviceChange, err := expandVirtualMachineDeviceChanges(c, newVM, virtualMachineDeviceSets{}, vm.devices)
+	if err != nil {
+		return err
+	}
+	if len(deviceChange) > 0 {
+		log.Printf("[DEBUG] device changes: %#v", deviceChange)
+		t, err := newVM.Reconfigure(context.TODO(), types.VirtualMachineConfigSpec{DeviceChange: deviceChange})
+		if err != nil {
+			return err
+		}
+		if err := t.Wait(context.TODO()); err != nil {
+			return err
+		}
+	}
+
 	newVM.Properties(context.TODO(), newVM.Reference(), []string{"summary", "config"}, &vm_mo)
 	firstDisk := 0
 	if vm.template != "" {
@@ -2209,9 +2430,9 @@ func (vm *virtualMachine) setupVirtualMachine(c *govmomi.Client) error {
 		default:
 			return fmt.Errorf("[ERROR] setupVirtualMachine - Neither vmdk path nor vmdk name was given: %#v", vm.hardDisks[i])
 		}
-		err = addHardDisk(newVM, vm.hardDisks[i].size, vm.hardDisks[i].iops, vm.hardDisks[i].initType, datastore, diskPath, vm.hardDisks[i].controller)
+		err = addHardDisk(newVM, vm.hardDisks[i].size, vm.hardDisks[i].iops, vm.hardDisks[i].initType, datastore, diskPath, vm.hardDisks[i].controller, vm.hardDisks[i].storagePolicyID)
 		if err != nil {
-			err2 := addHardDisk(newVM, vm.hardDisks[i].size, vm.hardDisks[i].iops, vm.hardDisks[i].initType, datastore, diskPath, vm.hardDisks[i].controller)
+			err2 := addHardDisk(newVM, vm.hardDisks[i].size, vm.hardDisks[i].iops, vm.hardDisks[i].initType, datastore, diskPath, vm.hardDisks[i].controller, vm.hardDisks[i].storagePolicyID)
 			if err2 != nil {
 				return err2
 			}
@@ -2378,3 +2599,206 @@ func suppressIpDifferences(k, old, new string, d *schema.ResourceData) bool {
 	}
 	return false
 }
+
+// waitForGuestVMNetFromResourceData waits for guest networking on a virtual
+// machine, using the wait_for_guest_net_routable and ignored_guest_ips
+// settings in the supplied ResourceData.
+func waitForGuestVMNetFromResourceData(d *schema.ResourceData, client *govmomi.Client, vm *object.VirtualMachine) error {
+	routable := d.Get("wait_for_guest_net_routable").(bool)
+	ignored, err := parseGuestIPNetworks(d.Get("ignored_guest_ips").([]interface{}))
+	if err != nil {
+		return err
+	}
+	if routable {
+		log.Printf("[DEBUG] Waiting for routeable guest network access")
+	} else {
+		log.Printf("[DEBUG] Waiting for an available guest IP address")
+	}
+	if err := waitForGuestVMNet(client, vm, routable, ignored); err != nil {
+		return err
+	}
+	log.Printf("[DEBUG] Guest network is available.")
+	return nil
+}
+
+// suppressPowerStateForTemplates suppresses diffs on power_state when the
+// virtual machine is a template, as templates are always powered off.
+func suppressPowerStateForTemplates(k, old, new string, d *schema.ResourceData) bool {
+	return d.Get("template").(bool)
+}
+
+// virtualMachineResourcePool returns the resource pool that a virtual machine
+// should be placed in, based on the cluster and resource_pool arguments. The
+// default resource pool is used if neither is defined.
+//
+// resource_pool can also be the path to a vApp, in which case the returned
+// resource pool references the vApp.
+func virtualMachineResourcePool(finder *find.Finder, cluster, resourcePool string) (*object.ResourcePool, error) {
+	switch {
+	case resourcePool != "":
+		pool, err := finder.ResourcePool(context.TODO(), resourcePool)
+		if err == nil {
+			return pool, nil
+		}
+		if _, ok := err.(*find.NotFoundError); !ok {
+			return nil, err
+		}
+		vapp, vappErr := finder.VirtualApp(context.TODO(), resourcePool)
+		if vappErr != nil {
+			return nil, err
+		}
+		return vapp.ResourcePool, nil
+	case cluster != "":
+		return finder.ResourcePool(context.TODO(), "*"+cluster+"/Resources")
+	}
+	return finder.DefaultResourcePool(context.TODO())
+}
+
+// markVirtualMachineAsTemplate powers off a virtual machine if necessary, and
+// marks it as a template.
+func markVirtualMachineAsTemplate(vm *object.VirtualMachine) error {
+	state, err := vm.PowerState(context.TODO())
+	if err != nil {
+		return err
+	}
+	if state != types.VirtualMachinePowerStatePoweredOff {
+		log.Printf("[INFO] Shutting down virtual machine %s before marking it as a template", vm.InventoryPath)
+		task, err := vm.PowerOff(context.TODO())
+		if err != nil {
+			return err
+		}
+		if err := task.Wait(context.TODO()); err != nil {
+			return err
+		}
+	}
+	log.Printf("[INFO] Marking virtual machine %s as a template", vm.InventoryPath)
+	if err := vm.MarkAsTemplate(context.TODO()); err != nil {
+		return fmt.Errorf("error marking virtual machine as template: %s", err)
+	}
+	return nil
+}
+
+// virtualMachineDiskResource is the schema for an element of the disk set of a
+// virtual machine. It is built once, as it is needed every time a disk is
+// hashed.
+var virtualMachineDiskResource = &schema.Resource{
+	Schema: map[string]*schema.Schema{
+		"uuid": &schema.Schema{
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+
+		"key": &schema.Schema{
+			Type:     schema.TypeInt,
+			Computed: true,
+		},
+
+		"template": &schema.Schema{
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+
+		"type": &schema.Schema{
+			Type:     schema.TypeString,
+			Optional: true,
+			Default:  "eager_zeroed",
+			ValidateFunc: func(v interface{}, k string) (ws []string, errors []error) {
+				value := v.(string)
+				if value != "thin" && value != "eager_zeroed" && value != "lazy" {
+					errors = append(errors, fmt.Errorf(
+						"only 'thin', 'eager_zeroed', and 'lazy' are supported values for 'type'"))
+				}
+				return
+			},
+		},
+
+		"datastore": &schema.Schema{
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+
+		"size": &schema.Schema{
+			Type:     schema.TypeInt,
+			Optional: true,
+		},
+
+		"name": &schema.Schema{
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+
+		"iops": &schema.Schema{
+			Type:     schema.TypeInt,
+			Optional: true,
+		},
+
+		"vmdk": &schema.Schema{
+			// TODO: Add ValidateFunc to confirm path exists
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+
+		"bootable": &schema.Schema{
+			Type:     schema.TypeBool,
+			Optional: true,
+		},
+
+		"keep_on_remove": &schema.Schema{
+			Type:     schema.TypeBool,
+			Optional: true,
+		},
+
+		"storage_policy_id": &schema.Schema{
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+
+		"controller_type": &schema.Schema{
+			Type:     schema.TypeString,
+			Optional: true,
+			Default:  "scsi",
+			ValidateFunc: func(v interface{}, k string) (ws []string, errors []error) {
+				value := v.(string)
+				found := false
+				for _, t := range DiskControllerTypes {
+					if t == value {
+						found = true
+					}
+				}
+				if !found {
+					errors = append(errors, fmt.Errorf(
+						"Supported values for 'controller_type' are %v", strings.Join(DiskControllerTypes, ", ")))
+				}
+				return
+			},
+		},
+	},
+}
+
+// resourceVSphereVirtualMachineDiskHash computes the hash of an element in the
+// disk set. storage_policy_id is left out so that changing the policy of a
+// disk does not remove and re-add it.
+func resourceVSphereVirtualMachineDiskHash(v interface{}) int {
+	m := make(map[string]interface{})
+	for k, val := range v.(map[string]interface{}) {
+		if k != "storage_policy_id" {
+			m[k] = val
+		}
+	}
+	return schema.HashResource(virtualMachineDiskResource)(m)
+}
+
+// virtualMachineDeleteDetachesDisks returns true if deleting the virtual
+// machine requires any disks to be detached first, either because they are
+// marked keep_on_remove, or because detach_unknown_disks_on_delete is set.
+func virtualMachineDeleteDetachesDisks(d *schema.ResourceData) bool {
+	if d.Get("detach_unknown_disks_on_delete").(bool) {
+		return true
+	}
+	for _, v := range d.Get("disk").(*schema.Set).List() {
+		if keep, ok := v.(map[string]interface{})["keep_on_remove"].(bool); ok && keep {
+			return true
+		}
+	}
+	return false
+}
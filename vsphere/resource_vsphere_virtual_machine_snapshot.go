@@ -5,8 +5,11 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/vmware/govmomi/object"
 	"github.com/vmware/govmomi/vim25/types"
 )
 
@@ -14,7 +17,11 @@ func resourceVSphereVirtualMachineSnapshot() *schema.Resource {
 	return &schema.Resource{
 		Create: resourceVSphereVirtualMachineSnapshotCreate,
 		Read:   resourceVSphereVirtualMachineSnapshotRead,
+		Update: resourceVSphereVirtualMachineSnapshotUpdate,
 		Delete: resourceVSphereVirtualMachineSnapshotDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceVSphereVirtualMachineSnapshotImport,
+		},
 
 		Schema: map[string]*schema.Schema{
 			"virtual_machine_uuid": {
@@ -25,12 +32,10 @@ func resourceVSphereVirtualMachineSnapshot() *schema.Resource {
 			"snapshot_name": {
 				Type:     schema.TypeString,
 				Required: true,
-				ForceNew: true,
 			},
 			"description": {
 				Type:     schema.TypeString,
 				Required: true,
-				ForceNew: true,
 			},
 			"memory": {
 				Type:     schema.TypeBool,
@@ -52,11 +57,70 @@ func resourceVSphereVirtualMachineSnapshot() *schema.Resource {
 				Optional: true,
 				ForceNew: true,
 			},
+			"revert": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"rotate": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"retention": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"count": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							ValidateFunc: validation.IntAtLeast(1),
+						},
+						"max_age": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validateVirtualMachineSnapshotMaxAge,
+						},
+					},
+				},
+			},
+			"create_time": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"power_state": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"parent_snapshot_id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"retained_snapshot_ids": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			// The retained snapshots that have fallen outside of the retention
+			// policy, as of the last refresh. This is never set in configuration, so
+			// any snapshots listed here show up as a diff, and are pruned on the next
+			// apply, even if nothing else has changed.
+			"expired_snapshot_ids": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validateNotConfigurable,
+				},
+			},
 		},
 	}
 }
 
 func resourceVSphereVirtualMachineSnapshotCreate(d *schema.ResourceData, meta interface{}) error {
+	if err := validateVirtualMachineSnapshotRetention(d); err != nil {
+		return err
+	}
 	client := meta.(*VSphereClient).vimClient
 	vm, err := virtualMachineFromUUID(client, d.Get("virtual_machine_uuid").(string))
 	if err != nil {
@@ -80,7 +144,7 @@ func resourceVSphereVirtualMachineSnapshotCreate(d *schema.ResourceData, meta in
 	log.Printf("[DEBUG] Create Snapshot completed %v", d.Get("snapshot_name").(string))
 	log.Println("[DEBUG] Managed Object Reference: " + taskInfo.Result.(types.ManagedObjectReference).Value)
 	d.SetId(taskInfo.Result.(types.ManagedObjectReference).Value)
-	return nil
+	return resourceVSphereVirtualMachineSnapshotRead(d, meta)
 }
 
 func resourceVSphereVirtualMachineSnapshotDelete(d *schema.ResourceData, meta interface{}) error {
@@ -94,18 +158,17 @@ func resourceVSphereVirtualMachineSnapshotDelete(d *schema.ResourceData, meta in
 		log.Printf("[DEBUG] Error While finding the Snapshot: %v", err)
 		return nil
 	}
+	// Remove the older snapshots that this resource took when rotating first,
+	// so that everything this resource created is gone.
+	for _, id := range sliceInterfacesToStrings(d.Get("retained_snapshot_ids").([]interface{})) {
+		log.Printf("[DEBUG] Deleting retained snapshot %q", id)
+		if err := removeVirtualMachineSnapshot(vm, id, virtualMachineSnapshotConsolidate(d)); err != nil {
+			return fmt.Errorf("error deleting retained snapshot %q: %s", id, err)
+		}
+	}
 	log.Printf("[DEBUG] Deleting snapshot with name: %v", d.Get("snapshot_name").(string))
-	var consolidatePtr *bool
 	var removeChildren bool
-
-	if v, ok := d.GetOk("consolidate"); ok {
-		consolidate := v.(bool)
-		consolidatePtr = &consolidate
-	} else {
-
-		consolidate := true
-		consolidatePtr = &consolidate
-	}
+	consolidatePtr := virtualMachineSnapshotConsolidate(d)
 	if v, ok := d.GetOk("remove_children"); ok {
 		removeChildren = v.(bool)
 	} else {
@@ -136,18 +199,218 @@ func resourceVSphereVirtualMachineSnapshotRead(d *schema.ResourceData, meta inte
 	if err != nil {
 		return fmt.Errorf("Error while getting the VirtualMachine :%s", err)
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout) // This is 5 mins
+	tree, parent, err := virtualMachineSnapshotTreeFromID(vm, d.Id())
+	if err != nil {
+		return fmt.Errorf("Error while finding the Snapshot :%s", err)
+	}
+	if tree == nil {
+		log.Printf("[DEBUG] Snapshot %q not found on virtual machine %q", d.Id(), d.Get("virtual_machine_uuid").(string))
+		d.SetId("")
+		return nil
+	}
+	log.Printf("[DEBUG] Snapshot found: %v", tree.Snapshot)
+	d.Set("snapshot_name", tree.Name)
+	d.Set("description", tree.Description)
+	d.Set("create_time", tree.CreateTime.Format(time.RFC3339))
+	d.Set("power_state", tree.State)
+	d.Set("parent_snapshot_id", parent)
+
+	// Drop any retained snapshots that have since been removed outside of
+	// Terraform.
+	var retained []string
+	for _, id := range sliceInterfacesToStrings(d.Get("retained_snapshot_ids").([]interface{})) {
+		rt, _, err := virtualMachineSnapshotTreeFromID(vm, id)
+		if err != nil {
+			return fmt.Errorf("Error while finding the Snapshot :%s", err)
+		}
+		if rt != nil {
+			retained = append(retained, id)
+		}
+	}
+	if err := d.Set("retained_snapshot_ids", retained); err != nil {
+		return fmt.Errorf("error setting retained snapshot IDs: %s", err)
+	}
+
+	// Record the retained snapshots that have expired since the last apply, so
+	// that they are pruned on the next one.
+	var expired []string
+	if r := expandVirtualMachineSnapshotRetention(d); r != nil && len(retained) > 0 {
+		props, err := virtualMachineProperties(vm)
+		if err != nil {
+			return fmt.Errorf("error fetching virtual machine properties: %s", err)
+		}
+		if props.Snapshot != nil {
+			expired = r.snapshotsToPrune(props.Snapshot.RootSnapshotList, retained, time.Now())
+		}
+	}
+	if err := d.Set("expired_snapshot_ids", expired); err != nil {
+		return fmt.Errorf("error setting expired snapshot IDs: %s", err)
+	}
+	return nil
+}
+
+func resourceVSphereVirtualMachineSnapshotUpdate(d *schema.ResourceData, meta interface{}) error {
+	if err := validateVirtualMachineSnapshotRetention(d); err != nil {
+		return err
+	}
+	client := meta.(*VSphereClient).vimClient
+	if d.HasChange("snapshot_name") || d.HasChange("description") {
+		log.Printf("[DEBUG] Renaming snapshot %q to %q", d.Id(), d.Get("snapshot_name").(string))
+		if err := renameVirtualMachineSnapshot(client, d.Id(), d.Get("snapshot_name").(string), d.Get("description").(string)); err != nil {
+			return fmt.Errorf("error renaming snapshot: %s", err)
+		}
+	}
+	// Any change to a non-empty revert value reverts the virtual machine. Clearing
+	// the value does nothing.
+	if d.HasChange("revert") && d.Get("revert").(string) != "" {
+		log.Printf("[DEBUG] Reverting virtual machine %q to snapshot %q", d.Get("virtual_machine_uuid").(string), d.Id())
+		if err := revertToVirtualMachineSnapshot(client, d.Id()); err != nil {
+			return fmt.Errorf("error reverting to snapshot: %s", err)
+		}
+	}
+	vm, err := virtualMachineFromUUID(client, d.Get("virtual_machine_uuid").(string))
+	if err != nil {
+		return fmt.Errorf("error fetching virtual machine: %s", err)
+	}
+	// Any change to a non-empty rotate value takes a new snapshot, which becomes
+	// the snapshot managed by this resource. The previous snapshot is retained
+	// until it is pruned. This is the only way that a single resource takes
+	// more than one snapshot, and so the only source of snapshots for the
+	// retention policy to prune.
+	if d.HasChange("rotate") && d.Get("rotate").(string) != "" {
+		log.Printf("[DEBUG] Rotating snapshot %q on virtual machine %q", d.Id(), d.Get("virtual_machine_uuid").(string))
+		id, err := createVirtualMachineSnapshot(vm, d.Get("snapshot_name").(string), d.Get("description").(string), d.Get("memory").(bool), d.Get("quiesce").(bool))
+		if err != nil {
+			return fmt.Errorf("error rotating snapshot: %s", err)
+		}
+		retained := append(d.Get("retained_snapshot_ids").([]interface{}), d.Id())
+		d.SetId(id)
+		if err := d.Set("retained_snapshot_ids", retained); err != nil {
+			return fmt.Errorf("error setting retained snapshot IDs: %s", err)
+		}
+	}
+	// The retention policy is applied on every update, which includes the ones
+	// triggered by retained snapshots expiring.
+	if err := pruneVirtualMachineSnapshots(d, vm); err != nil {
+		return err
+	}
+	return resourceVSphereVirtualMachineSnapshotRead(d, meta)
+}
+
+func resourceVSphereVirtualMachineSnapshotImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	// Our subject is the virtual machine UUID and the snapshot name or managed
+	// object ID, separated by a slash. The snapshot name can be a path within
+	// the snapshot tree.
+	parts := strings.SplitN(d.Id(), "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("invalid import ID %q, must be in the format <vm uuid>/<snapshot name or id>", d.Id())
+	}
+	client := meta.(*VSphereClient).vimClient
+	vm, err := virtualMachineFromUUID(client, parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("error fetching virtual machine: %s", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
 	defer cancel()
-	snapshot, err := vm.FindSnapshot(ctx, d.Id())
+	ref, err := vm.FindSnapshot(ctx, parts[1])
 	if err != nil {
-		if strings.Contains(err.Error(), "No snapshots for this VM") || strings.Contains(err.Error(), "snapshot \""+d.Get("snapshot_name").(string)+"\" not found") {
-			log.Printf("[DEBUG] Error While finding the Snapshot: %v", err)
-			d.SetId("")
-			return nil
+		return nil, fmt.Errorf("error finding snapshot: %s", err)
+	}
+	tree, _, err := virtualMachineSnapshotTreeFromID(vm, ref.Reference().Value)
+	if err != nil {
+		return nil, err
+	}
+	if tree == nil {
+		return nil, fmt.Errorf("snapshot %q not found", parts[1])
+	}
+	d.SetId(tree.Snapshot.Value)
+	d.Set("virtual_machine_uuid", parts[0])
+	// These can't be read back exactly, so they are only set on import. A
+	// snapshot of a powered on virtual machine is only powered on if memory was
+	// included.
+	d.Set("memory", tree.State == types.VirtualMachinePowerStatePoweredOn)
+	d.Set("quiesce", tree.Quiesced)
+	return []*schema.ResourceData{d}, nil
+}
+
+// virtualMachineSnapshotConsolidate returns the value of consolidate, which
+// defaults to true.
+func virtualMachineSnapshotConsolidate(d *schema.ResourceData) *bool {
+	consolidate := true
+	if v, ok := d.GetOk("consolidate"); ok {
+		consolidate = v.(bool)
+	}
+	return &consolidate
+}
+
+// validateVirtualMachineSnapshotMaxAge checks that max_age in a retention
+// block is a valid duration.
+func validateVirtualMachineSnapshotMaxAge(v interface{}, k string) ([]string, []error) {
+	d, err := time.ParseDuration(v.(string))
+	if err != nil {
+		return nil, []error{fmt.Errorf("%s: %s", k, err)}
+	}
+	if d <= 0 {
+		return nil, []error{fmt.Errorf("%s must be a positive duration", k)}
+	}
+	return nil, nil
+}
+
+// validateVirtualMachineSnapshotRetention checks that a retention block, if
+// set, has at least one of count or max_age set. This is checked before any
+// snapshot is taken.
+func validateVirtualMachineSnapshotRetention(d *schema.ResourceData) error {
+	r := expandVirtualMachineSnapshotRetention(d)
+	if r != nil && r.count == 0 && r.maxAge == 0 {
+		return fmt.Errorf("retention must have at least one of count or max_age set")
+	}
+	return nil
+}
+
+// pruneVirtualMachineSnapshots removes the retained snapshots of this resource
+// that fall outside of its retention policy, and removes them from
+// retained_snapshot_ids. Nothing is done if retention is not set.
+func pruneVirtualMachineSnapshots(d *schema.ResourceData, vm *object.VirtualMachine) error {
+	r := expandVirtualMachineSnapshotRetention(d)
+	if r == nil {
+		return nil
+	}
+	props, err := virtualMachineProperties(vm)
+	if err != nil {
+		return fmt.Errorf("error fetching virtual machine properties: %s", err)
+	}
+	if props.Snapshot == nil {
+		return nil
+	}
+	retained := sliceInterfacesToStrings(d.Get("retained_snapshot_ids").([]interface{}))
+	pruned := make(map[string]bool)
+	for _, id := range r.snapshotsToPrune(props.Snapshot.RootSnapshotList, retained, time.Now()) {
+		log.Printf("[DEBUG] Pruning snapshot %q on virtual machine %q", id, d.Get("virtual_machine_uuid").(string))
+		if err := removeVirtualMachineSnapshot(vm, id, virtualMachineSnapshotConsolidate(d)); err != nil {
+			return fmt.Errorf("error pruning snapshot %q: %s", id, err)
+		}
+		pruned[id] = true
+	}
+	var remaining []string
+	for _, id := range retained {
+		if !pruned[id] {
+			remaining = append(remaining, id)
 		}
-		log.Printf("[DEBUG] Error While finding the Snapshot: %v", err)
-		return fmt.Errorf("Error while finding the Snapshot :%s", err)
 	}
-	log.Printf("[DEBUG] Snapshot found: %v", snapshot)
+	if err := d.Set("retained_snapshot_ids", remaining); err != nil {
+		return fmt.Errorf("error setting retained snapshot IDs: %s", err)
+	}
 	return nil
 }
+
+// removeVirtualMachineSnapshot removes a single snapshot, keeping its
+// children, and waits for the task to complete.
+func removeVirtualMachineSnapshot(vm *object.VirtualMachine, id string, consolidate *bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	task, err := vm.RemoveSnapshot(ctx, id, false, consolidate)
+	if err != nil {
+		return err
+	}
+	return task.Wait(ctx)
+}
@@ -11,6 +11,7 @@ import (
 )
 
 func TestAccResourceVSphereVirtualMachineSnapshot_Basic(t *testing.T) {
+	var snapshotID string
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -19,15 +20,50 @@ func TestAccResourceVSphereVirtualMachineSnapshot_Basic(t *testing.T) {
 		Providers: testAccProviders,
 		Steps: []resource.TestStep{
 			resource.TestStep{
-				Config: testAccResourceVSphereVirtualMachineSnapshotConfig(true),
+				Config: testAccResourceVSphereVirtualMachineSnapshotConfig(true, "Managed by Terraform", ""),
 				Check: resource.ComposeTestCheckFunc(
 					testAccCheckVirtualMachineSnapshotExists("vsphere_virtual_machine_snapshot.snapshot"),
 					resource.TestCheckResourceAttr(
 						"vsphere_virtual_machine_snapshot.snapshot", "snapshot_name", "terraform-test-snapshot"),
+					resource.TestCheckResourceAttr(
+						"vsphere_virtual_machine_snapshot.snapshot", "power_state", "poweredOn"),
+					resource.TestCheckResourceAttr(
+						"vsphere_virtual_machine_snapshot.snapshot", "parent_snapshot_id", ""),
+					testAccCheckVirtualMachineSnapshotID("vsphere_virtual_machine_snapshot.snapshot", &snapshotID),
+				),
+			},
+			resource.TestStep{
+				Config: testAccResourceVSphereVirtualMachineSnapshotConfig(true, "Managed by Terraform (updated)", ""),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"vsphere_virtual_machine_snapshot.snapshot", "description", "Managed by Terraform (updated)"),
+					testAccCheckVirtualMachineSnapshotID("vsphere_virtual_machine_snapshot.snapshot", &snapshotID),
+				),
+			},
+			resource.TestStep{
+				Config: testAccResourceVSphereVirtualMachineSnapshotConfig(true, "Managed by Terraform (updated)", "1"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVirtualMachineSnapshotID("vsphere_virtual_machine_snapshot.snapshot", &snapshotID),
+					resource.TestCheckResourceAttr(
+						"vsphere_virtual_machine.vm", "power_state", "poweredOn"),
 				),
 			},
 			resource.TestStep{
-				Config: testAccResourceVSphereVirtualMachineSnapshotConfig(false),
+				ResourceName:            "vsphere_virtual_machine_snapshot.snapshot",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"quiesce", "revert"},
+				ImportStateIdFunc: func(s *terraform.State) (string, error) {
+					rs, ok := s.RootModule().Resources["vsphere_virtual_machine_snapshot.snapshot"]
+					if !ok {
+						return "", fmt.Errorf("vsphere_virtual_machine_snapshot.snapshot not found in state")
+					}
+					return fmt.Sprintf("%s/%s", rs.Primary.Attributes["virtual_machine_uuid"], rs.Primary.Attributes["snapshot_name"]), nil
+				},
+				Config: testAccResourceVSphereVirtualMachineSnapshotConfig(true, "Managed by Terraform (updated)", "1"),
+			},
+			resource.TestStep{
+				Config: testAccResourceVSphereVirtualMachineSnapshotConfig(false, "Managed by Terraform", ""),
 				Check: resource.ComposeTestCheckFunc(
 					testAccCheckVirtualMachineHasNoSnapshots("vsphere_virtual_machine.vm"),
 				),
@@ -94,6 +130,26 @@ func testAccCheckVirtualMachineSnapshotExists(n string) resource.TestCheckFunc {
 	}
 }
 
+// testAccCheckVirtualMachineSnapshotID checks that the ID of a snapshot
+// matches the one saved by a previous step, saving it if none has been saved
+// yet. This ensures that the snapshot was updated in place.
+func testAccCheckVirtualMachineSnapshotID(n string, id *string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("Not found: %s", n)
+		}
+		if *id == "" {
+			*id = rs.Primary.ID
+			return nil
+		}
+		if rs.Primary.ID != *id {
+			return fmt.Errorf("expected snapshot ID to be %s, got %s", *id, rs.Primary.ID)
+		}
+		return nil
+	}
+}
+
 func testAccCheckVirtualMachineHasNoSnapshots(n string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		rs, ok := s.RootModule().Resources[n]
@@ -123,7 +179,7 @@ func testAccCheckVirtualMachineHasNoSnapshots(n string) resource.TestCheckFunc {
 	}
 }
 
-func testAccResourceVSphereVirtualMachineSnapshotConfig(enabled bool) string {
+func testAccResourceVSphereVirtualMachineSnapshotConfig(enabled bool, description, revert string) string {
 	return fmt.Sprintf(`
 variable "datacenter" {
   default = "%s"
@@ -165,6 +221,14 @@ variable "snapshot_enabled" {
   default = "%t"
 }
 
+variable "snapshot_description" {
+  default = "%s"
+}
+
+variable "snapshot_revert" {
+  default = "%s"
+}
+
 resource "vsphere_virtual_machine" "vm" {
   name          = "terraform-test"
   datacenter    = "${var.datacenter}"
@@ -194,9 +258,10 @@ resource "vsphere_virtual_machine_snapshot" "snapshot" {
   count                = "${var.snapshot_enabled == "true" ? 1 : 0 }"
   virtual_machine_uuid = "${vsphere_virtual_machine.vm.uuid}"
   snapshot_name        = "terraform-test-snapshot"
-  description          = "Managed by Terraform"
+  description          = "${var.snapshot_description}"
   memory               = true
   quiesce              = true
+  revert               = "${var.snapshot_revert}"
 }
 `,
 		os.Getenv("VSPHERE_DATACENTER"),
@@ -209,5 +274,7 @@ resource "vsphere_virtual_machine_snapshot" "snapshot" {
 		os.Getenv("VSPHERE_DATASTORE"),
 		os.Getenv("VSPHERE_TEMPLATE"),
 		enabled,
+		description,
+		revert,
 	)
 }
@@ -0,0 +1,538 @@
+package vsphere
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// hostVirtualNicDefaultNetStack is the key of the default TCP/IP stack on a
+// host.
+const hostVirtualNicDefaultNetStack = "defaultTcpipStack"
+
+var hostVirtualNicServiceAllowedValues = []string{
+	string(types.HostVirtualNicManagerNicTypeVmotion),
+	string(types.HostVirtualNicManagerNicTypeManagement),
+	string(types.HostVirtualNicManagerNicTypeVsan),
+	string(types.HostVirtualNicManagerNicTypeVsanWitness),
+	string(types.HostVirtualNicManagerNicTypeFaultToleranceLogging),
+	string(types.HostVirtualNicManagerNicTypeVSphereReplication),
+	string(types.HostVirtualNicManagerNicTypeVSphereReplicationNFC),
+	string(types.HostVirtualNicManagerNicTypeVSphereProvisioning),
+}
+
+func resourceVSphereVNIC() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereVNICCreate,
+		Read:   resourceVSphereVNICRead,
+		Update: resourceVSphereVNICUpdate,
+		Delete: resourceVSphereVNICDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceVSphereVNICImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"host_system_id": {
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the host to create the VMkernel adapter on.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"portgroup": {
+				Type:          schema.TypeString,
+				Description:   "The name of the standard port group to connect the VMkernel adapter to.",
+				Optional:      true,
+				ConflictsWith: []string{"distributed_switch_port", "distributed_port_group"},
+			},
+			"distributed_switch_port": {
+				Type:          schema.TypeString,
+				Description:   "The UUID of the distributed virtual switch to connect the VMkernel adapter to.",
+				Optional:      true,
+				ConflictsWith: []string{"portgroup"},
+			},
+			"distributed_port_group": {
+				Type:          schema.TypeString,
+				Description:   "The key of the distributed port group to connect the VMkernel adapter to.",
+				Optional:      true,
+				ConflictsWith: []string{"portgroup"},
+			},
+			"mtu": {
+				Type:         schema.TypeInt,
+				Description:  "The MTU of the VMkernel adapter.",
+				Optional:     true,
+				Default:      1500,
+				ValidateFunc: validation.IntBetween(1280, 9000),
+			},
+			"netstack": {
+				Type:        schema.TypeString,
+				Description: "The key of the TCP/IP stack to use, such as defaultTcpipStack, vmotion, vSphereProvisioning, or the key of a custom stack.",
+				Optional:    true,
+				Default:     hostVirtualNicDefaultNetStack,
+				ForceNew:    true,
+			},
+			"ipv4": {
+				Type:        schema.TypeList,
+				Description: "The IPv4 configuration of the VMkernel adapter.",
+				Optional:    true,
+				MaxItems:    1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"dhcp": {
+							Type:        schema.TypeBool,
+							Description: "Use DHCP to configure the IPv4 address.",
+							Optional:    true,
+							Default:     false,
+						},
+						"ip": {
+							Type:        schema.TypeString,
+							Description: "The static IPv4 address, when dhcp is false.",
+							Optional:    true,
+						},
+						"netmask": {
+							Type:        schema.TypeString,
+							Description: "The subnet mask of the static IPv4 address, when dhcp is false.",
+							Optional:    true,
+						},
+						"gw": {
+							Type:        schema.TypeString,
+							Description: "The IPv4 default gateway to use for this adapter, overriding the gateway of the TCP/IP stack.",
+							Optional:    true,
+						},
+					},
+				},
+			},
+			"ipv6": {
+				Type:        schema.TypeList,
+				Description: "The IPv6 configuration of the VMkernel adapter.",
+				Optional:    true,
+				MaxItems:    1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"dhcp": {
+							Type:        schema.TypeBool,
+							Description: "Use DHCPv6 to configure IPv6 addresses.",
+							Optional:    true,
+							Default:     false,
+						},
+						"autoconfig": {
+							Type:        schema.TypeBool,
+							Description: "Use router advertisements to configure IPv6 addresses.",
+							Optional:    true,
+							Default:     false,
+						},
+						"addresses": {
+							Type:        schema.TypeList,
+							Description: "The static IPv6 addresses, in CIDR notation.",
+							Optional:    true,
+							Elem: &schema.Schema{
+								Type:      schema.TypeString,
+								StateFunc: normalizeIPv6CIDR,
+							},
+						},
+						"gw": {
+							Type:        schema.TypeString,
+							Description: "The IPv6 default gateway to use for this adapter, overriding the gateway of the TCP/IP stack.",
+							Optional:    true,
+						},
+					},
+				},
+			},
+			"services": {
+				Type:        schema.TypeSet,
+				Description: "The services to enable on the VMkernel adapter, such as vmotion, management, vsan, or faultToleranceLogging.",
+				Optional:    true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringInSlice(hostVirtualNicServiceAllowedValues, false),
+				},
+			},
+			"mac": {
+				Type:        schema.TypeString,
+				Description: "The MAC address of the VMkernel adapter.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func resourceVSphereVNICCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	hsID := d.Get("host_system_id").(string)
+	ns, err := hostNetworkSystemFromHostSystemID(client, hsID)
+	if err != nil {
+		return fmt.Errorf("error loading host network system: %s", err)
+	}
+	spec, err := expandHostVirtualNicSpec(d)
+	if err != nil {
+		return err
+	}
+	spec.NetStackInstanceKey = d.Get("netstack").(string)
+
+	// When connecting to a standard port group, the port group is supplied as
+	// an argument to AddVirtualNic rather than in the spec.
+	portgroup := spec.Portgroup
+	spec.Portgroup = ""
+	device, err := addHostVirtualNic(ns, portgroup, *spec)
+	if err != nil {
+		return fmt.Errorf("error adding VMkernel adapter: %s", err)
+	}
+	d.SetId(fmt.Sprintf("%s:%s", hsID, device))
+
+	if err := resourceVSphereVNICApplyServices(d, meta, device); err != nil {
+		return err
+	}
+	return resourceVSphereVNICRead(d, meta)
+}
+
+func resourceVSphereVNICRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	hsID, device, err := splitHostVirtualNicID(d.Id())
+	if err != nil {
+		return err
+	}
+	ns, err := hostNetworkSystemFromHostSystemID(client, hsID)
+	if err != nil {
+		return fmt.Errorf("error loading host network system: %s", err)
+	}
+	nic, err := hostVirtualNicFromDevice(client, ns, device)
+	if err != nil {
+		return err
+	}
+	if nic == nil {
+		log.Printf("[DEBUG] VMkernel adapter %q not found on host %q, marking as gone", device, hsID)
+		d.SetId("")
+		return nil
+	}
+	d.Set("host_system_id", hsID)
+	if err := flattenHostVirtualNic(d, nic); err != nil {
+		return err
+	}
+
+	vnm, err := hostVirtualNicManagerFromHostSystemID(client, hsID)
+	if err != nil {
+		return fmt.Errorf("error loading host virtual NIC manager: %s", err)
+	}
+	services, err := hostVirtualNicServices(vnm, device)
+	if err != nil {
+		return fmt.Errorf("error fetching services for VMkernel adapter %q: %s", device, err)
+	}
+	if err := d.Set("services", services); err != nil {
+		return fmt.Errorf("error setting services: %s", err)
+	}
+	return nil
+}
+
+func resourceVSphereVNICUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	hsID, device, err := splitHostVirtualNicID(d.Id())
+	if err != nil {
+		return err
+	}
+	ns, err := hostNetworkSystemFromHostSystemID(client, hsID)
+	if err != nil {
+		return fmt.Errorf("error loading host network system: %s", err)
+	}
+	spec, err := expandHostVirtualNicSpec(d)
+	if err != nil {
+		return err
+	}
+	// The port group and distributed port are only used in an update to
+	// migrate the adapter, so only send them when they have changed.
+	if !d.HasChange("portgroup") && !d.HasChange("distributed_switch_port") && !d.HasChange("distributed_port_group") {
+		spec.Portgroup = ""
+		spec.DistributedVirtualPort = nil
+	}
+	if err := updateHostVirtualNic(ns, device, *spec); err != nil {
+		return fmt.Errorf("error updating VMkernel adapter %q: %s", device, err)
+	}
+
+	if err := resourceVSphereVNICApplyServices(d, meta, device); err != nil {
+		return err
+	}
+	return resourceVSphereVNICRead(d, meta)
+}
+
+func resourceVSphereVNICDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	hsID, device, err := splitHostVirtualNicID(d.Id())
+	if err != nil {
+		return err
+	}
+	ns, err := hostNetworkSystemFromHostSystemID(client, hsID)
+	if err != nil {
+		return fmt.Errorf("error loading host network system: %s", err)
+	}
+	if err := removeHostVirtualNic(ns, device); err != nil {
+		return fmt.Errorf("error removing VMkernel adapter %q: %s", device, err)
+	}
+	return nil
+}
+
+func resourceVSphereVNICImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	// We import with the same ID that the resource is saved with, which is the
+	// host system ID and the device name of the adapter, separated by a colon.
+	hsID, _, err := splitHostVirtualNicID(d.Id())
+	if err != nil {
+		return nil, err
+	}
+	d.Set("host_system_id", hsID)
+	return []*schema.ResourceData{d}, nil
+}
+
+// resourceVSphereVNICApplyServices enables and disables services on the
+// VMkernel adapter to match the services in the resource.
+func resourceVSphereVNICApplyServices(d *schema.ResourceData, meta interface{}, device string) error {
+	if !d.HasChange("services") {
+		return nil
+	}
+	client := meta.(*VSphereClient).vimClient
+	vnm, err := hostVirtualNicManagerFromHostSystemID(client, d.Get("host_system_id").(string))
+	if err != nil {
+		return fmt.Errorf("error loading host virtual NIC manager: %s", err)
+	}
+	o, n := d.GetChange("services")
+	oldServices := o.(*schema.Set)
+	newServices := n.(*schema.Set)
+	for _, v := range oldServices.Difference(newServices).List() {
+		if err := deselectHostVirtualNicService(vnm, device, v.(string)); err != nil {
+			return fmt.Errorf("error disabling service %q on VMkernel adapter %q: %s", v.(string), device, err)
+		}
+	}
+	for _, v := range newServices.Difference(oldServices).List() {
+		if err := selectHostVirtualNicService(vnm, device, v.(string)); err != nil {
+			return fmt.Errorf("error enabling service %q on VMkernel adapter %q: %s", v.(string), device, err)
+		}
+	}
+	return nil
+}
+
+// splitHostVirtualNicID splits the ID of a vsphere_vnic resource into the
+// host system ID and the device name of the adapter.
+func splitHostVirtualNicID(id string) (string, string, error) {
+	parts := strings.SplitN(id, ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid ID %q, expected HOST_SYSTEM_ID:DEVICE", id)
+	}
+	return parts[0], parts[1], nil
+}
+
+// expandHostVirtualNicSpec reads certain ResourceData keys and returns a
+// HostVirtualNicSpec.
+func expandHostVirtualNicSpec(d *schema.ResourceData) (*types.HostVirtualNicSpec, error) {
+	obj := &types.HostVirtualNicSpec{
+		Mtu: int32(d.Get("mtu").(int)),
+	}
+
+	portgroup := d.Get("portgroup").(string)
+	dvsUUID := d.Get("distributed_switch_port").(string)
+	dvpgKey := d.Get("distributed_port_group").(string)
+	switch {
+	case portgroup != "":
+		obj.Portgroup = portgroup
+	case dvsUUID != "" && dvpgKey != "":
+		obj.DistributedVirtualPort = &types.DistributedVirtualSwitchPortConnection{
+			SwitchUuid:   dvsUUID,
+			PortgroupKey: dvpgKey,
+		}
+	default:
+		return nil, errors.New("one of portgroup, or distributed_switch_port and distributed_port_group, must be set")
+	}
+
+	ip, route, err := expandHostVirtualNicIPConfig(d)
+	if err != nil {
+		return nil, err
+	}
+	obj.Ip = ip
+	if route != nil {
+		obj.IpRouteSpec = &types.HostVirtualNicIpRouteSpec{
+			IpRouteConfig: route,
+		}
+	}
+	return obj, nil
+}
+
+// expandHostVirtualNicIPConfig reads the ipv4 and ipv6 blocks in the resource
+// and returns a HostIpConfig, along with a HostIpRouteConfig if either block
+// has a gateway set.
+func expandHostVirtualNicIPConfig(d *schema.ResourceData) (*types.HostIpConfig, *types.HostIpRouteConfig, error) {
+	ipv4 := d.Get("ipv4").([]interface{})
+	ipv6 := d.Get("ipv6").([]interface{})
+	if len(ipv4) < 1 && len(ipv6) < 1 {
+		return nil, nil, errors.New("at least one of ipv4 or ipv6 must be set")
+	}
+
+	obj := &types.HostIpConfig{}
+	route := &types.HostIpRouteConfig{}
+	if len(ipv4) > 0 && ipv4[0] != nil {
+		v := ipv4[0].(map[string]interface{})
+		obj.Dhcp = v["dhcp"].(bool)
+		if obj.Dhcp {
+			if v["ip"].(string) != "" || v["netmask"].(string) != "" {
+				return nil, nil, errors.New("ipv4: ip and netmask cannot be set when dhcp is true")
+			}
+		} else {
+			if v["ip"].(string) == "" || v["netmask"].(string) == "" {
+				return nil, nil, errors.New("ipv4: ip and netmask must be set when dhcp is false")
+			}
+			obj.IpAddress = v["ip"].(string)
+			obj.SubnetMask = v["netmask"].(string)
+		}
+		route.DefaultGateway = v["gw"].(string)
+	}
+
+	// Static IPv6 addresses are added and removed individually, so we work out
+	// the changes from the old and new address lists.
+	o, n := d.GetChange("ipv6")
+	oldAddrs, err := expandHostVirtualNicIPv6Addresses(o.([]interface{}))
+	if err != nil {
+		return nil, nil, err
+	}
+	newAddrs, err := expandHostVirtualNicIPv6Addresses(n.([]interface{}))
+	if err != nil {
+		return nil, nil, err
+	}
+	v6 := &types.HostIpConfigIpV6AddressConfiguration{
+		AutoConfigurationEnabled: boolPtr(false),
+		DhcpV6Enabled:            boolPtr(false),
+	}
+	if len(ipv6) > 0 && ipv6[0] != nil {
+		v := ipv6[0].(map[string]interface{})
+		v6.DhcpV6Enabled = boolPtr(v["dhcp"].(bool))
+		v6.AutoConfigurationEnabled = boolPtr(v["autoconfig"].(bool))
+		route.IpV6DefaultGateway = v["gw"].(string)
+	}
+	for k, addr := range newAddrs {
+		if _, ok := oldAddrs[k]; ok {
+			continue
+		}
+		addr.Operation = string(types.HostConfigChangeOperationAdd)
+		v6.IpV6Address = append(v6.IpV6Address, addr)
+	}
+	for k, addr := range oldAddrs {
+		if _, ok := newAddrs[k]; ok {
+			continue
+		}
+		addr.Operation = string(types.HostConfigChangeOperationRemove)
+		v6.IpV6Address = append(v6.IpV6Address, addr)
+	}
+	obj.IpV6Config = v6
+
+	if route.DefaultGateway == "" && route.IpV6DefaultGateway == "" {
+		route = nil
+	}
+	return obj, route, nil
+}
+
+// expandHostVirtualNicIPv6Addresses parses the static IPv6 addresses in an
+// ipv6 block, and returns them keyed by their string form.
+func expandHostVirtualNicIPv6Addresses(l []interface{}) (map[string]types.HostIpConfigIpV6Address, error) {
+	addrs := make(map[string]types.HostIpConfigIpV6Address)
+	if len(l) < 1 || l[0] == nil {
+		return addrs, nil
+	}
+	for _, v := range l[0].(map[string]interface{})["addresses"].([]interface{}) {
+		ip, ipNet, err := net.ParseCIDR(v.(string))
+		if err != nil {
+			return nil, fmt.Errorf("ipv6: invalid address %q: %s", v.(string), err)
+		}
+		prefix, _ := ipNet.Mask.Size()
+		// Addresses are keyed in the same form that they are read back in, so
+		// that the same address written differently is not removed and added.
+		addrs[ip.String()+"/"+strconv.Itoa(prefix)] = types.HostIpConfigIpV6Address{
+			IpAddress:    ip.String(),
+			PrefixLength: int32(prefix),
+		}
+	}
+	return addrs, nil
+}
+
+// normalizeIPv6CIDR is a SchemaStateFunc that normalizes an IPv6 address in
+// CIDR notation to the form that it is read back from the host in. Invalid
+// addresses are returned as is.
+func normalizeIPv6CIDR(v interface{}) string {
+	ip, ipNet, err := net.ParseCIDR(v.(string))
+	if err != nil {
+		return v.(string)
+	}
+	prefix, _ := ipNet.Mask.Size()
+	return ip.String() + "/" + strconv.Itoa(prefix)
+}
+
+// flattenHostVirtualNic reads various fields from a HostVirtualNic into the
+// passed in ResourceData.
+//
+// Addresses that were assigned through DHCP or autoconfiguration are not
+// read, as they are not managed by the resource.
+func flattenHostVirtualNic(d *schema.ResourceData, obj *types.HostVirtualNic) error {
+	d.Set("portgroup", obj.Portgroup)
+	if obj.Spec.DistributedVirtualPort != nil {
+		d.Set("distributed_switch_port", obj.Spec.DistributedVirtualPort.SwitchUuid)
+		d.Set("distributed_port_group", obj.Spec.DistributedVirtualPort.PortgroupKey)
+	} else {
+		d.Set("distributed_switch_port", "")
+		d.Set("distributed_port_group", "")
+	}
+	d.Set("mtu", obj.Spec.Mtu)
+	d.Set("mac", obj.Spec.Mac)
+	netstack := obj.Spec.NetStackInstanceKey
+	if netstack == "" {
+		netstack = hostVirtualNicDefaultNetStack
+	}
+	d.Set("netstack", netstack)
+
+	var route *types.HostIpRouteConfig
+	if obj.Spec.IpRouteSpec != nil && obj.Spec.IpRouteSpec.IpRouteConfig != nil {
+		route = obj.Spec.IpRouteSpec.IpRouteConfig.GetHostIpRouteConfig()
+	}
+	if route == nil {
+		route = &types.HostIpRouteConfig{}
+	}
+
+	var ipv4, ipv6 []interface{}
+	if ip := obj.Spec.Ip; ip != nil {
+		if ip.Dhcp || ip.IpAddress != "" {
+			v := map[string]interface{}{
+				"dhcp": ip.Dhcp,
+				"gw":   route.DefaultGateway,
+			}
+			if !ip.Dhcp {
+				v["ip"] = ip.IpAddress
+				v["netmask"] = ip.SubnetMask
+			}
+			ipv4 = append(ipv4, v)
+		}
+		if v6 := ip.IpV6Config; v6 != nil {
+			var addrs []string
+			for _, addr := range v6.IpV6Address {
+				if addr.Origin != "" && addr.Origin != string(types.HostIpConfigIpV6AddressConfigTypeManual) {
+					continue
+				}
+				addrs = append(addrs, addr.IpAddress+"/"+strconv.Itoa(int(addr.PrefixLength)))
+			}
+			dhcp := v6.DhcpV6Enabled != nil && *v6.DhcpV6Enabled
+			autoconfig := v6.AutoConfigurationEnabled != nil && *v6.AutoConfigurationEnabled
+			if dhcp || autoconfig || len(addrs) > 0 {
+				ipv6 = append(ipv6, map[string]interface{}{
+					"dhcp":       dhcp,
+					"autoconfig": autoconfig,
+					"addresses":  addrs,
+					"gw":         route.IpV6DefaultGateway,
+				})
+			}
+		}
+	}
+	if err := d.Set("ipv4", ipv4); err != nil {
+		return fmt.Errorf("error setting ipv4: %s", err)
+	}
+	if err := d.Set("ipv6", ipv6); err != nil {
+		return fmt.Errorf("error setting ipv6: %s", err)
+	}
+	return nil
+}
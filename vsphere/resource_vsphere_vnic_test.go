@@ -0,0 +1,289 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccResourceVSphereVNIC(t *testing.T) {
+	var tp *testing.T
+	testAccResourceVSphereVNICCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"basic",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereHostPortGroupPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVNICExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVNICConfigIPv4("192.0.2.10", 1500, `"vmotion"`),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVNICExists(true),
+							testAccResourceVSphereVNICCheckIPv4("192.0.2.10"),
+							testAccResourceVSphereVNICCheckServices([]string{"vmotion"}),
+						),
+					},
+				},
+			},
+		},
+		{
+			"update",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereHostPortGroupPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVNICExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVNICConfigIPv4("192.0.2.10", 1500, `"vmotion"`),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVNICExists(true),
+							testAccResourceVSphereVNICCheckIPv4("192.0.2.10"),
+							testAccResourceVSphereVNICCheckServices([]string{"vmotion"}),
+						),
+					},
+					{
+						Config: testAccResourceVSphereVNICConfigIPv4("192.0.2.11", 9000, `"faultToleranceLogging", "vSphereReplication"`),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVNICExists(true),
+							testAccResourceVSphereVNICCheckIPv4("192.0.2.11"),
+							testAccResourceVSphereVNICCheckServices([]string{"faultToleranceLogging", "vSphereReplication"}),
+							resource.TestCheckResourceAttr("vsphere_vnic.vnic", "mtu", "9000"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"ipv6",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereHostPortGroupPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVNICExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVNICConfigIPv6("2001:db8::10/64"),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVNICExists(true),
+							resource.TestCheckResourceAttr("vsphere_vnic.vnic", "ipv6.0.addresses.0", "2001:db8::10/64"),
+						),
+					},
+					{
+						Config: testAccResourceVSphereVNICConfigIPv6("2001:db8::11/64"),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVNICExists(true),
+							resource.TestCheckResourceAttr("vsphere_vnic.vnic", "ipv6.0.addresses.#", "1"),
+							resource.TestCheckResourceAttr("vsphere_vnic.vnic", "ipv6.0.addresses.0", "2001:db8::11/64"),
+						),
+					},
+					{
+						Config: testAccResourceVSphereVNICConfigIPv6("2001:0DB8:0000::0011/64"),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVNICExists(true),
+							resource.TestCheckResourceAttr("vsphere_vnic.vnic", "ipv6.0.addresses.#", "1"),
+							resource.TestCheckResourceAttr("vsphere_vnic.vnic", "ipv6.0.addresses.0", "2001:db8::11/64"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"import",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereHostPortGroupPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVNICExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVNICConfigIPv4("192.0.2.10", 1500, `"vmotion"`),
+					},
+					{
+						ResourceName:      "vsphere_vnic.vnic",
+						ImportState:       true,
+						ImportStateVerify: true,
+						Config:            testAccResourceVSphereVNICConfigIPv4("192.0.2.10", 1500, `"vmotion"`),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccResourceVSphereVNICCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+func testAccResourceVSphereVNICExists(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		nic, err := testGetVNIC(s, "vnic")
+		if err != nil {
+			return err
+		}
+		switch {
+		case nic == nil && expected:
+			return fmt.Errorf("VMkernel adapter not found")
+		case nic != nil && !expected:
+			return fmt.Errorf("expected VMkernel adapter %q to be missing", nic.Device)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereVNICCheckIPv4(expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		nic, err := testGetVNIC(s, "vnic")
+		if err != nil {
+			return err
+		}
+		if nic == nil {
+			return fmt.Errorf("VMkernel adapter not found")
+		}
+		actual := nic.Spec.Ip.IpAddress
+		if expected != actual {
+			return fmt.Errorf("expected IPv4 address to be %q, got %q", expected, actual)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereVNICCheckServices(expected []string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		vars, err := testClientVariablesForResource(s, "vsphere_vnic.vnic")
+		if err != nil {
+			return err
+		}
+		hsID, device, err := splitHostVirtualNicID(vars.resourceID)
+		if err != nil {
+			return err
+		}
+		vnm, err := hostVirtualNicManagerFromHostSystemID(vars.client, hsID)
+		if err != nil {
+			return err
+		}
+		actual, err := hostVirtualNicServices(vnm, device)
+		if err != nil {
+			return err
+		}
+		sort.Strings(expected)
+		sort.Strings(actual)
+		if !reflect.DeepEqual(expected, actual) {
+			return fmt.Errorf("expected services to be %v, got %v", expected, actual)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereVNICConfigBase() string {
+	return fmt.Sprintf(`
+variable "host_nic0" {
+  default = "%s"
+}
+
+variable "host_nic1" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "datacenter" {
+  name = "%s"
+}
+
+data "vsphere_host" "esxi_host" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.datacenter.id}"
+}
+
+resource "vsphere_host_virtual_switch" "switch" {
+  name           = "vSwitchTerraformTest"
+  host_system_id = "${data.vsphere_host.esxi_host.id}"
+
+  network_adapters = ["${var.host_nic0}", "${var.host_nic1}"]
+  active_nics      = ["${var.host_nic0}", "${var.host_nic1}"]
+  standby_nics     = []
+  mtu              = 9000
+}
+
+resource "vsphere_host_port_group" "pg" {
+  name                = "PGTerraformTest"
+  host_system_id      = "${data.vsphere_host.esxi_host.id}"
+  virtual_switch_name = "${vsphere_host_virtual_switch.switch.name}"
+}
+`, os.Getenv("VSPHERE_HOST_NIC0"), os.Getenv("VSPHERE_HOST_NIC1"), os.Getenv("VSPHERE_DATACENTER"), os.Getenv("VSPHERE_ESXI_HOST"))
+}
+
+func testAccResourceVSphereVNICConfigIPv4(ip string, mtu int, services string) string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_vnic" "vnic" {
+  host_system_id = "${data.vsphere_host.esxi_host.id}"
+  portgroup      = "${vsphere_host_port_group.pg.name}"
+  mtu            = %d
+  services       = [%s]
+
+  ipv4 {
+    ip      = "%s"
+    netmask = "255.255.255.0"
+  }
+}
+`,
+		testAccResourceVSphereVNICConfigBase(),
+		mtu,
+		services,
+		ip,
+	)
+}
+
+func testAccResourceVSphereVNICConfigIPv6(address string) string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_vnic" "vnic" {
+  host_system_id = "${data.vsphere_host.esxi_host.id}"
+  portgroup      = "${vsphere_host_port_group.pg.name}"
+
+  ipv6 {
+    addresses = ["%s"]
+  }
+}
+`,
+		testAccResourceVSphereVNICConfigBase(),
+		address,
+	)
+}
+
+func TestNormalizeIPv6CIDR(t *testing.T) {
+	cases := map[string]string{
+		"2001:db8::10/64":         "2001:db8::10/64",
+		"2001:0DB8:0000::0010/64": "2001:db8::10/64",
+		"2001:db8:0:0:0:0:0:1/48": "2001:db8::1/48",
+		"not-an-address":          "not-an-address",
+	}
+	for in, expected := range cases {
+		if actual := normalizeIPv6CIDR(in); actual != expected {
+			t.Fatalf("expected %q to normalize to %q, got %q", in, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package vsphere
+
+import (
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// schemaStorageDrsPodConfigSpec returns schema items for resources that need
+// to work with a StorageDrsPodConfigSpec.
+func schemaStorageDrsPodConfigSpec() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		// StorageDrsPodConfigSpec
+		// Skipped attributes: automationOverrides, rule, option
+		"sdrs_enabled": {
+			Type:        schema.TypeBool,
+			Description: "Enable Storage DRS for this datastore cluster.",
+			Optional:    true,
+			Default:     true,
+		},
+		"sdrs_automation_level": {
+			Type:        schema.TypeString,
+			Description: "The default automation level for all virtual machines in this datastore cluster. Can be one of manual or automated.",
+			Optional:    true,
+			Default:     string(types.StorageDrsPodConfigInfoBehaviorManual),
+			ValidateFunc: validation.StringInSlice(
+				[]string{
+					string(types.StorageDrsPodConfigInfoBehaviorManual),
+					string(types.StorageDrsPodConfigInfoBehaviorAutomated),
+				},
+				false,
+			),
+		},
+		"sdrs_io_load_balance_enabled": {
+			Type:        schema.TypeBool,
+			Description: "Enable I/O load balancing for this datastore cluster.",
+			Optional:    true,
+			Default:     true,
+		},
+		"sdrs_default_intra_vm_affinity": {
+			Type:        schema.TypeBool,
+			Description: "When true, storage DRS keeps the disks of a virtual machine together on the same datastore by default.",
+			Optional:    true,
+			Default:     true,
+		},
+		"sdrs_load_balance_interval": {
+			Type:         schema.TypeInt,
+			Description:  "The storage DRS load balancing interval, in minutes.",
+			Optional:     true,
+			Default:      480,
+			ValidateFunc: validation.IntBetween(60, 43200),
+		},
+		// StorageDrsSpaceLoadBalanceConfig
+		"sdrs_free_space_threshold_type": {
+			Type:        schema.TypeString,
+			Description: "The type of threshold used to trigger space load balancing. Can be one of utilization or freeSpace.",
+			Optional:    true,
+			Default:     string(types.StorageDrsSpaceLoadBalanceConfigSpaceThresholdModeUtilization),
+			ValidateFunc: validation.StringInSlice(
+				[]string{
+					string(types.StorageDrsSpaceLoadBalanceConfigSpaceThresholdModeUtilization),
+					string(types.StorageDrsSpaceLoadBalanceConfigSpaceThresholdModeFreeSpace),
+				},
+				false,
+			),
+		},
+		"sdrs_space_utilization_threshold": {
+			Type:         schema.TypeInt,
+			Description:  "The space utilization percentage of a datastore above which storage DRS moves virtual machines off it. Used when sdrs_free_space_threshold_type is utilization.",
+			Optional:     true,
+			Default:      80,
+			ValidateFunc: validation.IntBetween(50, 100),
+		},
+		"sdrs_free_space_threshold": {
+			Type:         schema.TypeInt,
+			Description:  "The free space, in GB, below which storage DRS moves virtual machines off a datastore. Used when sdrs_free_space_threshold_type is freeSpace.",
+			Optional:     true,
+			Default:      50,
+			ValidateFunc: validation.IntAtLeast(1),
+		},
+		"sdrs_free_space_utilization_difference": {
+			Type:         schema.TypeInt,
+			Description:  "The minimum difference in space utilization percentage between the source and destination datastores for storage DRS to recommend a move.",
+			Optional:     true,
+			Default:      5,
+			ValidateFunc: validation.IntBetween(1, 50),
+		},
+		// StorageDrsIoLoadBalanceConfig
+		"sdrs_io_latency_threshold": {
+			Type:         schema.TypeInt,
+			Description:  "The I/O latency, in milliseconds, above which storage DRS moves virtual machines off a datastore.",
+			Optional:     true,
+			Default:      15,
+			ValidateFunc: validation.IntBetween(5, 100),
+		},
+		"sdrs_io_load_imbalance_threshold": {
+			Type:         schema.TypeInt,
+			Description:  "The I/O load imbalance between datastores above which storage DRS recommends moves. A lower value is more aggressive.",
+			Optional:     true,
+			Default:      5,
+			ValidateFunc: validation.IntBetween(1, 100),
+		},
+	}
+}
+
+// expandStorageDrsPodConfigSpec reads certain ResourceData keys and returns a
+// StorageDrsPodConfigSpec.
+func expandStorageDrsPodConfigSpec(d *schema.ResourceData) *types.StorageDrsPodConfigSpec {
+	obj := &types.StorageDrsPodConfigSpec{
+		Enabled:                boolPtr(d.Get("sdrs_enabled").(bool)),
+		DefaultVmBehavior:      d.Get("sdrs_automation_level").(string),
+		IoLoadBalanceEnabled:   boolPtr(d.Get("sdrs_io_load_balance_enabled").(bool)),
+		DefaultIntraVmAffinity: boolPtr(d.Get("sdrs_default_intra_vm_affinity").(bool)),
+		LoadBalanceInterval:    int32(d.Get("sdrs_load_balance_interval").(int)),
+		SpaceLoadBalanceConfig: &types.StorageDrsSpaceLoadBalanceConfig{
+			SpaceThresholdMode:            d.Get("sdrs_free_space_threshold_type").(string),
+			SpaceUtilizationThreshold:     int32(d.Get("sdrs_space_utilization_threshold").(int)),
+			FreeSpaceThresholdGB:          int32(d.Get("sdrs_free_space_threshold").(int)),
+			MinSpaceUtilizationDifference: int32(d.Get("sdrs_free_space_utilization_difference").(int)),
+		},
+		IoLoadBalanceConfig: &types.StorageDrsIoLoadBalanceConfig{
+			IoLatencyThreshold:       int32(d.Get("sdrs_io_latency_threshold").(int)),
+			IoLoadImbalanceThreshold: int32(d.Get("sdrs_io_load_imbalance_threshold").(int)),
+		},
+	}
+	return obj
+}
+
+// flattenStorageDrsPodConfigInfo reads various fields from a
+// StorageDrsPodConfigInfo into the passed in ResourceData.
+func flattenStorageDrsPodConfigInfo(d *schema.ResourceData, obj types.StorageDrsPodConfigInfo) error {
+	d.Set("sdrs_enabled", obj.Enabled)
+	d.Set("sdrs_automation_level", obj.DefaultVmBehavior)
+	d.Set("sdrs_io_load_balance_enabled", obj.IoLoadBalanceEnabled)
+	d.Set("sdrs_load_balance_interval", obj.LoadBalanceInterval)
+	if err := setBoolPtr(d, "sdrs_default_intra_vm_affinity", obj.DefaultIntraVmAffinity); err != nil {
+		return err
+	}
+
+	if c := obj.SpaceLoadBalanceConfig; c != nil {
+		d.Set("sdrs_free_space_threshold_type", c.SpaceThresholdMode)
+		d.Set("sdrs_space_utilization_threshold", c.SpaceUtilizationThreshold)
+		d.Set("sdrs_free_space_threshold", c.FreeSpaceThresholdGB)
+		d.Set("sdrs_free_space_utilization_difference", c.MinSpaceUtilizationDifference)
+	}
+
+	if c := obj.IoLoadBalanceConfig; c != nil {
+		d.Set("sdrs_io_latency_threshold", c.IoLatencyThreshold)
+		d.Set("sdrs_io_load_imbalance_threshold", c.IoLoadImbalanceThreshold)
+	}
+
+	return nil
+}
@@ -0,0 +1,131 @@
+package vsphere
+
+import (
+	"context"
+	"path"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// storagePodFromID locates a StoragePod (datastore cluster) by its managed
+// object reference ID.
+func storagePodFromID(client *govmomi.Client, id string) (*object.StoragePod, error) {
+	finder := find.NewFinder(client.Client, false)
+
+	ref := types.ManagedObjectReference{
+		Type:  "StoragePod",
+		Value: id,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	pod, err := finder.ObjectReference(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	return pod.(*object.StoragePod), nil
+}
+
+// storagePodFromPath loads a StoragePod from its path.
+//
+// Datacenter is optional here - if not provided, it's expected that the path
+// is sufficient enough for finder to determine the datacenter required.
+func storagePodFromPath(client *govmomi.Client, name string, dc *object.Datacenter) (*object.StoragePod, error) {
+	finder := find.NewFinder(client.Client, false)
+	if dc != nil {
+		finder.SetDatacenter(dc)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return finder.DatastoreCluster(ctx, name)
+}
+
+// storagePodProperties is a convenience method that wraps fetching the
+// StoragePod MO from its higher-level object.
+func storagePodProperties(pod *object.StoragePod) (*mo.StoragePod, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	var props mo.StoragePod
+	if err := pod.Properties(ctx, pod.Reference(), nil, &props); err != nil {
+		return nil, err
+	}
+	return &props, nil
+}
+
+// createStoragePod creates a StoragePod in the supplied datastore folder.
+func createStoragePod(folder *object.Folder, name string) (*object.StoragePod, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return folder.CreateStoragePod(ctx, name)
+}
+
+// moveDatastoresToFolder moves the datastores with the supplied IDs into a
+// folder. The folder can be either a datastore folder or a StoragePod.
+func moveDatastoresToFolder(folder *object.Folder, ids []string) error {
+	if len(ids) < 1 {
+		return nil
+	}
+	var refs []types.ManagedObjectReference
+	for _, id := range ids {
+		refs = append(refs, types.ManagedObjectReference{Type: "Datastore", Value: id})
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	task, err := folder.MoveInto(ctx, refs)
+	if err != nil {
+		return err
+	}
+	tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer tcancel()
+	return task.Wait(tctx)
+}
+
+// moveDatastoresOutOfStoragePod moves the datastores with the supplied IDs
+// out of a StoragePod and into the datastore folder that the StoragePod is in.
+func moveDatastoresOutOfStoragePod(client *govmomi.Client, pod *object.StoragePod, ids []string) error {
+	folder, err := folderFromAbsolutePath(client, path.Dir(pod.InventoryPath))
+	if err != nil {
+		return err
+	}
+	return moveDatastoresToFolder(folder, ids)
+}
+
+// applyStorageDrsConfig applies a Storage DRS configuration to a StoragePod.
+// The configuration is merged into the existing configuration, so any unset
+// values in the spec are left as they are.
+func applyStorageDrsConfig(client *govmomi.Client, pod *object.StoragePod, spec types.StorageDrsConfigSpec) error {
+	srm := object.NewStorageResourceManager(client.Client)
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	task, err := srm.ConfigureStorageDrsForPod(ctx, pod, spec, true)
+	if err != nil {
+		return err
+	}
+	tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer tcancel()
+	return task.Wait(tctx)
+}
+
+// storageDrsVMConfig returns the Storage DRS configuration for a virtual
+// machine in a StoragePod. nil is returned if the StoragePod has no
+// configuration for the virtual machine.
+func storageDrsVMConfig(pod *object.StoragePod, vm *object.VirtualMachine) (*types.StorageDrsVmConfigInfo, error) {
+	props, err := storagePodProperties(pod)
+	if err != nil {
+		return nil, err
+	}
+	if props.PodStorageDrsEntry == nil {
+		return nil, nil
+	}
+	for _, info := range props.PodStorageDrsEntry.StorageDrsConfig.VmConfig {
+		if info.Vm != nil && info.Vm.Value == vm.Reference().Value {
+			return &info, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,127 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/pbm"
+	"github.com/vmware/govmomi/pbm/methods"
+	pbmtypes "github.com/vmware/govmomi/pbm/types"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// storagePolicyByName locates a storage (requirement) policy by its name.
+func storagePolicyByName(client *pbm.Client, name string) (*pbmtypes.PbmProfile, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	rtype := pbmtypes.PbmProfileResourceType{
+		ResourceType: string(pbmtypes.PbmProfileResourceTypeEnumSTORAGE),
+	}
+	ids, err := client.QueryProfile(ctx, rtype, string(pbmtypes.PbmProfileCategoryEnumREQUIREMENT))
+	if err != nil {
+		return nil, fmt.Errorf("error querying storage policies: %s", err)
+	}
+	if len(ids) < 1 {
+		return nil, fmt.Errorf("no storage policies found")
+	}
+	profiles, err := client.RetrieveContent(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving storage policies: %s", err)
+	}
+	for _, p := range profiles {
+		profile := p.GetPbmProfile()
+		if profile.Name == name {
+			return profile, nil
+		}
+	}
+	return nil, fmt.Errorf("could not find storage policy with name %q", name)
+}
+
+// virtualMachineStoragePolicyID returns the ID of the storage policy
+// associated with the home (configuration files) of a virtual machine. An
+// empty string is returned if the VM home has no policy.
+func virtualMachineStoragePolicyID(client *pbm.Client, vm *object.VirtualMachine) (string, error) {
+	return storagePolicyIDForEntity(client, pbmtypes.PbmServerObjectRef{
+		ObjectType: string(pbmtypes.PbmObjectTypeVirtualMachine),
+		Key:        vm.Reference().Value,
+	})
+}
+
+// virtualDiskStoragePolicyID returns the ID of the storage policy associated
+// with the virtual disk with the supplied device key on a virtual machine. An
+// empty string is returned if the disk has no policy.
+func virtualDiskStoragePolicyID(client *pbm.Client, vm *object.VirtualMachine, key int32) (string, error) {
+	return storagePolicyIDForEntity(client, pbmtypes.PbmServerObjectRef{
+		ObjectType: string(pbmtypes.PbmObjectTypeVirtualDiskId),
+		Key:        fmt.Sprintf("%s:%d", vm.Reference().Value, key),
+	})
+}
+
+// storagePolicyIDForEntity queries the profile associated with a SPBM server
+// object. The first profile returned is used.
+func storagePolicyIDForEntity(client *pbm.Client, entity pbmtypes.PbmServerObjectRef) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	req := pbmtypes.PbmQueryAssociatedProfile{
+		This:   client.ServiceContent.ProfileManager,
+		Entity: entity,
+	}
+	res, err := methods.PbmQueryAssociatedProfile(ctx, client, &req)
+	if err != nil {
+		return "", fmt.Errorf("error querying storage policy for %s %q: %s", entity.ObjectType, entity.Key, err)
+	}
+	if len(res.Returnval) < 1 {
+		return "", nil
+	}
+	return res.Returnval[0].UniqueId, nil
+}
+
+// expandVirtualMachineProfileSpec returns the profile spec used to apply a
+// storage policy to a virtual machine home or virtual disk. An empty ID
+// removes any existing policy.
+func expandVirtualMachineProfileSpec(id string) []types.BaseVirtualMachineProfileSpec {
+	if id == "" {
+		return []types.BaseVirtualMachineProfileSpec{&types.VirtualMachineEmptyProfileSpec{}}
+	}
+	return []types.BaseVirtualMachineProfileSpec{
+		&types.VirtualMachineDefinedProfileSpec{
+			ProfileId: id,
+		},
+	}
+}
+
+// expandVirtualMachineDiskStoragePolicyChanges returns device edit specs for
+// disks present in both the old and new disk sets whose storage policy has
+// changed.
+func expandVirtualMachineDiskStoragePolicyChanges(vm *object.VirtualMachine, o, n *schema.Set) ([]types.BaseVirtualDeviceConfigSpec, error) {
+	var spec []types.BaseVirtualDeviceConfigSpec
+	var devices object.VirtualDeviceList
+	for _, nv := range n.List() {
+		newDisk := nv.(map[string]interface{})
+		for _, ov := range o.List() {
+			oldDisk := ov.(map[string]interface{})
+			if o.F(ov) != n.F(nv) || oldDisk["storage_policy_id"] == newDisk["storage_policy_id"] {
+				continue
+			}
+			if devices == nil {
+				var err error
+				if devices, err = vm.Device(context.TODO()); err != nil {
+					return nil, fmt.Errorf("error loading virtual device list: %s", err)
+				}
+			}
+			key := int32(oldDisk["key"].(int))
+			device := devices.FindByKey(key)
+			if device == nil {
+				return nil, fmt.Errorf("could not find disk with key %d", key)
+			}
+			spec = append(spec, &types.VirtualDeviceConfigSpec{
+				Operation: types.VirtualDeviceConfigSpecOperationEdit,
+				Device:    device,
+				Profile:   expandVirtualMachineProfileSpec(newDisk["storage_policy_id"].(string)),
+			})
+		}
+	}
+	return spec, nil
+}
@@ -0,0 +1,70 @@
+package vsphere
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func testVirtualMachineDisk(size int, policy string) map[string]interface{} {
+	return map[string]interface{}{
+		"key":               2000,
+		"uuid":              "",
+		"template":          "",
+		"type":              "thin",
+		"datastore":         "datastore1",
+		"size":              size,
+		"name":              "disk1",
+		"iops":              0,
+		"vmdk":              "",
+		"bootable":          false,
+		"keep_on_remove":    false,
+		"controller_type":   "scsi",
+		"storage_policy_id": policy,
+	}
+}
+
+func TestResourceVSphereVirtualMachineDiskHash(t *testing.T) {
+	cases := []struct {
+		Name  string
+		a     map[string]interface{}
+		b     map[string]interface{}
+		equal bool
+	}{
+		{
+			Name:  "storage policy ignored",
+			a:     testVirtualMachineDisk(10, ""),
+			b:     testVirtualMachineDisk(10, "aa6d5a82-1c88-45da-85d3-3d74b91a5bad"),
+			equal: true,
+		},
+		{
+			Name:  "size changed",
+			a:     testVirtualMachineDisk(10, ""),
+			b:     testVirtualMachineDisk(20, ""),
+			equal: false,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			a := resourceVSphereVirtualMachineDiskHash(tc.a)
+			b := resourceVSphereVirtualMachineDiskHash(tc.b)
+			if (a == b) != tc.equal {
+				t.Fatalf("expected hashes equal to be %t, got %d and %d", tc.equal, a, b)
+			}
+		})
+	}
+}
+
+func TestExpandVirtualMachineDiskStoragePolicyChangesUnchanged(t *testing.T) {
+	o := schema.NewSet(resourceVSphereVirtualMachineDiskHash, []interface{}{testVirtualMachineDisk(10, "policy")})
+	n := schema.NewSet(resourceVSphereVirtualMachineDiskHash, []interface{}{testVirtualMachineDisk(20, "policy")})
+	// No policy changes means the VM is never consulted, so a nil VM is safe
+	// here.
+	spec, err := expandVirtualMachineDiskStoragePolicyChanges(nil, o, n)
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	if len(spec) != 0 {
+		t.Fatalf("expected no changes, got %#v", spec)
+	}
+}
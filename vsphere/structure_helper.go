@@ -3,6 +3,7 @@ package vsphere
 import (
 	"fmt"
 	"reflect"
+	"strconv"
 
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/vmware/govmomi/vim25/types"
@@ -39,6 +40,13 @@ func mergeSchema(dst, src map[string]*schema.Schema) {
 	}
 }
 
+// validateNotConfigurable rejects any value for an attribute that is only ever
+// set by the provider. It is used for attributes that need to be Optional
+// rather than Computed, so that a value recorded on refresh shows up as a diff.
+func validateNotConfigurable(v interface{}, k string) ([]string, []error) {
+	return nil, []error{fmt.Errorf("%s is set by the provider and cannot be set in configuration", k)}
+}
+
 // boolPtr makes a *bool out of the value passed in through v.
 //
 // vSphere uses nil values in bools to omit values in the SOAP XML request, and
@@ -69,6 +77,32 @@ func setBoolPtr(d *schema.ResourceData, key string, val *bool) error {
 	return nil
 }
 
+// getBoolStringPtr reads a ResourceData key that holds a boolean as a string
+// ("true" or "false") and returns an appropriate *bool. nil is returned if the
+// string is empty, which allows a value to be omitted so that it is inherited
+// from a parent object.
+func getBoolStringPtr(d *schema.ResourceData, key string) (*bool, error) {
+	v := d.Get(key).(string)
+	if v == "" {
+		return nil, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s: %s", key, err)
+	}
+	return &b, nil
+}
+
+// setBoolStringPtr sets a ResourceData key that holds a boolean as a string
+// from a *bool. An empty string is set if the value is nil.
+func setBoolStringPtr(d *schema.ResourceData, key string, val *bool) error {
+	var s string
+	if val != nil {
+		s = strconv.FormatBool(*val)
+	}
+	return d.Set(key, s)
+}
+
 // int64Ptr makes an *int64 out of the value passed in through v.
 func int64Ptr(v int64) *int64 {
 	return &v
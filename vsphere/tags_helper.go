@@ -185,6 +185,8 @@ func tagTypeForObject(obj object.Reference) (string, error) {
 		return vSphereTagTypeVirtualMachine, nil
 	case *object.Datastore:
 		return vSphereTagTypeDatastore, nil
+	case *object.StoragePod:
+		return vSphereTagTypeStoragePod, nil
 	case *object.Network:
 		return vSphereTagTypeNetwork, nil
 	case *object.Folder:
@@ -201,6 +203,10 @@ func tagTypeForObject(obj object.Reference) (string, error) {
 		return vSphereTagTypeClusterComputeResource, nil
 	case *object.HostSystem:
 		return vSphereTagTypeHostSystem, nil
+	case *object.ResourcePool:
+		return vSphereTagTypeResourcePool, nil
+	case *object.VirtualApp:
+		return vSphereTagTypeVirtualApp, nil
 	}
 	return "", fmt.Errorf("unsupported type for tagging: %T", obj)
 }
@@ -0,0 +1,95 @@
+package vsphere
+
+import (
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// schemaVAppProductInfo returns the schema for the product information
+// section of a vApp. Only a single product is managed per vApp.
+func schemaVAppProductInfo() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"product_name": {
+			Type:        schema.TypeString,
+			Description: "The name of the product.",
+			Optional:    true,
+		},
+		"product_vendor": {
+			Type:        schema.TypeString,
+			Description: "The name of the product vendor.",
+			Optional:    true,
+		},
+		"product_version": {
+			Type:        schema.TypeString,
+			Description: "A short form of the product version.",
+			Optional:    true,
+		},
+		"product_full_version": {
+			Type:        schema.TypeString,
+			Description: "The full version of the product, including any build information.",
+			Optional:    true,
+		},
+		"product_vendor_url": {
+			Type:        schema.TypeString,
+			Description: "The URL of the vendor's website.",
+			Optional:    true,
+		},
+		"product_url": {
+			Type:        schema.TypeString,
+			Description: "The URL of the product's website.",
+			Optional:    true,
+		},
+		"product_app_url": {
+			Type:        schema.TypeString,
+			Description: "The URL of the running application.",
+			Optional:    true,
+		},
+	}
+}
+
+// expandVAppProductSpec reads the product information in the resource and
+// returns a VAppProductSpec. The supplied existing products determine whether
+// the product is added or edited in place.
+func expandVAppProductSpec(d *schema.ResourceData, existing []types.VAppProductInfo) []types.VAppProductSpec {
+	info := &types.VAppProductInfo{
+		Name:        d.Get("product_name").(string),
+		Vendor:      d.Get("product_vendor").(string),
+		Version:     d.Get("product_version").(string),
+		FullVersion: d.Get("product_full_version").(string),
+		VendorUrl:   d.Get("product_vendor_url").(string),
+		ProductUrl:  d.Get("product_url").(string),
+		AppUrl:      d.Get("product_app_url").(string),
+	}
+	op := types.ArrayUpdateOperationAdd
+	if len(existing) > 0 {
+		op = types.ArrayUpdateOperationEdit
+		info.Key = existing[0].Key
+		info.ClassId = existing[0].ClassId
+		info.InstanceId = existing[0].InstanceId
+	}
+	return []types.VAppProductSpec{
+		{
+			ArrayUpdateSpec: types.ArrayUpdateSpec{
+				Operation: op,
+			},
+			Info: info,
+		},
+	}
+}
+
+// flattenVAppProductInfo reads the first product in the supplied list into
+// the passed in ResourceData.
+func flattenVAppProductInfo(d *schema.ResourceData, products []types.VAppProductInfo) error {
+	var info types.VAppProductInfo
+	if len(products) > 0 {
+		info = products[0]
+	}
+	d.Set("product_name", info.Name)
+	d.Set("product_vendor", info.Vendor)
+	d.Set("product_version", info.Version)
+	d.Set("product_full_version", info.FullVersion)
+	d.Set("product_vendor_url", info.VendorUrl)
+	d.Set("product_url", info.ProductUrl)
+	d.Set("product_app_url", info.AppUrl)
+	return nil
+}
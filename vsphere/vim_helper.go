@@ -115,6 +115,37 @@ func isConcurrentAccessError(err error) bool {
 	return false
 }
 
+// isFileAlreadyExistsError checks an error to see if it's of the
+// FileAlreadyExists type.
+func isFileAlreadyExistsError(err error) bool {
+	if f, ok := vimSoapFault(err); ok {
+		switch f.(type) {
+		case types.FileAlreadyExists, *types.FileAlreadyExists:
+			return true
+		}
+	}
+	return false
+}
+
+// isFileNotFoundError checks an error to see if it's of the FileNotFound type.
+// This fault can come from a task as well as a direct SOAP call, so both are
+// checked.
+func isFileNotFoundError(err error) bool {
+	var f types.AnyType
+	var ok bool
+	f, ok = vimSoapFault(err)
+	if !ok {
+		f, ok = taskFault(err)
+	}
+	if ok {
+		switch f.(type) {
+		case types.FileNotFound, *types.FileNotFound:
+			return true
+		}
+	}
+	return false
+}
+
 // renameObject renames a MO and tracks the task to make sure it completes.
 func renameObject(client *govmomi.Client, ref types.ManagedObjectReference, new string) error {
 	req := types.Rename_Task{
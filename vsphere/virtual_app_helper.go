@@ -0,0 +1,93 @@
+package vsphere
+
+import (
+	"context"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// virtualAppFromID locates a VirtualApp by its managed object reference ID.
+func virtualAppFromID(client *govmomi.Client, id string) (*object.VirtualApp, error) {
+	finder := find.NewFinder(client.Client, false)
+
+	ref := types.ManagedObjectReference{
+		Type:  "VirtualApp",
+		Value: id,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	vapp, err := finder.ObjectReference(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	return vapp.(*object.VirtualApp), nil
+}
+
+// virtualAppFromPath loads a VirtualApp from its path.
+//
+// Datacenter is optional here - if not provided, it's expected that the path
+// is sufficient enough for finder to determine the datacenter required.
+func virtualAppFromPath(client *govmomi.Client, name string, dc *object.Datacenter) (*object.VirtualApp, error) {
+	finder := find.NewFinder(client.Client, false)
+	if dc != nil {
+		finder.SetDatacenter(dc)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return finder.VirtualApp(ctx, name)
+}
+
+// virtualAppProperties is a convenience method that wraps fetching the
+// VirtualApp MO from its higher-level object.
+func virtualAppProperties(vapp *object.VirtualApp) (*mo.VirtualApp, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	var props mo.VirtualApp
+	if err := vapp.Properties(ctx, vapp.Reference(), nil, &props); err != nil {
+		return nil, err
+	}
+	return &props, nil
+}
+
+// resourcePoolOrVirtualAppFromID locates a resource pool by its managed object
+// reference ID, where the ID may refer to either a ResourcePool or a
+// VirtualApp. The returned ResourcePool carries the reference type of the
+// object found, which can be checked with isVirtualAppReference.
+func resourcePoolOrVirtualAppFromID(client *govmomi.Client, id string) (*object.ResourcePool, error) {
+	vapp, err := virtualAppFromID(client, id)
+	if err == nil {
+		return vapp.ResourcePool, nil
+	}
+	if !isManagedObjectNotFoundError(err) {
+		return nil, err
+	}
+	return resourcePoolFromID(client, id)
+}
+
+// isVirtualAppReference returns true if the supplied reference is a
+// VirtualApp.
+func isVirtualAppReference(ref types.ManagedObjectReference) bool {
+	return ref.Type == "VirtualApp"
+}
+
+// createVirtualApp creates a VirtualApp as a child of the supplied parent
+// ResourcePool. folder must be nil if the parent is another vApp.
+func createVirtualApp(parent *object.ResourcePool, name string, resSpec types.ResourceConfigSpec, configSpec types.VAppConfigSpec, folder *object.Folder) (*object.VirtualApp, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return parent.CreateVApp(ctx, name, resSpec, configSpec, folder)
+}
+
+// updateVirtualAppConfig updates the vApp-specific configuration of a
+// VirtualApp, such as product information and entity start order.
+func updateVirtualAppConfig(vapp *object.VirtualApp, spec types.VAppConfigSpec) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return vapp.UpdateConfig(ctx, spec)
+}
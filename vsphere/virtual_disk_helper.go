@@ -0,0 +1,172 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// virtualDiskFileInfo searches a datastore for the virtual disk at the
+// supplied path, relative to the datastore root. nil is returned if the disk
+// does not exist.
+func virtualDiskFileInfo(ds *object.Datastore, vmdkPath string) (*types.VmDiskFileInfo, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	b, err := ds.Browser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// `Datastore.Stat` does not allow to query `VmDiskFileQuery`. Instead, we
+	// search the datastore manually.
+	spec := types.HostDatastoreBrowserSearchSpec{
+		Query: []types.BaseFileQuery{&types.VmDiskFileQuery{Details: &types.VmDiskFileQueryFlags{
+			CapacityKb:     true,
+			DiskType:       true,
+			Thin:           types.NewBool(true),
+			ControllerType: types.NewBool(true),
+		}}},
+		Details: &types.FileQueryFlags{
+			FileSize:     true,
+			FileType:     true,
+			Modification: true,
+			FileOwner:    types.NewBool(true),
+		},
+		MatchPattern: []string{path.Base(vmdkPath)},
+	}
+
+	task, err := b.SearchDatastore(ctx, ds.Path(path.Dir(vmdkPath)), &spec)
+	if err != nil {
+		return nil, err
+	}
+	info, err := task.WaitForResult(ctx, nil)
+	if err != nil {
+		if info != nil && info.Error != nil {
+			if _, ok := info.Error.Fault.(*types.FileNotFound); ok {
+				return nil, nil
+			}
+		}
+		return nil, err
+	}
+
+	res := info.Result.(types.HostDatastoreBrowserSearchResults)
+	log.Printf("[DEBUG] virtualDiskFileInfo: %d results for %q", len(res.File), vmdkPath)
+	switch len(res.File) {
+	case 0:
+		return nil, nil
+	case 1:
+	default:
+		return nil, fmt.Errorf("datastore search for %q did not return exactly one result", vmdkPath)
+	}
+	fileInfo, ok := res.File[0].(*types.VmDiskFileInfo)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a virtual disk", vmdkPath)
+	}
+	return fileInfo, nil
+}
+
+// virtualDiskType returns the provisioning type of a virtual disk, in the
+// format of the type argument of the vsphere_virtual_disk resource. An empty
+// string is returned for types that the resource does not support.
+func virtualDiskType(client *govmomi.Client, name string, dc *object.Datacenter) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	info, err := object.NewVirtualDiskManager(client.Client).QueryVirtualDiskInfo(ctx, name, dc, false)
+	if err != nil {
+		return "", err
+	}
+	if len(info) < 1 {
+		return "", fmt.Errorf("no disk information returned for %q", name)
+	}
+	switch types.VirtualDiskType(info[0].DiskType) {
+	case types.VirtualDiskTypeThin:
+		return "thin", nil
+	case types.VirtualDiskTypeEagerZeroedThick:
+		return "eagerZeroedThick", nil
+	case types.VirtualDiskTypePreallocated:
+		return "lazy", nil
+	}
+	return "", nil
+}
+
+// virtualDiskAdapterType returns the adapter type of a virtual disk from the
+// controller type reported by a datastore search, in the format of the
+// adapter_type argument of the vsphere_virtual_disk resource. An empty string
+// is returned for controllers that the resource does not support.
+func virtualDiskAdapterType(controllerType string) string {
+	switch controllerType {
+	case "VirtualIDEController":
+		return "ide"
+	case "VirtualBusLogicController":
+		return "busLogic"
+	case "VirtualLsiLogicController":
+		return "lsiLogic"
+	}
+	return ""
+}
+
+// extendVirtualDisk grows a virtual disk to the supplied capacity, optionally
+// eager zeroing the added space.
+func extendVirtualDisk(client *govmomi.Client, name string, dc *object.Datacenter, capacityKb int64, eagerZero bool) error {
+	req := types.ExtendVirtualDisk_Task{
+		This:          *client.ServiceContent.VirtualDiskManager,
+		Name:          name,
+		NewCapacityKb: capacityKb,
+		EagerZero:     &eagerZero,
+	}
+	if dc != nil {
+		ref := dc.Reference()
+		req.Datacenter = &ref
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	res, err := methods.ExtendVirtualDisk_Task(ctx, client.Client, &req)
+	if err != nil {
+		return err
+	}
+	t := object.NewTask(client.Client, res.Returnval)
+	// Extending, inflating, and copying large disks can take much longer than
+	// the API timeout, so like disk creation, these waits have no deadline.
+	return t.Wait(context.TODO())
+}
+
+// inflateVirtualDisk inflates a thin provisioned virtual disk to its full
+// size, zeroing the allocated space.
+func inflateVirtualDisk(client *govmomi.Client, name string, dc *object.Datacenter) error {
+	req := types.InflateVirtualDisk_Task{
+		This: *client.ServiceContent.VirtualDiskManager,
+		Name: name,
+	}
+	if dc != nil {
+		ref := dc.Reference()
+		req.Datacenter = &ref
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	res, err := methods.InflateVirtualDisk_Task(ctx, client.Client, &req)
+	if err != nil {
+		return err
+	}
+	t := object.NewTask(client.Client, res.Returnval)
+	return t.Wait(context.TODO())
+}
+
+// copyVirtualDisk copies a virtual disk to a new path, converting it to the
+// supplied disk and adapter types.
+func copyVirtualDisk(client *govmomi.Client, source, dest string, dc *object.Datacenter, spec *types.VirtualDiskSpec) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	task, err := object.NewVirtualDiskManager(client.Client).CopyVirtualDisk(ctx, source, dc, dest, dc, spec, false)
+	if err != nil {
+		return err
+	}
+	return task.Wait(context.TODO())
+}
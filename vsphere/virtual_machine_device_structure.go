@@ -0,0 +1,458 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+const (
+	virtualMachineSerialPortBackingTypeNetwork = "network"
+	virtualMachineSerialPortBackingTypeFile    = "file"
+)
+
+const (
+	virtualMachineUSBControllerTypeEHCI = "ehci"
+	virtualMachineUSBControllerTypeXHCI = "xhci"
+)
+
+var virtualMachineSerialPortBackingTypeAllowedValues = []string{
+	virtualMachineSerialPortBackingTypeNetwork,
+	virtualMachineSerialPortBackingTypeFile,
+}
+
+var virtualMachineSerialPortNetworkDirectionAllowedValues = []string{
+	string(types.VirtualDeviceURIBackingOptionDirectionServer),
+	string(types.VirtualDeviceURIBackingOptionDirectionClient),
+}
+
+var virtualMachineUSBControllerTypeAllowedValues = []string{
+	virtualMachineUSBControllerTypeEHCI,
+	virtualMachineUSBControllerTypeXHCI,
+}
+
+// virtualMachineDeviceSets holds the sets of devices that are managed in the
+// serial_port, usb_controller, and pci_device blocks of a virtual machine.
+type virtualMachineDeviceSets struct {
+	serialPorts    *schema.Set
+	usbControllers *schema.Set
+	pciDevices     *schema.Set
+}
+
+// virtualMachineDeviceSetsFromResourceData returns the old and new device
+// sets from a ResourceData. In a create operation, the old sets are empty.
+func virtualMachineDeviceSetsFromResourceData(d *schema.ResourceData) (virtualMachineDeviceSets, virtualMachineDeviceSets) {
+	var o, n virtualMachineDeviceSets
+	osp, nsp := d.GetChange("serial_port")
+	o.serialPorts, n.serialPorts = osp.(*schema.Set), nsp.(*schema.Set)
+	ou, nu := d.GetChange("usb_controller")
+	o.usbControllers, n.usbControllers = ou.(*schema.Set), nu.(*schema.Set)
+	op, np := d.GetChange("pci_device")
+	o.pciDevices, n.pciDevices = op.(*schema.Set), np.(*schema.Set)
+	return o, n
+}
+
+// hasPCIDevices returns true if the set of PCI devices is not empty.
+//
+// Passthrough devices require the memory reservation of a virtual machine to
+// be locked to the configured amount of memory, so this is used to determine
+// if the lock needs to be in place.
+func (s virtualMachineDeviceSets) hasPCIDevices() bool {
+	return s.pciDevices != nil && s.pciDevices.Len() > 0
+}
+
+// schemaVirtualMachineSerialPort returns the schema for the serial_port block
+// of a virtual machine.
+func schemaVirtualMachineSerialPort() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeSet,
+		Optional:    true,
+		Description: "A virtual serial port, backed by a network URI or a file on a datastore.",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"key": &schema.Schema{
+					Type:     schema.TypeInt,
+					Computed: true,
+				},
+				"backing_type": &schema.Schema{
+					Type:         schema.TypeString,
+					Required:     true,
+					Description:  "The backing type of the serial port. Can be one of network or file.",
+					ValidateFunc: validation.StringInSlice(virtualMachineSerialPortBackingTypeAllowedValues, false),
+				},
+				"network_uri": &schema.Schema{
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "The service URI for a network-backed serial port, such as telnet://:7001.",
+				},
+				"network_direction": &schema.Schema{
+					Type:         schema.TypeString,
+					Optional:     true,
+					Default:      string(types.VirtualDeviceURIBackingOptionDirectionServer),
+					Description:  "Whether the virtual machine listens on network_uri (server) or connects to it (client).",
+					ValidateFunc: validation.StringInSlice(virtualMachineSerialPortNetworkDirectionAllowedValues, false),
+				},
+				"proxy_uri": &schema.Schema{
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "The URI of a virtual serial port concentrator (vSPC) to proxy the connection through.",
+				},
+				"datastore": &schema.Schema{
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "The name of the datastore holding the output file of a file-backed serial port.",
+				},
+				"path": &schema.Schema{
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "The path to the output file of a file-backed serial port, relative to the datastore.",
+				},
+				"yield_on_poll": &schema.Schema{
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Default:     true,
+					Description: "Allow the guest to yield the CPU when polling the serial port.",
+				},
+			},
+		},
+	}
+}
+
+// expandVirtualMachineSerialPort reads a serial_port set entry and returns a
+// VirtualSerialPort with the supplied device key.
+func expandVirtualMachineSerialPort(m map[string]interface{}, key int32) (*types.VirtualSerialPort, error) {
+	dev := &types.VirtualSerialPort{
+		VirtualDevice: types.VirtualDevice{
+			Key: key,
+		},
+		YieldOnPoll: m["yield_on_poll"].(bool),
+	}
+	switch m["backing_type"].(string) {
+	case virtualMachineSerialPortBackingTypeNetwork:
+		uri := m["network_uri"].(string)
+		if uri == "" {
+			return nil, fmt.Errorf("network_uri is required for serial ports with a backing type of %q", virtualMachineSerialPortBackingTypeNetwork)
+		}
+		dev.Backing = &types.VirtualSerialPortURIBackingInfo{
+			VirtualDeviceURIBackingInfo: types.VirtualDeviceURIBackingInfo{
+				ServiceURI: uri,
+				Direction:  m["network_direction"].(string),
+				ProxyURI:   m["proxy_uri"].(string),
+			},
+		}
+	case virtualMachineSerialPortBackingTypeFile:
+		p := object.DatastorePath{
+			Datastore: m["datastore"].(string),
+			Path:      m["path"].(string),
+		}
+		if p.Datastore == "" || p.Path == "" {
+			return nil, fmt.Errorf("datastore and path are required for serial ports with a backing type of %q", virtualMachineSerialPortBackingTypeFile)
+		}
+		dev.Backing = &types.VirtualSerialPortFileBackingInfo{
+			VirtualDeviceFileBackingInfo: types.VirtualDeviceFileBackingInfo{
+				FileName: p.String(),
+			},
+		}
+	}
+	return dev, nil
+}
+
+// flattenVirtualMachineSerialPort reads a VirtualSerialPort into a
+// serial_port set entry. false is returned if the serial port has a backing
+// that Terraform does not manage.
+func flattenVirtualMachineSerialPort(dev *types.VirtualSerialPort) (map[string]interface{}, bool) {
+	m := map[string]interface{}{
+		"key":           dev.Key,
+		"yield_on_poll": dev.YieldOnPoll,
+	}
+	switch backing := dev.Backing.(type) {
+	case *types.VirtualSerialPortURIBackingInfo:
+		m["backing_type"] = virtualMachineSerialPortBackingTypeNetwork
+		m["network_uri"] = backing.ServiceURI
+		m["network_direction"] = backing.Direction
+		m["proxy_uri"] = backing.ProxyURI
+	case *types.VirtualSerialPortFileBackingInfo:
+		var p object.DatastorePath
+		if !p.FromString(backing.FileName) {
+			return nil, false
+		}
+		m["backing_type"] = virtualMachineSerialPortBackingTypeFile
+		m["datastore"] = p.Datastore
+		m["path"] = p.Path
+	default:
+		return nil, false
+	}
+	return m, true
+}
+
+// schemaVirtualMachineUSBController returns the schema for the
+// usb_controller block of a virtual machine.
+func schemaVirtualMachineUSBController() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeSet,
+		Optional:    true,
+		Description: "A virtual USB controller. Only one controller of each type can be added to a virtual machine.",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"key": &schema.Schema{
+					Type:     schema.TypeInt,
+					Computed: true,
+				},
+				"type": &schema.Schema{
+					Type:         schema.TypeString,
+					Required:     true,
+					Description:  "The type of the USB controller. Can be one of ehci (USB 2.0) or xhci (USB 3.0).",
+					ValidateFunc: validation.StringInSlice(virtualMachineUSBControllerTypeAllowedValues, false),
+				},
+				"auto_connect_devices": &schema.Schema{
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Default:     false,
+					Description: "Automatically connect new USB devices plugged into the host to the virtual machine.",
+				},
+			},
+		},
+	}
+}
+
+// expandVirtualMachineUSBController reads a usb_controller set entry and
+// returns the appropriate USB controller device with the supplied device key.
+func expandVirtualMachineUSBController(m map[string]interface{}, key int32) types.BaseVirtualDevice {
+	controller := types.VirtualController{
+		VirtualDevice: types.VirtualDevice{
+			Key: key,
+		},
+	}
+	autoConnect := boolPtr(m["auto_connect_devices"].(bool))
+	if m["type"].(string) == virtualMachineUSBControllerTypeXHCI {
+		return &types.VirtualUSBXHCIController{
+			VirtualController:  controller,
+			AutoConnectDevices: autoConnect,
+		}
+	}
+	return &types.VirtualUSBController{
+		VirtualController:  controller,
+		AutoConnectDevices: autoConnect,
+		EhciEnabled:        boolPtr(true),
+	}
+}
+
+// flattenVirtualMachineUSBController reads a USB controller device into a
+// usb_controller set entry. false is returned if the device is not an EHCI or
+// xHCI USB controller. Plain (UHCI) controllers are not managed.
+func flattenVirtualMachineUSBController(dev types.BaseVirtualDevice) (map[string]interface{}, bool) {
+	m := make(map[string]interface{})
+	var autoConnect *bool
+	switch controller := dev.(type) {
+	case *types.VirtualUSBController:
+		if controller.EhciEnabled == nil || !*controller.EhciEnabled {
+			return nil, false
+		}
+		m["type"] = virtualMachineUSBControllerTypeEHCI
+		autoConnect = controller.AutoConnectDevices
+	case *types.VirtualUSBXHCIController:
+		m["type"] = virtualMachineUSBControllerTypeXHCI
+		autoConnect = controller.AutoConnectDevices
+	default:
+		return nil, false
+	}
+	m["key"] = dev.GetVirtualDevice().Key
+	m["auto_connect_devices"] = autoConnect != nil && *autoConnect
+	return m, true
+}
+
+// schemaVirtualMachinePCIDevice returns the schema for the pci_device block
+// of a virtual machine.
+func schemaVirtualMachinePCIDevice() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeSet,
+		Optional:    true,
+		Description: "A DirectPath I/O PCI passthrough device from the host the virtual machine is running on.",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"key": &schema.Schema{
+					Type:     schema.TypeInt,
+					Computed: true,
+				},
+				"host_device_id": &schema.Schema{
+					Type:        schema.TypeString,
+					Required:    true,
+					Description: "The PCI ID of the host device, such as 0000:04:00.0. The device must be enabled for passthrough on the host.",
+				},
+			},
+		},
+	}
+}
+
+// expandVirtualMachinePCIPassthrough returns a VirtualPCIPassthrough device
+// for the supplied host device, with the supplied device key.
+func expandVirtualMachinePCIPassthrough(info *types.VirtualMachinePciPassthroughInfo, key int32) *types.VirtualPCIPassthrough {
+	return &types.VirtualPCIPassthrough{
+		VirtualDevice: types.VirtualDevice{
+			Key: key,
+			Backing: &types.VirtualPCIPassthroughDeviceBackingInfo{
+				VirtualDeviceDeviceBackingInfo: types.VirtualDeviceDeviceBackingInfo{
+					DeviceName: info.PciDevice.DeviceName,
+				},
+				Id:       info.PciDevice.Id,
+				DeviceId: fmt.Sprintf("%x", uint16(info.PciDevice.DeviceId)),
+				SystemId: info.SystemId,
+				VendorId: info.PciDevice.VendorId,
+			},
+		},
+	}
+}
+
+// flattenVirtualMachinePCIPassthrough reads a VirtualPCIPassthrough device
+// into a pci_device set entry. false is returned if the device is not a
+// DirectPath I/O device.
+func flattenVirtualMachinePCIPassthrough(dev *types.VirtualPCIPassthrough) (map[string]interface{}, bool) {
+	backing, ok := dev.Backing.(*types.VirtualPCIPassthroughDeviceBackingInfo)
+	if !ok {
+		return nil, false
+	}
+	m := map[string]interface{}{
+		"key":            dev.Key,
+		"host_device_id": backing.Id,
+	}
+	return m, true
+}
+
+// flattenVirtualMachineDeviceSets reads the serial ports, USB controllers, and
+// PCI passthrough devices from a device list into the passed in ResourceData.
+//
+// Each kind of device is only read if its block is in use in the resource, so
+// that devices that come from a clone template, and are not managed by
+// Terraform, do not show up as a diff and get removed.
+func flattenVirtualMachineDeviceSets(d *schema.ResourceData, devices object.VirtualDeviceList) error {
+	var serialPorts, usbControllers, pciDevices []interface{}
+	for _, device := range devices {
+		switch dev := device.(type) {
+		case *types.VirtualSerialPort:
+			if m, ok := flattenVirtualMachineSerialPort(dev); ok {
+				serialPorts = append(serialPorts, m)
+			} else {
+				log.Printf("[DEBUG] Skipping serial port %d with unmanaged backing %T", dev.Key, dev.Backing)
+			}
+		case *types.VirtualUSBController, *types.VirtualUSBXHCIController:
+			if m, ok := flattenVirtualMachineUSBController(dev); ok {
+				usbControllers = append(usbControllers, m)
+			} else {
+				log.Printf("[DEBUG] Skipping unmanaged USB controller %d", dev.GetVirtualDevice().Key)
+			}
+		case *types.VirtualPCIPassthrough:
+			if m, ok := flattenVirtualMachinePCIPassthrough(dev); ok {
+				pciDevices = append(pciDevices, m)
+			} else {
+				log.Printf("[DEBUG] Skipping PCI device %d with unmanaged backing %T", dev.Key, dev.Backing)
+			}
+		}
+	}
+	for k, v := range map[string][]interface{}{
+		"serial_port":    serialPorts,
+		"usb_controller": usbControllers,
+		"pci_device":     pciDevices,
+	} {
+		if d.Get(k).(*schema.Set).Len() < 1 {
+			log.Printf("[DEBUG] %s is not in use, not reading %d device(s)", k, len(v))
+			continue
+		}
+		if err := d.Set(k, v); err != nil {
+			return fmt.Errorf("error setting %s: %s", k, err)
+		}
+	}
+	return nil
+}
+
+// deviceSetDifference returns the items in a that are not in b as a list. A
+// nil set is treated as an empty set.
+func deviceSetDifference(a, b *schema.Set) []interface{} {
+	if a == nil {
+		return nil
+	}
+	if b == nil {
+		return a.List()
+	}
+	return a.Difference(b).List()
+}
+
+// expandVirtualMachineDeviceChanges computes the device changes necessary to
+// go from the old set of serial ports, USB controllers, and PCI passthrough
+// devices to the new one. Devices that have been removed or modified are
+// removed by their key, and new or modified devices are added.
+//
+// The virtual machine is used to look up the PCI passthrough devices that are
+// available on its host, so it needs to exist already.
+func expandVirtualMachineDeviceChanges(client *govmomi.Client, vm *object.VirtualMachine, o, n virtualMachineDeviceSets) ([]types.BaseVirtualDeviceConfigSpec, error) {
+	var spec []types.BaseVirtualDeviceConfigSpec
+	remove := func(items []interface{}) {
+		for _, item := range items {
+			key := int32(item.(map[string]interface{})["key"].(int))
+			spec = append(spec, &types.VirtualDeviceConfigSpec{
+				Operation: types.VirtualDeviceConfigSpecOperationRemove,
+				Device:    &types.VirtualDevice{Key: key},
+			})
+		}
+	}
+	add := func(dev types.BaseVirtualDevice) {
+		spec = append(spec, &types.VirtualDeviceConfigSpec{
+			Operation: types.VirtualDeviceConfigSpecOperationAdd,
+			Device:    dev,
+		})
+	}
+	// New devices need unique, negative keys until they are created.
+	var nextKey int32
+	newKey := func() int32 {
+		nextKey--
+		return nextKey
+	}
+
+	remove(deviceSetDifference(o.serialPorts, n.serialPorts))
+	remove(deviceSetDifference(o.usbControllers, n.usbControllers))
+	remove(deviceSetDifference(o.pciDevices, n.pciDevices))
+
+	for _, item := range deviceSetDifference(n.serialPorts, o.serialPorts) {
+		dev, err := expandVirtualMachineSerialPort(item.(map[string]interface{}), newKey())
+		if err != nil {
+			return nil, err
+		}
+		add(dev)
+	}
+
+	usbTypes := make(map[string]bool)
+	if n.usbControllers != nil {
+		for _, item := range n.usbControllers.List() {
+			t := item.(map[string]interface{})["type"].(string)
+			if usbTypes[t] {
+				return nil, fmt.Errorf("only one usb_controller of type %q can be defined", t)
+			}
+			usbTypes[t] = true
+		}
+	}
+	for _, item := range deviceSetDifference(n.usbControllers, o.usbControllers) {
+		add(expandVirtualMachineUSBController(item.(map[string]interface{}), newKey()))
+	}
+
+	addedPCI := deviceSetDifference(n.pciDevices, o.pciDevices)
+	if len(addedPCI) > 0 {
+		targets, err := virtualMachinePCIPassthroughTargets(client, vm)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range addedPCI {
+			id := item.(map[string]interface{})["host_device_id"].(string)
+			info, ok := targets[id]
+			if !ok {
+				return nil, fmt.Errorf("PCI device %q is not available for passthrough on the virtual machine's host", id)
+			}
+			add(expandVirtualMachinePCIPassthrough(info, newKey()))
+		}
+	}
+
+	return spec, nil
+}
@@ -0,0 +1,236 @@
+package vsphere
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// testDeviceSet returns a set for the supplied device block schema, populated
+// with the supplied items.
+func testDeviceSet(s *schema.Schema, items ...map[string]interface{}) *schema.Set {
+	set := schema.NewSet(schema.HashResource(s.Elem.(*schema.Resource)), nil)
+	for _, item := range items {
+		set.Add(item)
+	}
+	return set
+}
+
+func testSerialPortNetwork(key int, uri string) map[string]interface{} {
+	return map[string]interface{}{
+		"key":               key,
+		"backing_type":      virtualMachineSerialPortBackingTypeNetwork,
+		"network_uri":       uri,
+		"network_direction": "server",
+		"proxy_uri":         "",
+		"datastore":         "",
+		"path":              "",
+		"yield_on_poll":     true,
+	}
+}
+
+func testUSBController(key int, t string) map[string]interface{} {
+	return map[string]interface{}{
+		"key":                  key,
+		"type":                 t,
+		"auto_connect_devices": false,
+	}
+}
+
+type testExpandVirtualMachineDeviceChanges struct {
+	Name string
+
+	old      virtualMachineDeviceSets
+	new      virtualMachineDeviceSets
+	expected []types.BaseVirtualDeviceConfigSpec
+	err      bool
+}
+
+func (tc *testExpandVirtualMachineDeviceChanges) Test(t *testing.T) {
+	actual, err := expandVirtualMachineDeviceChanges(nil, nil, tc.old, tc.new)
+	if tc.err {
+		if err == nil {
+			t.Fatalf("expected error, got none")
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	if !reflect.DeepEqual(tc.expected, actual) {
+		t.Fatalf("expected %#v, got %#v", tc.expected, actual)
+	}
+}
+
+func TestExpandVirtualMachineDeviceChanges(t *testing.T) {
+	serialPort := schemaVirtualMachineSerialPort()
+	usbController := schemaVirtualMachineUSBController()
+	cases := []testExpandVirtualMachineDeviceChanges{
+		{
+			Name:     "no devices",
+			expected: nil,
+		},
+		{
+			Name: "add network serial port and xHCI controller",
+			new: virtualMachineDeviceSets{
+				serialPorts:    testDeviceSet(serialPort, testSerialPortNetwork(0, "telnet://:7001")),
+				usbControllers: testDeviceSet(usbController, testUSBController(0, virtualMachineUSBControllerTypeXHCI)),
+			},
+			expected: []types.BaseVirtualDeviceConfigSpec{
+				&types.VirtualDeviceConfigSpec{
+					Operation: types.VirtualDeviceConfigSpecOperationAdd,
+					Device: &types.VirtualSerialPort{
+						VirtualDevice: types.VirtualDevice{
+							Key: -1,
+							Backing: &types.VirtualSerialPortURIBackingInfo{
+								VirtualDeviceURIBackingInfo: types.VirtualDeviceURIBackingInfo{
+									ServiceURI: "telnet://:7001",
+									Direction:  "server",
+								},
+							},
+						},
+						YieldOnPoll: true,
+					},
+				},
+				&types.VirtualDeviceConfigSpec{
+					Operation: types.VirtualDeviceConfigSpecOperationAdd,
+					Device: &types.VirtualUSBXHCIController{
+						VirtualController: types.VirtualController{
+							VirtualDevice: types.VirtualDevice{
+								Key: -2,
+							},
+						},
+						AutoConnectDevices: boolPtr(false),
+					},
+				},
+			},
+		},
+		{
+			Name: "modify serial port",
+			old: virtualMachineDeviceSets{
+				serialPorts: testDeviceSet(serialPort, testSerialPortNetwork(9000, "telnet://:7001")),
+			},
+			new: virtualMachineDeviceSets{
+				serialPorts: testDeviceSet(serialPort, testSerialPortNetwork(9000, "telnet://:7002")),
+			},
+			expected: []types.BaseVirtualDeviceConfigSpec{
+				&types.VirtualDeviceConfigSpec{
+					Operation: types.VirtualDeviceConfigSpecOperationRemove,
+					Device:    &types.VirtualDevice{Key: 9000},
+				},
+				&types.VirtualDeviceConfigSpec{
+					Operation: types.VirtualDeviceConfigSpecOperationAdd,
+					Device: &types.VirtualSerialPort{
+						VirtualDevice: types.VirtualDevice{
+							Key: -1,
+							Backing: &types.VirtualSerialPortURIBackingInfo{
+								VirtualDeviceURIBackingInfo: types.VirtualDeviceURIBackingInfo{
+									ServiceURI: "telnet://:7002",
+									Direction:  "server",
+								},
+							},
+						},
+						YieldOnPoll: true,
+					},
+				},
+			},
+		},
+		{
+			Name: "unchanged USB controller",
+			old: virtualMachineDeviceSets{
+				usbControllers: testDeviceSet(usbController, testUSBController(7000, virtualMachineUSBControllerTypeEHCI)),
+			},
+			new: virtualMachineDeviceSets{
+				usbControllers: testDeviceSet(usbController, testUSBController(0, virtualMachineUSBControllerTypeEHCI)),
+			},
+			expected: nil,
+		},
+		{
+			Name: "duplicate USB controller types",
+			new: virtualMachineDeviceSets{
+				usbControllers: testDeviceSet(
+					usbController,
+					testUSBController(0, virtualMachineUSBControllerTypeEHCI),
+					map[string]interface{}{
+						"key":                  0,
+						"type":                 virtualMachineUSBControllerTypeEHCI,
+						"auto_connect_devices": true,
+					},
+				),
+			},
+			err: true,
+		},
+		{
+			Name: "file serial port without path",
+			new: virtualMachineDeviceSets{
+				serialPorts: testDeviceSet(serialPort, map[string]interface{}{
+					"key":               0,
+					"backing_type":      virtualMachineSerialPortBackingTypeFile,
+					"network_uri":       "",
+					"network_direction": "server",
+					"proxy_uri":         "",
+					"datastore":         "datastore1",
+					"path":              "",
+					"yield_on_poll":     true,
+				}),
+			},
+			err: true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, tc.Test)
+	}
+}
+
+func TestFlattenVirtualMachineUSBController(t *testing.T) {
+	cases := []struct {
+		name     string
+		dev      types.BaseVirtualDevice
+		expected map[string]interface{}
+	}{
+		{
+			name: "ehci",
+			dev: &types.VirtualUSBController{
+				VirtualController: types.VirtualController{VirtualDevice: types.VirtualDevice{Key: 7000}},
+				EhciEnabled:       boolPtr(true),
+			},
+			expected: map[string]interface{}{
+				"key":                  int32(7000),
+				"type":                 virtualMachineUSBControllerTypeEHCI,
+				"auto_connect_devices": false,
+			},
+		},
+		{
+			name: "uhci",
+			dev: &types.VirtualUSBController{
+				VirtualController: types.VirtualController{VirtualDevice: types.VirtualDevice{Key: 7000}},
+			},
+		},
+		{
+			name: "xhci",
+			dev: &types.VirtualUSBXHCIController{
+				VirtualController:  types.VirtualController{VirtualDevice: types.VirtualDevice{Key: 14000}},
+				AutoConnectDevices: boolPtr(false),
+			},
+			expected: map[string]interface{}{
+				"key":                  int32(14000),
+				"type":                 virtualMachineUSBControllerTypeXHCI,
+				"auto_connect_devices": false,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, ok := flattenVirtualMachineUSBController(tc.dev)
+			if ok != (tc.expected != nil) {
+				t.Fatalf("expected ok to be %t, got %t", tc.expected != nil, ok)
+			}
+			if ok && !reflect.DeepEqual(tc.expected, actual) {
+				t.Fatalf("expected %#v, got %#v", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -4,12 +4,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
 	"net"
+	"strconv"
+	"strings"
 
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/find"
 	"github.com/vmware/govmomi/object"
 	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25/methods"
 	"github.com/vmware/govmomi/vim25/mo"
 	"github.com/vmware/govmomi/vim25/types"
 )
@@ -69,6 +73,17 @@ func virtualMachineFromManagedObjectID(client *govmomi.Client, id string) (*obje
 	return vm.(*object.VirtualMachine), nil
 }
 
+// virtualMachineFromPath locates a virtualMachine by its name or inventory
+// path, relative to the supplied datacenter.
+func virtualMachineFromPath(client *govmomi.Client, path string, dc *object.Datacenter) (*object.VirtualMachine, error) {
+	finder := find.NewFinder(client.Client, false)
+	finder.SetDatacenter(dc)
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return finder.VirtualMachine(ctx, path)
+}
+
 // virtualMachineProperties is a convenience method that wraps fetching the
 // VirtualMachine MO from its higher-level object.
 func virtualMachineProperties(vm *object.VirtualMachine) (*mo.VirtualMachine, error) {
@@ -81,12 +96,371 @@ func virtualMachineProperties(vm *object.VirtualMachine) (*mo.VirtualMachine, er
 	return &props, nil
 }
 
-// waitForGuestVMNet waits for a virtual machine to have routeable network
-// access. This is denoted as a gateway, and at least one IP address that can
-// reach that gateway. This function supports both IPv4 and IPv6, and returns
-// the moment either stack is routeable - it doesn't wait for both.
-func waitForGuestVMNet(client *govmomi.Client, vm *object.VirtualMachine) error {
-	var v4gw, v6gw net.IP
+// virtualMachineReferencesFromUUIDs locates the virtual machines with the
+// supplied UUIDs and returns their managed object references.
+func virtualMachineReferencesFromUUIDs(client *govmomi.Client, uuids []string) ([]types.ManagedObjectReference, error) {
+	var refs []types.ManagedObjectReference
+	for _, uuid := range uuids {
+		vm, err := virtualMachineFromUUID(client, uuid)
+		if err != nil {
+			return nil, fmt.Errorf("cannot locate virtual machine with UUID %q: %s", uuid, err)
+		}
+		refs = append(refs, vm.Reference())
+	}
+	return refs, nil
+}
+
+// virtualMachineUUIDsFromReferences returns the UUIDs of the virtual machines
+// referenced by the supplied managed object references.
+func virtualMachineUUIDsFromReferences(client *govmomi.Client, refs []types.ManagedObjectReference) ([]string, error) {
+	var uuids []string
+	for _, ref := range refs {
+		vm, err := virtualMachineFromManagedObjectID(client, ref.Value)
+		if err != nil {
+			return nil, fmt.Errorf("cannot locate virtual machine %q: %s", ref.Value, err)
+		}
+		props, err := virtualMachineProperties(vm)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching properties for virtual machine %q: %s", ref.Value, err)
+		}
+		if props.Config == nil {
+			return nil, fmt.Errorf("virtual machine %q has no configuration", ref.Value)
+		}
+		uuids = append(uuids, props.Config.Uuid)
+	}
+	return uuids, nil
+}
+
+// virtualMachinePCIPassthroughTargets returns the PCI devices that can be
+// passed through to a virtual machine, keyed by their PCI ID.
+//
+// Only devices that are active for passthrough in the PciPassthruInfo of the
+// virtual machine's current host are returned. The remaining device details,
+// such as the system ID, come from the config target of the virtual machine's
+// environment browser.
+func virtualMachinePCIPassthroughTargets(client *govmomi.Client, vm *object.VirtualMachine) (map[string]*types.VirtualMachinePciPassthroughInfo, error) {
+	props, err := virtualMachineProperties(vm)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching virtual machine properties: %s", err)
+	}
+	if props.Runtime.Host == nil {
+		return nil, fmt.Errorf("virtual machine %q is not assigned to a host", vm.InventoryPath)
+	}
+	host := object.NewHostSystem(client.Client, *props.Runtime.Host)
+	hprops, err := hostSystemProperties(host)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching host properties: %s", err)
+	}
+	active := make(map[string]bool)
+	if hprops.Config != nil {
+		for _, v := range hprops.Config.PciPassthruInfo {
+			info := v.GetHostPciPassthruInfo()
+			active[info.Id] = info.PassthruActive
+		}
+	}
+
+	req := types.QueryConfigTarget{
+		This: props.EnvironmentBrowser,
+		Host: props.Runtime.Host,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	res, err := methods.QueryConfigTarget(ctx, client.Client, &req)
+	if err != nil {
+		return nil, fmt.Errorf("error querying config target: %s", err)
+	}
+
+	targets := make(map[string]*types.VirtualMachinePciPassthroughInfo)
+	if res.Returnval == nil {
+		return targets, nil
+	}
+	for _, v := range res.Returnval.PciPassthrough {
+		info, ok := v.(*types.VirtualMachinePciPassthroughInfo)
+		if !ok || !active[info.PciDevice.Id] {
+			continue
+		}
+		targets[info.PciDevice.Id] = info
+	}
+	return targets, nil
+}
+
+// virtualMachineHardwareVersionString returns the version key for a virtual
+// hardware version number, such as vmx-13 for 13.
+func virtualMachineHardwareVersionString(version int) string {
+	return fmt.Sprintf("vmx-%02d", version)
+}
+
+// parseVirtualMachineHardwareVersion parses a virtual hardware version key,
+// such as vmx-13, into its version number.
+func parseVirtualMachineHardwareVersion(s string) (int, error) {
+	v, err := strconv.Atoi(strings.TrimPrefix(s, "vmx-"))
+	if err != nil || !strings.HasPrefix(s, "vmx-") {
+		return 0, fmt.Errorf("could not parse virtual hardware version %q", s)
+	}
+	return v, nil
+}
+
+// environmentBrowserFromResourcePool returns the reference to the
+// EnvironmentBrowser of the compute resource that owns a resource pool.
+func environmentBrowserFromResourcePool(client *govmomi.Client, pool *object.ResourcePool) (types.ManagedObjectReference, error) {
+	collector := property.DefaultCollector(client.Client)
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	var rp mo.ResourcePool
+	if err := collector.RetrieveOne(ctx, pool.Reference(), []string{"owner"}, &rp); err != nil {
+		return types.ManagedObjectReference{}, fmt.Errorf("error fetching resource pool owner: %s", err)
+	}
+	var cr mo.ComputeResource
+	if err := collector.RetrieveOne(ctx, rp.Owner, []string{"environmentBrowser"}, &cr); err != nil {
+		return types.ManagedObjectReference{}, fmt.Errorf("error fetching compute resource environment browser: %s", err)
+	}
+	if cr.EnvironmentBrowser == nil {
+		return types.ManagedObjectReference{}, fmt.Errorf("compute resource %q has no environment browser", rp.Owner.Value)
+	}
+	return *cr.EnvironmentBrowser, nil
+}
+
+// validateVirtualMachineHardwareVersion checks a virtual hardware version
+// against the config option descriptors of an EnvironmentBrowser. If create
+// is true, the version needs to be supported for creating new virtual
+// machines, otherwise it needs to be supported as an upgrade target.
+func validateVirtualMachineHardwareVersion(client *govmomi.Client, browser types.ManagedObjectReference, version int, create bool) error {
+	req := types.QueryConfigOptionDescriptor{
+		This: browser,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	res, err := methods.QueryConfigOptionDescriptor(ctx, client.Client, &req)
+	if err != nil {
+		return fmt.Errorf("error querying supported virtual hardware versions: %s", err)
+	}
+	key := virtualMachineHardwareVersionString(version)
+	var supported []string
+	for _, desc := range res.Returnval {
+		ok := desc.UpgradeSupported
+		if create {
+			ok = desc.CreateSupported
+		}
+		if ok == nil || !*ok {
+			continue
+		}
+		if desc.Key == key {
+			return nil
+		}
+		supported = append(supported, desc.Key)
+	}
+	return fmt.Errorf("virtual hardware version %d (%s) is not supported, supported versions are: %s", version, key, strings.Join(supported, ", "))
+}
+
+// upgradeVirtualMachineHardware upgrades the virtual hardware of a virtual
+// machine to the supplied version. The virtual machine needs to be powered
+// off.
+func upgradeVirtualMachineHardware(client *govmomi.Client, vm *object.VirtualMachine, version int) error {
+	req := types.UpgradeVM_Task{
+		This:    vm.Reference(),
+		Version: virtualMachineHardwareVersionString(version),
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	res, err := methods.UpgradeVM_Task(ctx, client.Client, &req)
+	if err != nil {
+		return err
+	}
+	t := object.NewTask(client.Client, res.Returnval)
+	tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer tcancel()
+	return t.Wait(tctx)
+}
+
+// guestIPSelection is the result of selectGuestIPs, and describes the
+// addresses that VMware tools is reporting for a guest, after local,
+// auto-configuration, and ignored addresses have been filtered out.
+type guestIPSelection struct {
+	// All eligible addresses. IPv4 addresses are listed first, followed by IPv6
+	// addresses.
+	addresses []string
+
+	// The default IP address of the guest. This is the first IPv4 address that
+	// can reach a default gateway, falling back to the first IPv6 address that
+	// can reach one. If no address is routeable, this is the first eligible
+	// address found, if any.
+	defaultAddress string
+
+	// true if defaultAddress can reach a default gateway.
+	routable bool
+}
+
+// guestDefaultGateway represents a default route found in a guest's IP
+// stack.
+type guestDefaultGateway struct {
+	// The IP address of the gateway.
+	ip net.IP
+
+	// The index of the device in guest.net that the gateway is reachable
+	// through. This is -1 if the device could not be determined.
+	device int
+}
+
+// parseGuestIPNetworks parses a list of networks in CIDR notation, such as
+// the ones supplied in the ignored_guest_ips attribute of
+// vsphere_virtual_machine, into a slice of *net.IPNet.
+func parseGuestIPNetworks(cidrs []interface{}) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, v := range cidrs {
+		_, n, err := net.ParseCIDR(v.(string))
+		if err != nil {
+			return nil, fmt.Errorf("could not parse network %q: %s", v, err)
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
+
+// guestIPIsEligible checks an IP address reported by a guest to see if it
+// can be used as a guest IP address. Loopback, link-local (including IPv4
+// APIPA addresses), multicast, and unspecified addresses are never eligible,
+// nor is any address that falls in one of the networks supplied in ignored.
+func guestIPIsEligible(ip net.IP, ignored []*net.IPNet) bool {
+	switch {
+	case ip == nil:
+		return false
+	case ip.IsLoopback():
+		return false
+	case ip.IsLinkLocalUnicast():
+		return false
+	case ip.IsMulticast():
+		return false
+	case ip.IsUnspecified():
+		return false
+	}
+	for _, n := range ignored {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// guestDefaultGateways returns the IPv4 and IPv6 default gateways found in
+// the supplied guest IP stack information.
+func guestDefaultGateways(stacks []types.GuestStackInfo) ([]guestDefaultGateway, []guestDefaultGateway) {
+	var v4gws, v6gws []guestDefaultGateway
+	for _, s := range stacks {
+		if s.IpRouteConfig == nil {
+			continue
+		}
+		for _, r := range s.IpRouteConfig.IpRoute {
+			if r.PrefixLength != 0 {
+				continue
+			}
+			gw := guestDefaultGateway{
+				ip:     net.ParseIP(r.Gateway.IpAddress),
+				device: -1,
+			}
+			if gw.ip == nil {
+				continue
+			}
+			// "device" is a string, even though its value has always been observed
+			// to be an int.
+			if n, err := strconv.Atoi(r.Gateway.Device); err == nil {
+				gw.device = n
+			}
+			switch r.Network {
+			case "0.0.0.0":
+				v4gws = append(v4gws, gw)
+			case "::":
+				v6gws = append(v6gws, gw)
+			}
+		}
+	}
+	return v4gws, v6gws
+}
+
+// guestIPIsRoutable checks to see if an IP address on the guest device at
+// index device can reach any of the supplied gateways.
+//
+// IPv6 default gateways are usually advertised with a link-local address,
+// which will never share a network with a global address. In that case, the
+// address is considered routeable if it is on the same device as the gateway.
+func guestIPIsRoutable(ip net.IP, prefix int32, device int, gws []guestDefaultGateway) bool {
+	bits := 128
+	if ip.To4() != nil {
+		bits = 32
+	}
+	mask := net.CIDRMask(int(prefix), bits)
+	for _, gw := range gws {
+		if gw.ip.IsLinkLocalUnicast() && gw.ip.To4() == nil {
+			if gw.device == device {
+				return true
+			}
+			continue
+		}
+		if ip.Mask(mask).Equal(gw.ip.Mask(mask)) {
+			return true
+		}
+	}
+	return false
+}
+
+// selectGuestIPs builds a guestIPSelection out of the NIC and IP stack
+// information reported by VMware tools, skipping any addresses that are not
+// eligible as per guestIPIsEligible.
+func selectGuestIPs(nics []types.GuestNicInfo, stacks []types.GuestStackInfo, ignored []*net.IPNet) guestIPSelection {
+	var sel guestIPSelection
+	var v4addrs, v6addrs []string
+	var v4default, v6default string
+	v4gws, v6gws := guestDefaultGateways(stacks)
+
+	for i, n := range nics {
+		if n.IpConfig == nil {
+			continue
+		}
+		for _, addr := range n.IpConfig.IpAddress {
+			ip := net.ParseIP(addr.IpAddress)
+			if !guestIPIsEligible(ip, ignored) {
+				continue
+			}
+			if ip.To4() != nil {
+				v4addrs = append(v4addrs, ip.String())
+				if v4default == "" && guestIPIsRoutable(ip, addr.PrefixLength, i, v4gws) {
+					v4default = ip.String()
+				}
+			} else {
+				v6addrs = append(v6addrs, ip.String())
+				if v6default == "" && guestIPIsRoutable(ip, addr.PrefixLength, i, v6gws) {
+					v6default = ip.String()
+				}
+			}
+		}
+	}
+
+	sel.addresses = append(v4addrs, v6addrs...)
+	switch {
+	case v4default != "":
+		sel.defaultAddress = v4default
+		sel.routable = true
+	case v6default != "":
+		sel.defaultAddress = v6default
+		sel.routable = true
+	case len(sel.addresses) > 0:
+		sel.defaultAddress = sel.addresses[0]
+	}
+	return sel
+}
+
+// waitForGuestVMNet waits for a virtual machine to have usable network
+// access, by watching the guest.net and guest.ipStack properties through
+// WaitForUpdates on the property collector.
+//
+// Addresses that are not eligible as per guestIPIsEligible are never
+// considered. If routable is true, the function waits for an eligible address
+// that can reach a default gateway. This function supports both IPv4 and
+// IPv6, and returns the moment either stack is routeable - it doesn't wait for
+// both. If routable is false, the function returns as soon as any eligible
+// address is available.
+func waitForGuestVMNet(client *govmomi.Client, vm *object.VirtualMachine, routable bool, ignored []*net.IPNet) error {
+	var nics []types.GuestNicInfo
+	var stacks []types.GuestStackInfo
 
 	p := client.PropertyCollector()
 	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
@@ -100,45 +474,27 @@ func waitForGuestVMNet(client *govmomi.Client, vm *object.VirtualMachine) error
 
 			switch v := c.Val.(type) {
 			case types.ArrayOfGuestStackInfo:
-				for _, s := range v.GuestStackInfo {
-					if s.IpRouteConfig != nil {
-						for _, r := range s.IpRouteConfig.IpRoute {
-							switch r.Network {
-							case "0.0.0.0":
-								v4gw = net.ParseIP(r.Gateway.IpAddress)
-							case "::":
-								v6gw = net.ParseIP(r.Gateway.IpAddress)
-							}
-						}
-					}
-				}
+				stacks = v.GuestStackInfo
 			case types.ArrayOfGuestNicInfo:
-				for _, n := range v.GuestNicInfo {
-					if n.IpConfig != nil {
-						for _, addr := range n.IpConfig.IpAddress {
-							ip := net.ParseIP(addr.IpAddress)
-							var mask net.IPMask
-							if ip.To4() != nil {
-								mask = net.CIDRMask(int(addr.PrefixLength), 32)
-							} else {
-								mask = net.CIDRMask(int(addr.PrefixLength), 128)
-							}
-							if ip.Mask(mask).Equal(v4gw.Mask(mask)) || ip.Mask(mask).Equal(v6gw.Mask(mask)) {
-								return true
-							}
-						}
-					}
-				}
+				nics = v.GuestNicInfo
 			}
 		}
 
-		return false
+		sel := selectGuestIPs(nics, stacks, ignored)
+		log.Printf("[DEBUG] Eligible guest IP addresses for %q: %v (default: %q, routeable: %t)", vm.InventoryPath, sel.addresses, sel.defaultAddress, sel.routable)
+		if routable {
+			return sel.routable
+		}
+		return sel.defaultAddress != ""
 	})
 
 	if err != nil {
 		// Provide a friendly error message if we timed out waiting for a routeable IP.
 		if ctx.Err() == context.DeadlineExceeded {
-			return errors.New("timeout waiting for a routeable interface")
+			if routable {
+				return errors.New("timeout waiting for a routeable interface")
+			}
+			return errors.New("timeout waiting for an available IP address")
 		}
 		return err
 	}
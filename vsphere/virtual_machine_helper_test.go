@@ -0,0 +1,194 @@
+package vsphere
+
+import (
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// testGuestNic returns a GuestNicInfo with the supplied addresses, in
+// address/prefix format.
+func testGuestNic(addrs ...string) types.GuestNicInfo {
+	nic := types.GuestNicInfo{
+		IpConfig: &types.NetIpConfigInfo{},
+	}
+	for _, a := range addrs {
+		ip, n, err := net.ParseCIDR(a)
+		if err != nil {
+			panic(err)
+		}
+		prefix, _ := n.Mask.Size()
+		nic.IpConfig.IpAddress = append(nic.IpConfig.IpAddress, types.NetIpConfigInfoIpAddress{
+			IpAddress:    ip.String(),
+			PrefixLength: int32(prefix),
+		})
+	}
+	return nic
+}
+
+// testGuestStack returns a GuestStackInfo with a default route for each
+// gateway supplied, in network/gateway/device format.
+func testGuestStack(routes ...[3]string) types.GuestStackInfo {
+	stack := types.GuestStackInfo{
+		IpRouteConfig: &types.NetIpRouteConfigInfo{},
+	}
+	for _, r := range routes {
+		stack.IpRouteConfig.IpRoute = append(stack.IpRouteConfig.IpRoute, types.NetIpRouteConfigInfoIpRoute{
+			Network:      r[0],
+			PrefixLength: 0,
+			Gateway: types.NetIpRouteConfigInfoGateway{
+				IpAddress: r[1],
+				Device:    r[2],
+			},
+		})
+	}
+	return stack
+}
+
+type testSelectGuestIPs struct {
+	Name string
+
+	nics     []types.GuestNicInfo
+	stacks   []types.GuestStackInfo
+	ignored  []interface{}
+	expected guestIPSelection
+}
+
+func (tc *testSelectGuestIPs) Test(t *testing.T) {
+	ignored, err := parseGuestIPNetworks(tc.ignored)
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	actual := selectGuestIPs(tc.nics, tc.stacks, ignored)
+	if !reflect.DeepEqual(tc.expected, actual) {
+		t.Fatalf("expected %#v, got %#v", tc.expected, actual)
+	}
+}
+
+func TestSelectGuestIPs(t *testing.T) {
+	cases := []testSelectGuestIPs{
+		{
+			Name:     "no addresses",
+			expected: guestIPSelection{},
+		},
+		{
+			Name: "routeable IPv4",
+			nics: []types.GuestNicInfo{
+				testGuestNic("10.0.0.10/24"),
+			},
+			stacks: []types.GuestStackInfo{
+				testGuestStack([3]string{"0.0.0.0", "10.0.0.1", "0"}),
+			},
+			expected: guestIPSelection{
+				addresses:      []string{"10.0.0.10"},
+				defaultAddress: "10.0.0.10",
+				routable:       true,
+			},
+		},
+		{
+			Name: "APIPA and link-local IPv6 skipped",
+			nics: []types.GuestNicInfo{
+				testGuestNic("169.254.10.20/16", "fe80::250:56ff:fe8e:1/64"),
+			},
+			expected: guestIPSelection{},
+		},
+		{
+			Name: "docker bridge not routeable",
+			nics: []types.GuestNicInfo{
+				testGuestNic("172.17.0.1/16"),
+				testGuestNic("10.0.0.10/24"),
+			},
+			stacks: []types.GuestStackInfo{
+				testGuestStack([3]string{"0.0.0.0", "10.0.0.1", "1"}),
+			},
+			expected: guestIPSelection{
+				addresses:      []string{"172.17.0.1", "10.0.0.10"},
+				defaultAddress: "10.0.0.10",
+				routable:       true,
+			},
+		},
+		{
+			Name: "ignored network",
+			nics: []types.GuestNicInfo{
+				testGuestNic("172.17.0.1/16"),
+				testGuestNic("10.0.0.10/24"),
+			},
+			ignored: []interface{}{"172.16.0.0/12"},
+			expected: guestIPSelection{
+				addresses:      []string{"10.0.0.10"},
+				defaultAddress: "10.0.0.10",
+			},
+		},
+		{
+			Name: "IPv6 with link-local gateway",
+			nics: []types.GuestNicInfo{
+				testGuestNic("192.168.0.10/24"),
+				testGuestNic("2001:db8::10/64", "fe80::10/64"),
+			},
+			stacks: []types.GuestStackInfo{
+				testGuestStack([3]string{"::", "fe80::1", "1"}),
+			},
+			expected: guestIPSelection{
+				addresses:      []string{"192.168.0.10", "2001:db8::10"},
+				defaultAddress: "2001:db8::10",
+				routable:       true,
+			},
+		},
+		{
+			Name: "IPv4 preferred over IPv6",
+			nics: []types.GuestNicInfo{
+				testGuestNic("2001:db8::10/64", "10.0.0.10/24"),
+			},
+			stacks: []types.GuestStackInfo{
+				testGuestStack(
+					[3]string{"::", "2001:db8::1", "0"},
+					[3]string{"0.0.0.0", "10.0.0.1", "0"},
+				),
+			},
+			expected: guestIPSelection{
+				addresses:      []string{"10.0.0.10", "2001:db8::10"},
+				defaultAddress: "10.0.0.10",
+				routable:       true,
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, tc.Test)
+	}
+}
+
+func TestParseVirtualMachineHardwareVersion(t *testing.T) {
+	cases := []struct {
+		Name     string
+		in       string
+		expected int
+		err      bool
+	}{
+		{Name: "two digit version", in: "vmx-13", expected: 13},
+		{Name: "zero-padded version", in: "vmx-07", expected: 7},
+		{Name: "missing prefix", in: "13", err: true},
+		{Name: "garbage", in: "vmx-foo", err: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			actual, err := parseVirtualMachineHardwareVersion(tc.in)
+			if tc.err {
+				if err == nil {
+					t.Fatalf("expected error, got %d", actual)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("bad: %s", err)
+			}
+			if tc.expected != actual {
+				t.Fatalf("expected %d, got %d", tc.expected, actual)
+			}
+			if s := virtualMachineHardwareVersionString(actual); s != tc.in {
+				t.Fatalf("expected %q when formatting %d, got %q", tc.in, actual, s)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// virtualMachineSnapshotTreeFromID locates a snapshot in the snapshot tree of
+// a virtual machine by its managed object ID. The tree node for the snapshot
+// is returned, along with the managed object ID of its parent, which is empty
+// for root snapshots. A nil tree is returned if the snapshot does not exist.
+func virtualMachineSnapshotTreeFromID(vm *object.VirtualMachine, id string) (*types.VirtualMachineSnapshotTree, string, error) {
+	props, err := virtualMachineProperties(vm)
+	if err != nil {
+		return nil, "", fmt.Errorf("error fetching virtual machine properties: %s", err)
+	}
+	if props.Snapshot == nil {
+		return nil, "", nil
+	}
+	tree, parent := findVirtualMachineSnapshotTree(props.Snapshot.RootSnapshotList, id, "")
+	return tree, parent, nil
+}
+
+// findVirtualMachineSnapshotTree searches a list of snapshot trees for the
+// snapshot with the supplied ID, returning it and the ID of its parent.
+func findVirtualMachineSnapshotTree(trees []types.VirtualMachineSnapshotTree, id, parent string) (*types.VirtualMachineSnapshotTree, string) {
+	for i := range trees {
+		if trees[i].Snapshot.Value == id {
+			return &trees[i], parent
+		}
+		if tree, p := findVirtualMachineSnapshotTree(trees[i].ChildSnapshotList, id, trees[i].Snapshot.Value); tree != nil {
+			return tree, p
+		}
+	}
+	return nil, ""
+}
+
+// virtualMachineSnapshotReference returns a managed object reference for a
+// snapshot ID.
+func virtualMachineSnapshotReference(id string) types.ManagedObjectReference {
+	return types.ManagedObjectReference{
+		Type:  "VirtualMachineSnapshot",
+		Value: id,
+	}
+}
+
+// renameVirtualMachineSnapshot changes the name and description of a
+// snapshot.
+func renameVirtualMachineSnapshot(client *govmomi.Client, id, name, description string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	req := types.RenameSnapshot{
+		This:        virtualMachineSnapshotReference(id),
+		Name:        name,
+		Description: description,
+	}
+	_, err := methods.RenameSnapshot(ctx, client.Client, &req)
+	return err
+}
+
+// revertToVirtualMachineSnapshot reverts a virtual machine to the snapshot
+// with the supplied ID.
+func revertToVirtualMachineSnapshot(client *govmomi.Client, id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	req := types.RevertToSnapshot_Task{
+		This: virtualMachineSnapshotReference(id),
+	}
+	res, err := methods.RevertToSnapshot_Task(ctx, client.Client, &req)
+	if err != nil {
+		return err
+	}
+	task := object.NewTask(client.Client, res.Returnval)
+	tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer tcancel()
+	return task.Wait(tctx)
+}
+
+// createVirtualMachineSnapshot takes a new snapshot of a virtual machine and
+// returns its managed object ID.
+func createVirtualMachineSnapshot(vm *object.VirtualMachine, name, description string, memory, quiesce bool) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	task, err := vm.CreateSnapshot(ctx, name, description, memory, quiesce)
+	if err != nil {
+		return "", err
+	}
+	tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer tcancel()
+	info, err := task.WaitForResult(tctx, nil)
+	if err != nil {
+		return "", err
+	}
+	return info.Result.(types.ManagedObjectReference).Value, nil
+}
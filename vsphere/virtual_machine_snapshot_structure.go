@@ -0,0 +1,145 @@
+package vsphere
+
+import (
+	"sort"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// schemaVirtualMachineSnapshotTree returns the schema for a flattened
+// snapshot tree, as used by data sources that list the snapshots of a virtual
+// machine.
+func schemaVirtualMachineSnapshotTree() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Computed:    true,
+		Description: "The snapshots of the virtual machine, parents before their children.",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"id": {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "The managed object ID of the snapshot.",
+				},
+				"name": {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "The name of the snapshot.",
+				},
+				"description": {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "The description of the snapshot.",
+				},
+				"create_time": {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "The time the snapshot was created, in RFC3339 format.",
+				},
+				"parent_id": {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "The managed object ID of the parent snapshot. Empty for root snapshots.",
+				},
+				"power_state": {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "The power state of the virtual machine when the snapshot was taken.",
+				},
+				"quiesced": {
+					Type:        schema.TypeBool,
+					Computed:    true,
+					Description: "True if the guest file system was quiesced when the snapshot was taken.",
+				},
+				"memory": {
+					Type:        schema.TypeBool,
+					Computed:    true,
+					Description: "True if the snapshot includes the memory of the virtual machine.",
+				},
+			},
+		},
+	}
+}
+
+// flattenVirtualMachineSnapshotTrees walks a snapshot tree depth first,
+// returning each snapshot after its parent.
+func flattenVirtualMachineSnapshotTrees(trees []types.VirtualMachineSnapshotTree, parent string) []map[string]interface{} {
+	var snapshots []map[string]interface{}
+	for _, tree := range trees {
+		snapshots = append(snapshots, map[string]interface{}{
+			"id":          tree.Snapshot.Value,
+			"name":        tree.Name,
+			"description": tree.Description,
+			"create_time": tree.CreateTime.Format(time.RFC3339),
+			"parent_id":   parent,
+			"power_state": string(tree.State),
+			"quiesced":    tree.Quiesced,
+			// Only snapshots that include memory retain the powered on state.
+			"memory": tree.State == types.VirtualMachinePowerStatePoweredOn,
+		})
+		snapshots = append(snapshots, flattenVirtualMachineSnapshotTrees(tree.ChildSnapshotList, tree.Snapshot.Value)...)
+	}
+	return snapshots
+}
+
+// virtualMachineSnapshotRetention describes how many of the snapshots taken by
+// a snapshot resource are kept, and for how long.
+type virtualMachineSnapshotRetention struct {
+	count  int
+	maxAge time.Duration
+}
+
+// expandVirtualMachineSnapshotRetention reads the retention block from
+// ResourceData. nil is returned if retention is not set.
+func expandVirtualMachineSnapshotRetention(d *schema.ResourceData) *virtualMachineSnapshotRetention {
+	l := d.Get("retention").([]interface{})
+	if len(l) < 1 || l[0] == nil {
+		return nil
+	}
+	m := l[0].(map[string]interface{})
+	r := &virtualMachineSnapshotRetention{
+		count: m["count"].(int),
+	}
+	// max_age is validated in the schema.
+	r.maxAge, _ = time.ParseDuration(m["max_age"].(string))
+	return r
+}
+
+// snapshotsToPrune returns the IDs of the retained snapshots in the supplied
+// trees that fall outside of the retention policy, oldest first. Only the
+// snapshots in retained are considered. The current snapshot is never pruned,
+// and counts towards the retained snapshots.
+func (r *virtualMachineSnapshotRetention) snapshotsToPrune(trees []types.VirtualMachineSnapshotTree, retained []string, now time.Time) []string {
+	candidates := make(map[string]bool)
+	for _, id := range retained {
+		candidates[id] = true
+	}
+	var series []types.VirtualMachineSnapshotTree
+	var walk func([]types.VirtualMachineSnapshotTree)
+	walk = func(trees []types.VirtualMachineSnapshotTree) {
+		for _, tree := range trees {
+			if candidates[tree.Snapshot.Value] {
+				series = append(series, tree)
+			}
+			walk(tree.ChildSnapshotList)
+		}
+	}
+	walk(trees)
+
+	// Newest first, so that the index is the number of newer snapshots in the
+	// series, not including the current one.
+	sort.SliceStable(series, func(i, j int) bool {
+		return series[i].CreateTime.After(series[j].CreateTime)
+	})
+	var ids []string
+	for i := len(series) - 1; i >= 0; i-- {
+		expired := r.maxAge > 0 && now.Sub(series[i].CreateTime) > r.maxAge
+		excess := r.count > 0 && i+1 >= r.count
+		if expired || excess {
+			ids = append(ids, series[i].Snapshot.Value)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,76 @@
+package vsphere
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func testSnapshotTree(id, name string, created time.Time, children ...types.VirtualMachineSnapshotTree) types.VirtualMachineSnapshotTree {
+	return types.VirtualMachineSnapshotTree{
+		Snapshot:          virtualMachineSnapshotReference(id),
+		Name:              name,
+		CreateTime:        created,
+		ChildSnapshotList: children,
+	}
+}
+
+func TestVirtualMachineSnapshotRetentionSnapshotsToPrune(t *testing.T) {
+	now := time.Date(2017, 10, 1, 12, 0, 0, 0, time.UTC)
+	trees := []types.VirtualMachineSnapshotTree{
+		testSnapshotTree("snapshot-1", "nightly", now.Add(-72*time.Hour),
+			testSnapshotTree("snapshot-2", "manual", now.Add(-60*time.Hour),
+				testSnapshotTree("snapshot-3", "nightly", now.Add(-48*time.Hour),
+					testSnapshotTree("snapshot-4", "nightly", now.Add(-24*time.Hour),
+						testSnapshotTree("snapshot-5", "nightly", now),
+					),
+				),
+			),
+		),
+		// Not taken by the resource, so never pruned, even though it has the same
+		// name and is the oldest.
+		testSnapshotTree("snapshot-6", "nightly", now.Add(-96*time.Hour)),
+	}
+	cases := []struct {
+		name      string
+		retention virtualMachineSnapshotRetention
+		expected  []string
+	}{
+		{
+			name:      "keep by count",
+			retention: virtualMachineSnapshotRetention{count: 2},
+			expected:  []string{"snapshot-1", "snapshot-3"},
+		},
+		{
+			name:      "keep only current",
+			retention: virtualMachineSnapshotRetention{count: 1},
+			expected:  []string{"snapshot-1", "snapshot-3", "snapshot-4"},
+		},
+		{
+			name:      "keep by age",
+			retention: virtualMachineSnapshotRetention{maxAge: 36 * time.Hour},
+			expected:  []string{"snapshot-1", "snapshot-3"},
+		},
+		{
+			name:      "count and age",
+			retention: virtualMachineSnapshotRetention{count: 4, maxAge: 60 * time.Hour},
+			expected:  []string{"snapshot-1"},
+		},
+		{
+			name:      "nothing to prune",
+			retention: virtualMachineSnapshotRetention{count: 10},
+			expected:  nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := tc.retention.snapshotsToPrune(trees, []string{"snapshot-1", "snapshot-3", "snapshot-4"}, now)
+			if !reflect.DeepEqual(tc.expected, actual) {
+				t.Fatalf("expected %#v, got %#v", tc.expected, actual)
+			}
+		})
+	}
+}